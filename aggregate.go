@@ -0,0 +1,132 @@
+package semanticrouter
+
+import "sort"
+
+// entryScore is the score of a query against an index entry.
+type entryScore struct {
+	entry indexEntry
+	score float64
+}
+
+// AggregationMode is how the scores of the utterances of a route are
+// aggregated into the score of the route, see WithAggregation.
+type AggregationMode int
+
+const (
+	// AggregationMax takes the best score among the utterances.
+	AggregationMax AggregationMode = iota
+	// AggregationMean takes the mean score of the utterances.
+	AggregationMean
+	// AggregationTrimmedMean takes the mean score of the utterances once
+	// the best and worst scores are dropped, see WithTrimmedMeanAggregation.
+	AggregationTrimmedMean
+)
+
+// aggregate aggregates the scores of the index entries into the score of
+// each route.
+//
+// By default, the score of a route is the best score among its utterances,
+// see WithAggregation. With KNN voting, only the k best scoring utterances
+// across all routes are kept, and the score of a route is the sum of the
+// scores of its utterances among them.
+func (r *Router) aggregate(scored []entryScore) (scores []routeScore) {
+	if r.knn > 0 {
+		return aggregateKNN(scored, r.knn)
+	}
+	switch r.aggregation {
+	case AggregationMean:
+		return aggregateMean(scored, 0)
+	case AggregationTrimmedMean:
+		return aggregateMean(scored, r.trimFraction)
+	}
+	return aggregateMax(scored, r.better)
+}
+
+// aggregateMax aggregates the scores of each route by taking the best score
+// among its utterances, according to the given comparison.
+func aggregateMax(
+	scored []entryScore,
+	better func(a, b float64) bool,
+) (scores []routeScore) {
+	positions := make(map[string]int)
+	for _, es := range scored {
+		pos, ok := positions[es.entry.route]
+		if !ok {
+			positions[es.entry.route] = len(scores)
+			scores = append(scores, routeScore{
+				route: es.entry.route,
+				score: es.score,
+			})
+			continue
+		}
+		if better(es.score, scores[pos].score) {
+			scores[pos].score = es.score
+		}
+	}
+	return scores
+}
+
+// aggregateKNN aggregates the scores of each route by summing the scores of
+// its utterances among the k best scoring utterances.
+func aggregateKNN(scored []entryScore, k int) (scores []routeScore) {
+	order := make([]int, len(scored))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return scored[order[i]].score > scored[order[j]].score
+	})
+	if len(order) > k {
+		order = order[:k]
+	}
+	nearest := make([]bool, len(scored))
+	for _, i := range order {
+		nearest[i] = true
+	}
+	positions := make(map[string]int)
+	for i, es := range scored {
+		if !nearest[i] {
+			continue
+		}
+		pos, ok := positions[es.entry.route]
+		if !ok {
+			positions[es.entry.route] = len(scores)
+			scores = append(scores, routeScore{route: es.entry.route})
+			pos = len(scores) - 1
+		}
+		scores[pos].score += es.score
+	}
+	return scores
+}
+
+// aggregateMean aggregates the scores of each route by averaging the scores
+// of its utterances, once the given fraction of its best and of its worst
+// scores are dropped.
+//
+// At least one score is always kept: when fewer are left, the middle scores
+// are averaged.
+func aggregateMean(scored []entryScore, trim float64) (scores []routeScore) {
+	positions := make(map[string]int)
+	var routeScores [][]float64
+	for _, es := range scored {
+		pos, ok := positions[es.entry.route]
+		if !ok {
+			pos = len(scores)
+			positions[es.entry.route] = pos
+			scores = append(scores, routeScore{route: es.entry.route})
+			routeScores = append(routeScores, nil)
+		}
+		routeScores[pos] = append(routeScores[pos], es.score)
+	}
+	for i, values := range routeScores {
+		sort.Float64s(values)
+		k := min(int(trim*float64(len(values))), (len(values)-1)/2)
+		values = values[k : len(values)-k]
+		var sum float64
+		for _, v := range values {
+			sum += v
+		}
+		scores[i].score = sum / float64(len(values))
+	}
+	return scores
+}
@@ -0,0 +1,144 @@
+package semanticrouter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/conneroisu/go-semantic-router/domain"
+	"github.com/conneroisu/go-semantic-router/stores/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestWithKNNVoting tests that KNN voting lets a route with several close
+// utterances beat a route with a single closest outlier.
+func TestWithKNNVoting(t *testing.T) {
+	ctx := context.Background()
+	encoder := &mockEncoder{embeddings: map[string][]float64{
+		"query":   {1.0, 0.0},
+		"outlier": {1.0, 0.05},
+		"far":     {0.0, 1.0},
+		"close a": {1.0, 0.2},
+		"close b": {1.0, 0.25},
+		"close c": {1.0, 0.3},
+	}}
+	routes := []Route{
+		{
+			Name: "outlier",
+			Utterances: []domain.Utterance{
+				{Utterance: "outlier"},
+				{Utterance: "far"},
+			},
+		},
+		{
+			Name: "cluster",
+			Utterances: []domain.Utterance{
+				{Utterance: "close a"},
+				{Utterance: "close b"},
+				{Utterance: "close c"},
+			},
+		},
+	}
+
+	router, err := NewRouter(routes, encoder, memory.NewStore())
+	require.NoError(t, err)
+	route, _, err := router.Match(ctx, "query")
+	require.NoError(t, err)
+	assert.Equal(t, "outlier", route)
+
+	router, err = NewRouter(routes, encoder, memory.NewStore(), WithKNNVoting(3))
+	require.NoError(t, err)
+	route, score, err := router.Match(ctx, "query")
+	require.NoError(t, err)
+	assert.Equal(t, "cluster", route)
+	assert.Greater(t, score, 1.0)
+
+	scores, err := router.ScoreAll(ctx, "query")
+	require.NoError(t, err)
+	assert.Len(t, scores, 2)
+	assert.Greater(t, scores["cluster"], scores["outlier"])
+
+	// Routes without any of the k nearest utterances get no vote.
+	router, err = NewRouter(routes, encoder, memory.NewStore(), WithKNNVoting(1))
+	require.NoError(t, err)
+	scores, err = router.ScoreAll(ctx, "query")
+	require.NoError(t, err)
+	assert.Len(t, scores, 1)
+	assert.Contains(t, scores, "outlier")
+}
+
+// TestWithTrimmedMeanAggregation tests that a trimmed mean ignores an
+// outlier utterance which drags down the plain mean of its route.
+func TestWithTrimmedMeanAggregation(t *testing.T) {
+	ctx := context.Background()
+	encoder := &mockEncoder{embeddings: map[string][]float64{
+		"query":    {1.0, 0.0},
+		"steady a": {0.9, 0.43589},
+		"steady b": {0.9, 0.43589},
+		"steady c": {0.9, 0.43589},
+		"steady d": {0.9, 0.43589},
+		"opposite": {-1.0, 0.0},
+		"other a":  {0.7, 0.71414},
+		"other b":  {0.7, 0.71414},
+		"other c":  {0.7, 0.71414},
+		"other d":  {0.7, 0.71414},
+		"other e":  {0.7, 0.71414},
+	}}
+	routes := []Route{
+		{
+			Name: "steady",
+			Utterances: []domain.Utterance{
+				{Utterance: "steady a"},
+				{Utterance: "steady b"},
+				{Utterance: "opposite"},
+				{Utterance: "steady c"},
+				{Utterance: "steady d"},
+			},
+		},
+		{
+			Name: "other",
+			Utterances: []domain.Utterance{
+				{Utterance: "other a"},
+				{Utterance: "other b"},
+				{Utterance: "other c"},
+				{Utterance: "other d"},
+				{Utterance: "other e"},
+			},
+		},
+	}
+
+	mean, err := NewRouter(routes, encoder, memory.NewStore(), WithAggregation(AggregationMean))
+	require.NoError(t, err)
+	scores, err := mean.ScoreAll(ctx, "query")
+	require.NoError(t, err)
+	assert.InDelta(t, 0.52, scores["steady"], 1e-3)
+	assert.InDelta(t, 0.7, scores["other"], 1e-3)
+	route, _, err := mean.Match(ctx, "query")
+	require.NoError(t, err)
+	assert.Equal(t, "other", route)
+
+	trimmed, err := NewRouter(routes, encoder, memory.NewStore(), WithTrimmedMeanAggregation(0.2))
+	require.NoError(t, err)
+	scores, err = trimmed.ScoreAll(ctx, "query")
+	require.NoError(t, err)
+	assert.InDelta(t, 0.9, scores["steady"], 1e-3)
+	assert.InDelta(t, 0.7, scores["other"], 1e-3)
+	route, score, err := trimmed.Match(ctx, "query")
+	require.NoError(t, err)
+	assert.Equal(t, "steady", route)
+	assert.InDelta(t, 0.9, score, 1e-3)
+
+	// A fraction of zero is the plain mean.
+	untrimmed, err := NewRouter(routes, encoder, memory.NewStore(), WithTrimmedMeanAggregation(0))
+	require.NoError(t, err)
+	scores, err = untrimmed.ScoreAll(ctx, "query")
+	require.NoError(t, err)
+	assert.InDelta(t, 0.52, scores["steady"], 1e-3)
+
+	_, err = NewRouter(routes, encoder, memory.NewStore(), WithTrimmedMeanAggregation(0.5))
+	assert.Error(t, err)
+	_, err = NewRouter(routes, encoder, memory.NewStore(), WithAggregation(AggregationTrimmedMean+1))
+	assert.ErrorContains(t, err, "aggregation mode 3 is not a valid AggregationMode")
+	_, err = NewRouter(routes, encoder, memory.NewStore(), WithAggregation(-1))
+	assert.Error(t, err)
+}
@@ -0,0 +1,126 @@
+package semanticrouter
+
+import (
+	"context"
+	"fmt"
+)
+
+// BlendedRouter is a router blending the route scores of two routers, each
+// typically built with its own encoder.
+//
+// Blending happens at the score level rather than the embedding level, so
+// the routers' encoders may produce embeddings of different dimensions.
+type BlendedRouter struct {
+	A      *Router // A is the first router.
+	B      *Router // B is the second router.
+	Weight float64 // Weight is the weight of the scores of A, in [0, 1].
+}
+
+// NewBlendedRouter creates a new BlendedRouter scoring each route as
+// weight*scoreA + (1-weight)*scoreB, where scoreA and scoreB are the scores
+// of the route by a and b.
+//
+// The routers must use the same score direction, see WithScoreDirection.
+func NewBlendedRouter(a, b *Router, weight float64) (*BlendedRouter, error) {
+	if a == nil || b == nil {
+		return nil, fmt.Errorf("blended routers must not be nil")
+	}
+	if !(weight >= 0 && weight <= 1) {
+		return nil, fmt.Errorf("blend weight %v is not in [0, 1]", weight)
+	}
+	if a.scoreDirection != b.scoreDirection {
+		return nil, fmt.Errorf("blended routers must use the same score direction")
+	}
+	return &BlendedRouter{A: a, B: b, Weight: weight}, nil
+}
+
+// ScoreAll returns the blended score of every route for the given utterance.
+//
+// The scores of each router are the ones returned by its ScoreAll. A route
+// scored by only one of the routers gets a zero score from the other.
+func (b *BlendedRouter) ScoreAll(
+	ctx context.Context,
+	utterance string,
+) (map[string]float64, error) {
+	scoresA, scoresB, err := b.scoreRouters(ctx, utterance)
+	if err != nil {
+		return nil, err
+	}
+	return b.blend(scoresA, scoresB), nil
+}
+
+// Match returns the route with the best blended score for the given
+// utterance, along with that score.
+//
+// Only routes accepted by each router with a non-zero weight are returned:
+// routes that router scores, see ScoreAll, and whose own score passes its
+// threshold as in Match. So if neither router matches the utterance, neither
+// does the blended router. Routes with the same score are broken by name.
+func (b *BlendedRouter) Match(
+	ctx context.Context,
+	utterance string,
+) (bestRoute string, bestScore float64, err error) {
+	scoresA, scoresB, err := b.scoreRouters(ctx, utterance)
+	if err != nil {
+		return "", 0.0, err
+	}
+	for route, score := range b.blend(scoresA, scoresB) {
+		if !blendAccepts(b.A, b.Weight, scoresA, route) ||
+			!blendAccepts(b.B, 1-b.Weight, scoresB, route) {
+			continue
+		}
+		if bestRoute == "" ||
+			b.A.better(score, bestScore) ||
+			score == bestScore && route < bestRoute {
+			bestRoute, bestScore = route, score
+		}
+	}
+	if bestRoute == "" {
+		return "", 0.0, ErrNoRouteFound
+	}
+	return bestRoute, bestScore, nil
+}
+
+// scoreRouters returns the scores of every route by each router.
+func (b *BlendedRouter) scoreRouters(
+	ctx context.Context,
+	utterance string,
+) (scoresA, scoresB map[string]float64, err error) {
+	scoresA, err = b.A.ScoreAll(ctx, utterance)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error scoring routes of router A: %w", err)
+	}
+	scoresB, err = b.B.ScoreAll(ctx, utterance)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error scoring routes of router B: %w", err)
+	}
+	return scoresA, scoresB, nil
+}
+
+// blend returns the blended score of every route scored by either router.
+func (b *BlendedRouter) blend(scoresA, scoresB map[string]float64) map[string]float64 {
+	scores := make(map[string]float64, len(scoresA))
+	for route, score := range scoresA {
+		scores[route] = b.Weight * score
+	}
+	for route, score := range scoresB {
+		scores[route] += (1 - b.Weight) * score
+	}
+	return scores
+}
+
+// blendAccepts reports whether the given router, blended with the given
+// weight, accepts the given route: routers without weight accept every
+// route, others only the routes they score with an accepted score.
+func blendAccepts(
+	r *Router,
+	weight float64,
+	scores map[string]float64,
+	route string,
+) bool {
+	if weight == 0 {
+		return true
+	}
+	score, ok := scores[route]
+	return ok && r.accepts(score)
+}
@@ -0,0 +1,89 @@
+package semanticrouter
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/conneroisu/go-semantic-router/stores/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestBlendedRouter tests that the scores of two routers with encoders of
+// different dimensions are blended per route.
+func TestBlendedRouter(t *testing.T) {
+	ctx := context.Background()
+	a, err := NewRouter(newTestRoutes(), newTestEncoder(), memory.NewStore())
+	require.NoError(t, err)
+	b, err := NewRouter(newTestRoutes(), &mockEncoder{embeddings: map[string][]float64{
+		"how's the weather today?": {0.0, 1.0},
+		"lovely weather today":     {0.1, 1.0},
+		"who will win the vote?":   {1.0, 0.0},
+		"i love the president":     {1.0, 0.1},
+		"is it raining outside?":   {1.0, 0.0},
+	}}, memory.NewStore())
+	require.NoError(t, err)
+
+	const utterance = "is it raining outside?"
+	scoresA, err := a.ScoreAll(ctx, utterance)
+	require.NoError(t, err)
+	scoresB, err := b.ScoreAll(ctx, utterance)
+	require.NoError(t, err)
+
+	blended, err := NewBlendedRouter(a, b, 0.25)
+	require.NoError(t, err)
+	scores, err := blended.ScoreAll(ctx, utterance)
+	require.NoError(t, err)
+	require.Len(t, scores, 2)
+	for route, score := range scores {
+		assert.InDelta(t, 0.25*scoresA[route]+0.75*scoresB[route], score, 1e-12)
+	}
+
+	route, score, err := blended.Match(ctx, utterance)
+	require.NoError(t, err)
+	assert.Equal(t, "politics", route)
+	assert.Equal(t, scores["politics"], score)
+
+	blended.Weight = 1
+	route, _, err = blended.Match(ctx, utterance)
+	require.NoError(t, err)
+	assert.Equal(t, "chitchat", route)
+
+	_, err = NewBlendedRouter(a, b, 1.5)
+	assert.Error(t, err)
+	_, err = NewBlendedRouter(a, b, math.NaN())
+	assert.Error(t, err)
+}
+
+// TestBlendedRouterAccepts tests that the blended router only returns
+// routes the blended routers accept, in their score direction.
+func TestBlendedRouterAccepts(t *testing.T) {
+	ctx := context.Background()
+	const utterance = "is it raining outside?"
+	strict, err := NewRouter(newTestRoutes(), newTestEncoder(), memory.NewStore(), WithAdaptiveThreshold(0.9999))
+	require.NoError(t, err)
+	blended, err := NewBlendedRouter(strict, strict, 0.5)
+	require.NoError(t, err)
+	_, _, err = blended.Match(ctx, utterance)
+	assert.ErrorIs(t, err, ErrNoRouteFound)
+
+	distance, err := NewRouter(
+		newTestRoutes(),
+		newTestEncoder(),
+		memory.NewStore(),
+		WithCustomSimilarity("euclidean_distance", EuclideanDistance, 1.0),
+		WithScoreDirection(LowerIsBetter),
+	)
+	require.NoError(t, err)
+	expected, _, err := distance.Match(ctx, utterance)
+	require.NoError(t, err)
+	blended, err = NewBlendedRouter(distance, distance, 0.5)
+	require.NoError(t, err)
+	route, _, err := blended.Match(ctx, utterance)
+	require.NoError(t, err)
+	assert.Equal(t, expected, route)
+
+	_, err = NewBlendedRouter(strict, distance, 0.5)
+	assert.Error(t, err)
+}
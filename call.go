@@ -0,0 +1,200 @@
+package semanticrouter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/conneroisu/go-semantic-router/domain"
+)
+
+// callContext derives the context of a single encoder or store call from the
+// given context, applying the configured call timeout.
+func (r *Router) callContext(
+	ctx context.Context,
+) (context.Context, context.CancelFunc) {
+	if r.callTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, r.callTimeout)
+}
+
+// callError wraps the given error with the error of the call context, if any,
+// so that timeouts can be detected with errors.Is.
+func callError(ctx context.Context, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil && !errors.Is(err, ctxErr) {
+		return fmt.Errorf("%w: %w", ctxErr, err)
+	}
+	return err
+}
+
+// encode encodes the given utterance with the router's encoder, truncating
+// the embedding to the output dimension, if set.
+func (r *Router) encode(
+	ctx context.Context,
+	utterance string,
+) ([]float64, error) {
+	callCtx, cancel := r.callContext(ctx)
+	defer cancel()
+	em, err := r.Encoder.Encode(callCtx, utterance)
+	if err != nil {
+		return nil, callError(callCtx, err)
+	}
+	if r.outputDimension > 0 && len(em) > r.outputDimension {
+		em = em[:r.outputDimension:r.outputDimension]
+	}
+	return em, nil
+}
+
+// get gets the embedding of the given utterance from the router's store.
+func (r *Router) get(
+	ctx context.Context,
+	utterance string,
+) ([]float64, error) {
+	callCtx, cancel := r.callContext(ctx)
+	defer cancel()
+	em, err := r.Storage.Get(callCtx, r.key(utterance))
+	if err != nil {
+		return nil, callError(callCtx, err)
+	}
+	return em, nil
+}
+
+// store stores the given utterance in the router's store.
+func (r *Router) store(
+	ctx context.Context,
+	utterance domain.Utterance,
+) error {
+	callCtx, cancel := r.callContext(ctx)
+	defer cancel()
+	r.invalidateEntry(utterance.Utterance)
+	utterance.Utterance = r.key(utterance.Utterance)
+	err := r.Storage.Store(callCtx, utterance)
+	if err != nil {
+		return callError(callCtx, err)
+	}
+	return nil
+}
+
+// deleteUtterance deletes the embeddings of the given utterance from the
+// router's store, which must implement Deleter.
+func (r *Router) deleteUtterance(
+	ctx context.Context,
+	utterance string,
+) error {
+	deleter, ok := r.Storage.(Deleter)
+	if !ok {
+		return fmt.Errorf("store %T does not implement Deleter: %w", r.Storage, ErrNotSupported)
+	}
+	callCtx, cancel := r.callContext(ctx)
+	defer cancel()
+	r.invalidateEntry(utterance)
+	err := deleter.Delete(callCtx, r.key(utterance))
+	if err != nil {
+		return callError(callCtx, err)
+	}
+	return nil
+}
+
+// encodeSparse encodes the given utterance into a sparse embedding with the
+// router's encoder.
+func (r *Router) encodeSparse(
+	ctx context.Context,
+	utterance string,
+) (domain.SparseEmbedding, error) {
+	callCtx, cancel := r.callContext(ctx)
+	defer cancel()
+	em, err := r.Encoder.(SparseEncoder).EncodeSparse(callCtx, utterance)
+	if err != nil {
+		return domain.SparseEmbedding{}, callError(callCtx, err)
+	}
+	return em.Sorted(), nil
+}
+
+// getSparse gets the sparse embedding of the given utterance from the
+// router's store.
+func (r *Router) getSparse(
+	ctx context.Context,
+	utterance string,
+) (domain.SparseEmbedding, error) {
+	callCtx, cancel := r.callContext(ctx)
+	defer cancel()
+	em, err := r.Storage.(SparseStore).GetSparse(callCtx, r.key(utterance))
+	if err != nil {
+		return domain.SparseEmbedding{}, callError(callCtx, err)
+	}
+	return em, nil
+}
+
+// storeSparse stores the given sparse utterance in the router's store.
+func (r *Router) storeSparse(
+	ctx context.Context,
+	utterance domain.SparseUtterance,
+) error {
+	callCtx, cancel := r.callContext(ctx)
+	defer cancel()
+	r.invalidateEntry(utterance.Utterance)
+	utterance.Utterance = r.key(utterance.Utterance)
+	err := r.Storage.(SparseStore).StoreSparse(callCtx, utterance)
+	if err != nil {
+		return callError(callCtx, err)
+	}
+	return nil
+}
+
+// encodeMultiVector encodes the given utterance into a multi-vector
+// embedding with the router's encoder, rejecting malformed embeddings, see
+// domain.MultiVectorEmbedding.Validate.
+func (r *Router) encodeMultiVector(
+	ctx context.Context,
+	utterance string,
+) (domain.MultiVectorEmbedding, error) {
+	callCtx, cancel := r.callContext(ctx)
+	defer cancel()
+	em, err := r.Encoder.(MultiVectorEncoder).EncodeMulti(callCtx, utterance)
+	if err != nil {
+		return domain.MultiVectorEmbedding{}, callError(callCtx, err)
+	}
+	err = em.Validate()
+	if err != nil {
+		return domain.MultiVectorEmbedding{}, err
+	}
+	return em, nil
+}
+
+// getMultiVector gets the multi-vector embedding of the given utterance from
+// the router's store, rejecting malformed embeddings, see
+// domain.MultiVectorEmbedding.Validate.
+func (r *Router) getMultiVector(
+	ctx context.Context,
+	utterance string,
+) (domain.MultiVectorEmbedding, error) {
+	callCtx, cancel := r.callContext(ctx)
+	defer cancel()
+	em, err := r.Storage.(MultiVectorStore).GetMulti(callCtx, r.key(utterance))
+	if err != nil {
+		return domain.MultiVectorEmbedding{}, callError(callCtx, err)
+	}
+	err = em.Validate()
+	if err != nil {
+		return domain.MultiVectorEmbedding{}, err
+	}
+	return em, nil
+}
+
+// storeMultiVector stores the given multi-vector utterance in the router's
+// store.
+func (r *Router) storeMultiVector(
+	ctx context.Context,
+	utterance domain.MultiVectorUtterance,
+) error {
+	callCtx, cancel := r.callContext(ctx)
+	defer cancel()
+	r.invalidateEntry(utterance.Utterance)
+	utterance.Utterance = r.key(utterance.Utterance)
+	err := r.Storage.(MultiVectorStore).StoreMulti(callCtx, utterance)
+	if err != nil {
+		return callError(callCtx, err)
+	}
+	return nil
+}
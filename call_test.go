@@ -0,0 +1,126 @@
+package semanticrouter
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/conneroisu/go-semantic-router/stores/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// slowEncoder is an encoder that waits for a delay before delegating to
+// another encoder.
+type slowEncoder struct {
+	Encoder
+	delay time.Duration
+	slow  bool
+}
+
+// Encode waits for the delay, if slow is set, before encoding the utterance.
+func (s *slowEncoder) Encode(
+	ctx context.Context,
+	utterance string,
+) ([]float64, error) {
+	if s.slow {
+		select {
+		case <-time.After(s.delay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	return s.Encoder.Encode(ctx, utterance)
+}
+
+// TestWithCallTimeout tests that a slow encoder call is aborted once the
+// configured call timeout elapses.
+func TestWithCallTimeout(t *testing.T) {
+	ctx := context.Background()
+	encoder := &slowEncoder{Encoder: newTestEncoder(), delay: time.Second}
+	router, err := NewRouter(
+		newTestRoutes(),
+		encoder,
+		memory.NewStore(),
+		WithCallTimeout(10*time.Millisecond),
+	)
+	require.NoError(t, err)
+
+	route, _, err := router.Match(ctx, "is it raining outside?")
+	require.NoError(t, err)
+	assert.Equal(t, "chitchat", route)
+
+	encoder.slow = true
+	start := time.Now()
+	_, _, err = router.Match(ctx, "is it raining outside?")
+	assert.Error(t, err)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.Less(t, time.Since(start), encoder.delay)
+}
+
+// cancelingEncoder is an encoder canceling a context once it has encoded a
+// given number of utterances.
+type cancelingEncoder struct {
+	Encoder
+	cancel context.CancelFunc
+	after  int
+	calls  int
+}
+
+// Encode encodes the utterance, then cancels the context if enough
+// utterances were encoded.
+func (c *cancelingEncoder) Encode(
+	ctx context.Context,
+	utterance string,
+) ([]float64, error) {
+	c.calls++
+	if c.calls == c.after {
+		c.cancel()
+	}
+	return c.Encoder.Encode(ctx, utterance)
+}
+
+// TestNewRouterContextCanceled tests that a build stops once its context is
+// canceled.
+func TestNewRouterContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	encoder := &cancelingEncoder{
+		Encoder: newTestEncoder(),
+		cancel:  cancel,
+		after:   2,
+	}
+	_, err := NewRouterContext(ctx, newTestRoutes(), encoder, memory.NewStore())
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 2, encoder.calls)
+}
+
+// TestWithOutputDimension tests that stored and query embeddings are
+// truncated to the output dimension and that matching still works.
+func TestWithOutputDimension(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewStore()
+	router, err := NewRouter(newTestRoutes(), newTestEncoder(), store, WithOutputDimension(2))
+	require.NoError(t, err)
+
+	em, err := store.Get(ctx, "how's the weather today?")
+	require.NoError(t, err)
+	assert.Equal(t, []float64{1.0, 0.1}, em)
+	qs, err := router.encodeQueries(ctx, "is it raining outside?")
+	require.NoError(t, err)
+	require.Len(t, qs, 1)
+	assert.Equal(t, 2, qs[0].vec.Len())
+
+	route, _, err := router.Match(ctx, "is it raining outside?")
+	require.NoError(t, err)
+	assert.Equal(t, "chitchat", route)
+	route, _, err = router.Match(ctx, "what about the election?")
+	require.NoError(t, err)
+	assert.Equal(t, "politics", route)
+
+	_, err = NewRouter(newTestRoutes(), newTestEncoder(), memory.NewStore(), WithOutputDimension(4))
+	assert.ErrorAs(t, err, &ErrDimensionMismatch{})
+	_, err = NewRouter(newTestRoutes(), newTestEncoder(), memory.NewStore(), WithOutputDimension(0))
+	assert.Error(t, err)
+}
@@ -0,0 +1,50 @@
+package semanticrouter
+
+import (
+	"cmp"
+	"slices"
+)
+
+// candidateCounts returns the number of utterances of each route, in the
+// order of the routes, whose embeddings are loaded into the index when at
+// most limit embeddings are, see WithMaxCandidates. If limit is zero or
+// less, every utterance of the eligible routes is loaded.
+//
+// Each turn across the routes visits them in order of decreasing priority,
+// routes of equal priority keeping their order, see Route.Priority. Routes
+// with fewer utterances than the configured minimum count zero utterances,
+// and routes scored by their centroid count their centroid as one.
+func (r *Router) candidateCounts(limit int) []int {
+	sizes := make([]int, len(r.Routes))
+	for i, route := range r.Routes {
+		switch {
+		case len(route.Utterances) < r.minUtterances:
+		case r.centroids:
+			sizes[i] = 1
+		default:
+			sizes[i] = len(route.Utterances)
+		}
+	}
+	if limit <= 0 {
+		return sizes
+	}
+	order := make([]int, len(r.Routes))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		return cmp.Compare(r.Routes[b].Priority, r.Routes[a].Priority)
+	})
+	counts := make([]int, len(r.Routes))
+	for taken := true; taken && limit > 0; {
+		taken = false
+		for _, i := range order {
+			if limit > 0 && counts[i] < sizes[i] {
+				counts[i]++
+				limit--
+				taken = true
+			}
+		}
+	}
+	return counts
+}
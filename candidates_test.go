@@ -0,0 +1,78 @@
+package semanticrouter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/conneroisu/go-semantic-router/stores/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestWithMaxCandidates tests that Match fetches and scores at most the
+// given number of utterances, taken in turn across the routes.
+func TestWithMaxCandidates(t *testing.T) {
+	ctx := context.Background()
+	routes, encoder := newRandomRoutes(4, 8, 16)
+	for _, n := range []int{1, 3, 10, 100} {
+		store := &countingStore{store: memory.NewStore()}
+		router, err := NewRouter(routes, encoder, store, WithMaxCandidates(n))
+		require.NoError(t, err)
+		store.gets = 0
+		_, _, err = router.Match(ctx, "query")
+		require.NoError(t, err)
+		assert.Equal(t, min(n, 4*8), store.gets, "n=%d", n)
+
+		store.gets = 0
+		_, _, err = router.MatchExact(ctx, "query")
+		require.NoError(t, err)
+		assert.Equal(t, 4*8, store.gets, "MatchExact is not capped")
+	}
+
+	t.Run("spread across routes", func(t *testing.T) {
+		routes := newTestRoutes()
+		routes[0].Utterances = append(routes[0].Utterances, routes[0].Utterances...)
+		router, err := NewRouter(routes, newTestEncoder(), memory.NewStore(), WithMaxCandidates(3))
+		require.NoError(t, err)
+		assert.Equal(t, []int{2, 1}, router.candidateCounts(3))
+		assert.Equal(t, []int{4, 2}, router.candidateCounts(0))
+
+		// The best utterance of politics is its first one.
+		route, _, err := router.Match(ctx, "what about the election?")
+		require.NoError(t, err)
+		assert.Equal(t, "politics", route)
+	})
+
+	_, err := NewRouter(newTestRoutes(), newTestEncoder(), memory.NewStore(), WithMaxCandidates(0))
+	assert.Error(t, err)
+}
+
+// TestWithMaxCandidatesPriority tests that a route pinned with a priority is
+// scored even when the candidate cap leaves the other routes out.
+func TestWithMaxCandidatesPriority(t *testing.T) {
+	ctx := context.Background()
+	router, err := NewRouter(newTestRoutes(), newTestEncoder(), memory.NewStore(), WithMaxCandidates(1))
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 0}, router.candidateCounts(1))
+	route, _, err := router.Match(ctx, "is it raining outside?")
+	require.NoError(t, err)
+	assert.Equal(t, "chitchat", route)
+
+	routes := newTestRoutes()
+	routes[1].Priority = 1
+	router, err = NewRouter(routes, newTestEncoder(), memory.NewStore(), WithMaxCandidates(1))
+	require.NoError(t, err)
+	assert.Equal(t, []int{0, 1}, router.candidateCounts(1))
+	route, _, err = router.Match(ctx, "is it raining outside?")
+	require.NoError(t, err)
+	assert.Equal(t, "politics", route)
+
+	// Routes of equal priority keep their order within each turn.
+	routes, _ = newRandomRoutes(4, 2, 4)
+	routes[2].Priority = 2
+	routes[3].Priority = 1
+	routes[1].Priority = 1
+	router = &Router{Routes: routes}
+	assert.Equal(t, []int{0, 1, 1, 1}, router.candidateCounts(3))
+	assert.Equal(t, []int{1, 2, 2, 2}, router.candidateCounts(7))
+}
@@ -0,0 +1,70 @@
+package semanticrouter
+
+import (
+	"context"
+
+	"github.com/conneroisu/go-semantic-router/domain"
+)
+
+// centroidKeyPrefix prefixes the keys of route centroids in the store.
+const centroidKeyPrefix = "semanticrouter:centroid:"
+
+// CentroidKey returns the key under which the centroid of the route with the
+// given name is stored, see WithRouteCentroids.
+func CentroidKey(route string) string {
+	return centroidKeyPrefix + route
+}
+
+// routeCentroid is the running mean of the embeddings of the utterances of
+// a route.
+type routeCentroid struct {
+	mean  []float64
+	count int
+}
+
+// add returns the centroid updated with the given embedding.
+func (c routeCentroid) add(embedding []float64) routeCentroid {
+	mean := make([]float64, len(embedding))
+	copy(mean, c.mean)
+	count := c.count + 1
+	for i, v := range embedding {
+		mean[i] += (v - mean[i]) / float64(count)
+	}
+	return routeCentroid{mean: mean, count: count}
+}
+
+// centroid returns the centroid of the given route, reading it from the
+// store if the router has not computed it.
+func (r *Router) centroid(ctx context.Context, route Route) (routeCentroid, error) {
+	r.centroidsMu.Lock()
+	c, ok := r.centroidState[route.Name]
+	r.centroidsMu.Unlock()
+	if ok {
+		return c, nil
+	}
+	mean, err := r.get(ctx, CentroidKey(route.Name))
+	if err != nil {
+		return routeCentroid{}, ErrGetEmbedding{Message: "error getting centroid", Err: err}
+	}
+	return routeCentroid{mean: mean, count: len(route.Utterances)}, nil
+}
+
+// storeCentroid stores the given centroid of a route.
+func (r *Router) storeCentroid(
+	ctx context.Context,
+	route string,
+	c routeCentroid,
+) error {
+	return r.storeEmbedding(ctx, domain.Utterance{Utterance: CentroidKey(route)}, c.mean)
+}
+
+// setCentroid keeps the given centroid of a route to update it
+// incrementally, once it is stored.
+func (r *Router) setCentroid(route string, c routeCentroid) {
+	r.centroidsMu.Lock()
+	defer r.centroidsMu.Unlock()
+	if r.centroidState == nil {
+		r.centroidState = make(map[string]routeCentroid)
+	}
+	r.centroidState[route] = c
+}
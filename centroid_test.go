@@ -0,0 +1,130 @@
+package semanticrouter
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/conneroisu/go-semantic-router/domain"
+	"github.com/conneroisu/go-semantic-router/stores/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingStore is a store counting the calls to Get.
+type countingStore struct {
+	store Store
+	gets  int
+}
+
+// Get counts the call before delegating to the underlying store.
+func (c *countingStore) Get(ctx context.Context, utterance string) ([]float64, error) {
+	c.gets++
+	return c.store.Get(ctx, utterance)
+}
+
+// Store stores the utterance in the underlying store.
+func (c *countingStore) Store(ctx context.Context, utterance domain.Utterance) error {
+	return c.store.Store(ctx, utterance)
+}
+
+// TestWithRouteCentroids tests that centroid mode matches like a full scan
+// on the test queries while loading one vector per route.
+func TestWithRouteCentroids(t *testing.T) {
+	ctx := context.Background()
+	full, err := NewRouter(newTestRoutes(), newTestEncoder(), memory.NewStore())
+	require.NoError(t, err)
+	store := &countingStore{store: memory.NewStore()}
+	centroids, err := NewRouter(
+		newTestRoutes(),
+		newTestEncoder(),
+		store,
+		WithRouteCentroids(true),
+	)
+	require.NoError(t, err)
+
+	centroid, err := store.Get(ctx, CentroidKey("chitchat"))
+	require.NoError(t, err)
+	assert.InDeltaSlice(t, []float64{0.95, 0.15, 0.0}, centroid, 1e-12)
+
+	for _, utterance := range []string{"is it raining outside?", "what about the election?"} {
+		want, _, err := full.Match(ctx, utterance)
+		require.NoError(t, err)
+		store.gets = 0
+		got, _, err := centroids.Match(ctx, utterance)
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+		assert.Equal(t, 2, store.gets)
+	}
+
+	_, err = NewRouter(
+		newTestRoutes(),
+		sparseEncoder{newTestEncoder()},
+		memory.NewStore(),
+		WithSparseEmbeddings(),
+		WithRouteCentroids(true),
+	)
+	assert.Error(t, err)
+}
+
+// BenchmarkMatchCentroids compares the latency of matching against route
+// centroids with a full scan of the utterances.
+func BenchmarkMatchCentroids(b *testing.B) {
+	ctx := context.Background()
+	routes, encoder := newRandomRoutes(20, 50, 256)
+	for _, mode := range []bool{false, true} {
+		router, err := NewRouter(routes, encoder, memory.NewStore(), WithRouteCentroids(mode))
+		require.NoError(b, err)
+		b.Run(fmt.Sprintf("centroids=%t", mode), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				_, _, _ = router.Match(ctx, "query")
+			}
+		})
+	}
+}
+
+// TestCentroidIncrementalUpdate tests that adding utterances to a route
+// updates its centroid to the mean of all of its embeddings.
+func TestCentroidIncrementalUpdate(t *testing.T) {
+	ctx := context.Background()
+	encoder := newTestEncoder()
+	encoder.embeddings["sunny and warm"] = []float64{0.5, 0.5, 0.5}
+	encoder.embeddings["stormy night"] = []float64{0.7, 0.0, 0.3}
+	routes := newTestRoutes()
+	store := &countingStore{store: memory.NewStore()}
+	router, err := NewRouter(routes, encoder, store, WithRouteCentroids(true))
+	require.NoError(t, err)
+
+	store.gets = 0
+	require.NoError(t, router.AddUtterances(ctx, "chitchat", domain.Utterance{Utterance: "sunny and warm"}))
+	require.NoError(t, router.AddUtterances(ctx, "chitchat", domain.Utterance{Utterance: "stormy night"}))
+	// Only the dimension of the existing embeddings is read, not the
+	// embeddings of the route.
+	assert.Equal(t, 2, store.gets)
+	assert.Len(t, router.Routes[0].Utterances, 4)
+	assert.Len(t, routes[0].Utterances, 2)
+
+	incremental, err := store.Get(ctx, CentroidKey("chitchat"))
+	require.NoError(t, err)
+	full := make([]float64, 3)
+	for _, ut := range router.Routes[0].Utterances {
+		for i, v := range encoder.embeddings[ut.Utterance] {
+			full[i] += v / 4
+		}
+	}
+	assert.InDeltaSlice(t, full, incremental, 1e-12)
+
+	// A router without the computed centroids reads it from the store.
+	rebuilt := *router
+	rebuilt.centroidState = nil
+	encoder.embeddings["light breeze"] = []float64{0.9, 0.0, 0.0}
+	require.NoError(t, rebuilt.AddUtterances(ctx, "chitchat", domain.Utterance{Utterance: "light breeze"}))
+	incremental, err = store.Get(ctx, CentroidKey("chitchat"))
+	require.NoError(t, err)
+	for i := range full {
+		full[i] = (full[i]*4 + encoder.embeddings["light breeze"][i]) / 5
+	}
+	assert.InDeltaSlice(t, full, incremental, 1e-12)
+
+	assert.ErrorContains(t, router.AddUtterances(ctx, "missing"), `route not found: "missing"`)
+}
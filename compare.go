@@ -0,0 +1,53 @@
+package semanticrouter
+
+import (
+	"context"
+	"fmt"
+	"slices"
+)
+
+// Similarity returns the score between two arbitrary utterances under the
+// router's similarity functions, as if b were an utterance of a route and a
+// a query, without involving the routes or the store.
+//
+// Both utterances are encoded as queries, sharing the query cache and the
+// query prefix, so that the score is symmetric for symmetric similarity
+// functions. The functions overridden by routes are not used, and the scores
+// of cacheable functions are not cached, see Cacheable. It returns an
+// error if the score is NaN or infinite and the NaN policy skips it.
+func (r *Router) Similarity(ctx context.Context, a, b string) (float64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	qa, err := r.encodeQuery(ctx, a)
+	if err != nil {
+		return 0, err
+	}
+	qb, err := r.encodeQuery(ctx, b)
+	if err != nil {
+		return 0, err
+	}
+	entry := indexEntry{
+		utterance: b,
+		vec:       qb.vec,
+		norm:      qb.norm,
+		sparse:    qb.sparse,
+		multi:     qb.multi,
+	}
+	if qa.kind == denseEmbeddings && qa.vec.Len() != qb.vec.Len() {
+		return 0, ErrDimensionMismatch{Utterance: b, Expected: qa.vec.Len(), Actual: qb.vec.Len()}
+	}
+	// The scores of queries against each other are kept out of the score
+	// caches, which are meant for the index vectors of the routes.
+	fns := slices.Clone(r.biFuncCoefficients)
+	for i := range fns {
+		fns[i].cache = nil
+	}
+	score, ok, err := r.computeScore(qa, entry, fns)
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		return 0, fmt.Errorf("error comparing %q and %q: score is not a finite number", a, b)
+	}
+	return score, nil
+}
@@ -0,0 +1,53 @@
+package semanticrouter
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/conneroisu/go-semantic-router/stores/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSimilarity tests that identical utterances score at the maximum of the
+// cosine similarity and unrelated ones lower.
+func TestSimilarity(t *testing.T) {
+	ctx := context.Background()
+	router, err := NewRouter(newTestRoutes(), newTestEncoder(), memory.NewStore())
+	require.NoError(t, err)
+
+	same, err := router.Similarity(ctx, "lovely weather today", "lovely weather today")
+	require.NoError(t, err)
+	assert.InDelta(t, 1.0, same, 1e-12)
+	related, err := router.Similarity(ctx, "lovely weather today", "is it raining outside?")
+	require.NoError(t, err)
+	unrelated, err := router.Similarity(ctx, "lovely weather today", "who will win the vote?")
+	require.NoError(t, err)
+	assert.Less(t, related, same)
+	assert.Less(t, unrelated, related)
+
+	reversed, err := router.Similarity(ctx, "who will win the vote?", "lovely weather today")
+	require.NoError(t, err)
+	assert.InDelta(t, unrelated, reversed, 1e-12)
+
+	// The router's own similarity functions are used.
+	weighted, err := NewRouter(newTestRoutes(), newTestEncoder(), memory.NewStore(), WithCosineSimilarity(0.5), WithDotProduct(0.5))
+	require.NoError(t, err)
+	score, err := weighted.Similarity(ctx, "how's the weather today?", "how's the weather today?")
+	require.NoError(t, err)
+	assert.InDelta(t, 0.5*1.0+0.5*(1.0+0.01), score, 1e-12)
+
+	_, err = router.Similarity(ctx, "lovely weather today", "an unknown utterance")
+	assert.Error(t, err)
+
+	// Scores of cacheable functions are not cached.
+	var calls atomic.Int64
+	cached, err := NewRouter(newTestRoutes(), newTestEncoder(), memory.NewStore(), WithCustomSimilarity("cached", countingSimilarity(&calls), 1.0, Cacheable(16)))
+	require.NoError(t, err)
+	for i := 0; i < 2; i++ {
+		_, err = cached.Similarity(ctx, "lovely weather today", "is it raining outside?")
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int64(2), calls.Load())
+}
@@ -0,0 +1,112 @@
+package semanticrouter
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// prefetchEmbeddings encodes the distinct utterances of the given routes
+// concurrently ahead of storing them, see WithAutoConcurrency.
+//
+// The concurrency starts at one and is increased by one at each round of as
+// many calls as the current concurrency, as long as the throughput of the
+// rounds improves and the concurrency is below autoConcurrency. Once it
+// stops improving, the best concurrency found encodes the remaining
+// utterances and is reported to the observer.
+func (r *Router) prefetchEmbeddings(
+	ctx context.Context,
+	routes []Route,
+) (map[string][]float64, error) {
+	var utterances []string
+	seen := make(map[string]bool)
+	for _, route := range routes {
+		for _, utter := range route.Utterances {
+			if !seen[utter.Utterance] {
+				seen[utter.Utterance] = true
+				utterances = append(utterances, utter.Utterance)
+			}
+		}
+	}
+	embeddings := make(map[string][]float64, len(utterances))
+	level, best := 1, 0.0
+	pos := 0
+	for pos < len(utterances) && level < r.autoConcurrency {
+		batch := utterances[pos:min(pos+level, len(utterances))]
+		start := time.Now()
+		err := r.encodeConcurrently(ctx, batch, len(batch), embeddings)
+		if err != nil {
+			return nil, err
+		}
+		pos += len(batch)
+		if len(batch) < level {
+			break
+		}
+		throughput := float64(len(batch)) / max(time.Since(start).Seconds(), 1e-9)
+		if throughput <= best {
+			level--
+			break
+		}
+		best = throughput
+		level++
+	}
+	r.notify().ObserveConcurrency(level)
+	err := r.encodeConcurrently(ctx, utterances[pos:], level, embeddings)
+	if err != nil {
+		return nil, err
+	}
+	return embeddings, nil
+}
+
+// encodeConcurrently encodes the given utterances with at most n concurrent
+// encoder calls, recording their embeddings in the given map.
+//
+// It stops at the first error, returning it as an ErrEncoding, or once the
+// given context is done, returning the context's error.
+func (r *Router) encodeConcurrently(
+	ctx context.Context,
+	utterances []string,
+	n int,
+	embeddings map[string][]float64,
+) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var (
+		mu       sync.Mutex
+		firstErr error
+		wg       sync.WaitGroup
+	)
+	work := make(chan string)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for utterance := range work {
+				em, err := r.encode(ctx, r.documentInput(utterance))
+				mu.Lock()
+				if err != nil && firstErr == nil {
+					firstErr = ErrEncoding{Message: "error encoding utterance", Err: err}
+					cancel()
+				}
+				if err == nil {
+					embeddings[utterance] = em
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+feed:
+	for _, utterance := range utterances {
+		select {
+		case work <- utterance:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(work)
+	wg.Wait()
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
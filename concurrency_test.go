@@ -0,0 +1,114 @@
+package semanticrouter
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/conneroisu/go-semantic-router/stores/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// saturatingEncoder is an encoder taking a fixed latency per call and
+// serving at most capacity calls at once, queueing the others.
+type saturatingEncoder struct {
+	Encoder
+	latency  time.Duration
+	slots    chan struct{}
+	mu       sync.Mutex
+	inFlight int
+	peak     int // peak is the highest number of concurrent calls.
+}
+
+// newSaturatingEncoder creates a new saturatingEncoder delegating to the
+// given encoder.
+func newSaturatingEncoder(encoder Encoder, latency time.Duration, capacity int) *saturatingEncoder {
+	return &saturatingEncoder{
+		Encoder: encoder,
+		latency: latency,
+		slots:   make(chan struct{}, capacity),
+	}
+}
+
+// Encode waits for a free slot and the latency before encoding the
+// utterance.
+func (e *saturatingEncoder) Encode(ctx context.Context, utterance string) ([]float64, error) {
+	e.mu.Lock()
+	e.inFlight++
+	e.peak = max(e.peak, e.inFlight)
+	e.mu.Unlock()
+	defer func() {
+		e.mu.Lock()
+		e.inFlight--
+		e.mu.Unlock()
+	}()
+	select {
+	case e.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	defer func() { <-e.slots }()
+	select {
+	case <-time.After(e.latency):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	return e.Encoder.Encode(ctx, utterance)
+}
+
+// concurrencyObserver is an observer recording the chosen concurrency.
+type concurrencyObserver struct {
+	NopObserver
+	level int
+}
+
+// ObserveConcurrency records the chosen concurrency.
+func (o *concurrencyObserver) ObserveConcurrency(level int) {
+	o.level = level
+}
+
+// TestWithAutoConcurrency tests that the concurrency settles at the
+// capacity of the encoder, within the given maximum, and that the router
+// matches as if built serially.
+func TestWithAutoConcurrency(t *testing.T) {
+	ctx := context.Background()
+	routes, encoder := newRandomRoutes(6, 10, 4)
+	serial, err := NewRouter(routes, encoder, memory.NewStore())
+	require.NoError(t, err)
+
+	saturating := newSaturatingEncoder(encoder, 20*time.Millisecond, 3)
+	observer := &concurrencyObserver{}
+	router, err := NewRouter(
+		routes,
+		saturating,
+		memory.NewStore(),
+		WithAutoConcurrency(8),
+		WithObserver(observer),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, 3, observer.level)
+	assert.LessOrEqual(t, saturating.peak, 8)
+
+	want, err := serial.ScoreAll(ctx, "query")
+	require.NoError(t, err)
+	got, err := router.ScoreAll(ctx, "query")
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+
+	// The concurrency never exceeds the maximum.
+	saturating = newSaturatingEncoder(encoder, time.Millisecond, 16)
+	_, err = NewRouter(routes, saturating, memory.NewStore(), WithAutoConcurrency(2), WithObserver(observer))
+	require.NoError(t, err)
+	assert.LessOrEqual(t, observer.level, 2)
+	assert.LessOrEqual(t, saturating.peak, 2)
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = NewRouterContext(canceled, routes, saturating, memory.NewStore(), WithAutoConcurrency(2))
+	assert.ErrorIs(t, err, context.Canceled)
+
+	_, err = NewRouter(routes, encoder, memory.NewStore(), WithAutoConcurrency(0))
+	assert.Error(t, err)
+}
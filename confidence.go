@@ -0,0 +1,70 @@
+package semanticrouter
+
+import (
+	"context"
+	"errors"
+)
+
+// Confidence is the confidence band of a match, see WithConfidenceBands.
+type Confidence int
+
+const (
+	// ConfidenceNone means that no route matched.
+	ConfidenceNone Confidence = iota
+	// ConfidenceLow means that a route matched with a score between the low
+	// and the high bands, which the caller may want to have confirmed.
+	ConfidenceLow
+	// ConfidenceHigh means that a route matched with a score reaching the
+	// high band.
+	ConfidenceHigh
+)
+
+// String returns the name of the confidence band.
+func (c Confidence) String() string {
+	switch c {
+	case ConfidenceLow:
+		return "low"
+	case ConfidenceHigh:
+		return "high"
+	default:
+		return "none"
+	}
+}
+
+// confidenceBands are the bounds of the confidence bands of matches.
+type confidenceBands struct {
+	low  float64 // low is the minimum score of a match.
+	high float64 // high is the minimum score of a high confidence match.
+}
+
+// confidence returns the confidence band of an accepted match of the given
+// score.
+func (r *Router) confidence(score float64) Confidence {
+	if r.bands == nil || score >= r.bands.high {
+		return ConfidenceHigh
+	}
+	return ConfidenceLow
+}
+
+// MatchConfidence is like Match, but also returns the confidence band of the
+// match, see WithConfidenceBands, so that the caller can ask for
+// confirmation of low confidence matches.
+//
+// When no route matches, route is empty, confidence is ConfidenceNone and
+// err is nil, as with TryMatch. Without confidence bands every match is
+// ConfidenceHigh.
+func (r *Router) MatchConfidence(
+	ctx context.Context,
+	utterance string,
+) (route string, score float64, confidence Confidence, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	route, score, err = r.match(ctx, utterance, true)
+	if errors.Is(err, ErrNoRouteFound) {
+		return "", 0.0, ConfidenceNone, nil
+	}
+	if err != nil {
+		return "", 0.0, ConfidenceNone, err
+	}
+	return route, score, r.confidence(score), nil
+}
@@ -0,0 +1,72 @@
+package semanticrouter
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/conneroisu/go-semantic-router/stores/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMatchConfidence tests that matches are reported in the confidence band
+// of their score.
+func TestMatchConfidence(t *testing.T) {
+	ctx := context.Background()
+	router, err := NewRouter(newTestRoutes(), newTestEncoder(), memory.NewStore(), WithConfidenceBands(0.985, 0.99))
+	require.NoError(t, err)
+
+	tests := []struct {
+		utterance  string
+		route      string
+		confidence Confidence
+	}{
+		// The scores are 1.0, about 0.9876 and about 0.982.
+		{utterance: "how's the weather today?", route: "chitchat", confidence: ConfidenceHigh},
+		{utterance: "is it raining outside?", route: "chitchat", confidence: ConfidenceLow},
+		{utterance: "what about the election?", route: "", confidence: ConfidenceNone},
+	}
+	for _, tt := range tests {
+		t.Run(tt.confidence.String(), func(t *testing.T) {
+			route, score, confidence, err := router.MatchConfidence(ctx, tt.utterance)
+			require.NoError(t, err)
+			assert.Equal(t, tt.route, route)
+			assert.Equal(t, tt.confidence, confidence)
+
+			matched, matchedScore, err := router.Match(ctx, tt.utterance)
+			if tt.confidence == ConfidenceNone {
+				assert.ErrorIs(t, err, ErrNoRouteFound)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, route, matched)
+			assert.Equal(t, score, matchedScore)
+		})
+	}
+
+	t.Run("without bands", func(t *testing.T) {
+		router, err := NewRouter(newTestRoutes(), newTestEncoder(), memory.NewStore())
+		require.NoError(t, err)
+		route, _, confidence, err := router.MatchConfidence(ctx, "what about the election?")
+		require.NoError(t, err)
+		assert.Equal(t, "politics", route)
+		assert.Equal(t, ConfidenceHigh, confidence)
+	})
+
+	t.Run("encoder error", func(t *testing.T) {
+		_, _, confidence, err := router.MatchConfidence(ctx, "unknown")
+		assert.Error(t, err)
+		assert.Equal(t, ConfidenceNone, confidence)
+	})
+}
+
+// TestWithConfidenceBands tests that invalid confidence bands are rejected.
+func TestWithConfidenceBands(t *testing.T) {
+	for _, bands := range [][2]float64{{0.9, 0.5}, {math.NaN(), 0.5}, {0.5, math.Inf(1)}} {
+		_, err := NewRouter(newTestRoutes(), newTestEncoder(), memory.NewStore(), WithConfidenceBands(bands[0], bands[1]))
+		assert.Error(t, err)
+	}
+	_, err := NewRouter(newTestRoutes(), newTestEncoder(), memory.NewStore(), WithConfidenceBands(0.5, 0.8), WithScoreDirection(LowerIsBetter))
+	assert.Error(t, err)
+}
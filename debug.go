@@ -0,0 +1,91 @@
+package semanticrouter
+
+import (
+	"context"
+	"fmt"
+	"math"
+)
+
+// histogramBuckets is the number of buckets of the histograms returned by
+// DebugScoreDistribution.
+const histogramBuckets = 10
+
+// Histogram is the distribution of the best scores of a batch of queries.
+//
+// Bucket i counts the scores s such that Edges[i] <= s < Edges[i+1]; scores
+// below the first edge or above the last one are counted in the first and
+// last buckets respectively.
+type Histogram struct {
+	Edges     []float64 `json:"edges"     yaml:"edges"     toml:"edges"`     // Edges are the edges of the buckets, one more than there are buckets.
+	Counts    []int     `json:"counts"    yaml:"counts"    toml:"counts"`    // Counts are the number of scores in each bucket.
+	Unmatched int       `json:"unmatched" yaml:"unmatched" toml:"unmatched"` // Unmatched is the number of queries without any scored route.
+}
+
+// DebugScoreDistribution matches each of the given utterances and returns the
+// distribution of their best scores in ten buckets between 0 and 1.
+//
+// The best score of each utterance is taken regardless of the router's
+// threshold, which makes the distribution useful for picking one. The
+// buckets suit cosine similarities; scores of other ranges, such as
+// distances with LowerIsBetter, call for DebugScoreDistributionRange.
+func (r *Router) DebugScoreDistribution(
+	ctx context.Context,
+	utterances []string,
+) (Histogram, error) {
+	return r.DebugScoreDistributionRange(ctx, utterances, 0, 1, histogramBuckets)
+}
+
+// DebugScoreDistributionRange is like DebugScoreDistribution, but with the
+// given number of buckets of equal width between low and high.
+func (r *Router) DebugScoreDistributionRange(
+	ctx context.Context,
+	utterances []string,
+	low, high float64,
+	buckets int,
+) (Histogram, error) {
+	if buckets <= 0 {
+		return Histogram{}, fmt.Errorf("histogram bucket count %d is not positive", buckets)
+	}
+	if !(low < high) || math.IsInf(low, 0) || math.IsInf(high, 0) {
+		return Histogram{}, fmt.Errorf("histogram range [%v, %v] is not a finite non-empty interval", low, high)
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	hist := Histogram{
+		Edges:  make([]float64, buckets+1),
+		Counts: make([]int, buckets),
+	}
+	width := (high - low) / float64(buckets)
+	for i := range hist.Edges {
+		hist.Edges[i] = low + float64(i)*width
+	}
+	hist.Edges[buckets] = high
+	index, err := r.loadIndex(ctx)
+	if err != nil {
+		return Histogram{}, err
+	}
+	for _, utterance := range utterances {
+		qs, err := r.encodeQueries(ctx, utterance)
+		if err != nil {
+			return Histogram{}, err
+		}
+		ranked, err := r.rankIndex(ctx, utterance, qs, index, false)
+		if err != nil {
+			return Histogram{}, err
+		}
+		if len(ranked.scores) == 0 {
+			hist.Unmatched++
+			continue
+		}
+		best := r.worstScore()
+		for _, rs := range ranked.scores {
+			if r.better(rs.score, best) {
+				best = rs.score
+			}
+		}
+		bucket := int(math.Floor((best - low) / width))
+		bucket = max(0, min(bucket, buckets-1))
+		hist.Counts[bucket]++
+	}
+	return hist, nil
+}
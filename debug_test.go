@@ -0,0 +1,68 @@
+package semanticrouter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/conneroisu/go-semantic-router/stores/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestDebugScoreDistribution tests that the best scores of known queries
+// fall in the expected buckets.
+func TestDebugScoreDistribution(t *testing.T) {
+	ctx := context.Background()
+	encoder := newTestEncoder()
+	encoder.embeddings["nothing in common"] = []float64{0.0, 0.0, 1.0}
+	encoder.embeddings["opposite"] = []float64{-1.0, -1.0, 0.0}
+	router, err := NewRouter(newTestRoutes(), encoder, memory.NewStore())
+	require.NoError(t, err)
+
+	hist, err := router.DebugScoreDistribution(ctx, []string{
+		"is it raining outside?",
+		"what about the election?",
+		"lovely weather today",
+		"nothing in common",
+		"opposite",
+	})
+	require.NoError(t, err)
+	assert.Len(t, hist.Edges, 11)
+	assert.InDelta(t, 0.5, hist.Edges[5], 1e-12)
+	// The best scores are about 0.99, 0.96, 1, 0.1 and -0.7, the latter
+	// being counted in the first bucket.
+	assert.Equal(t, []int{2, 0, 0, 0, 0, 0, 0, 0, 0, 3}, hist.Counts)
+	assert.Zero(t, hist.Unmatched)
+}
+
+// TestDebugScoreDistributionRange tests the buckets of a score distribution
+// over a custom range, such as the distances of a lower-is-better router.
+func TestDebugScoreDistributionRange(t *testing.T) {
+	ctx := context.Background()
+	encoder := newTestEncoder()
+	encoder.embeddings["opposite"] = []float64{-1.0, -1.0, 0.0}
+	router, err := NewRouter(
+		newTestRoutes(),
+		encoder,
+		memory.NewStore(),
+		WithCustomSimilarity("euclidean_distance", EuclideanDistance, 1.0),
+		WithScoreDirection(LowerIsBetter),
+	)
+	require.NoError(t, err)
+
+	hist, err := router.DebugScoreDistributionRange(ctx, []string{
+		"is it raining outside?",
+		"lovely weather today",
+		"opposite",
+	}, 0, 2, 4)
+	require.NoError(t, err)
+	assert.Equal(t, []float64{0, 0.5, 1, 1.5, 2}, hist.Edges)
+	// The best distances are about 0.24, 0 and 2.2, the latter being counted
+	// in the last bucket.
+	assert.Equal(t, []int{2, 0, 0, 1}, hist.Counts)
+
+	_, err = router.DebugScoreDistributionRange(ctx, nil, 0, 2, 0)
+	assert.Error(t, err)
+	_, err = router.DebugScoreDistributionRange(ctx, nil, 1, 1, 4)
+	assert.Error(t, err)
+}
@@ -0,0 +1,75 @@
+package semanticrouter
+
+import (
+	"fmt"
+	"math"
+)
+
+// ScoreDirection tells whether higher or lower scores are better, see
+// WithScoreDirection.
+type ScoreDirection int
+
+const (
+	// HigherIsBetter makes the route with the highest score win, as with
+	// similarities.
+	HigherIsBetter ScoreDirection = iota
+	// LowerIsBetter makes the route with the lowest score win, as with
+	// distances.
+	LowerIsBetter
+)
+
+// better reports whether score a is better than score b.
+func (r *Router) better(a, b float64) bool {
+	if r.scoreDirection == LowerIsBetter {
+		return a < b
+	}
+	return a > b
+}
+
+// worstScore returns the score every other score is better than.
+func (r *Router) worstScore() float64 {
+	if r.scoreDirection == LowerIsBetter {
+		return math.Inf(1)
+	}
+	return math.Inf(-1)
+}
+
+// accepts reports whether the best score of a match is good enough for its
+// route to be returned.
+//
+// Higher-is-better scores must be positive, at least the threshold and at
+// least the low confidence band, if any. Lower-is-better scores must be at
+// most the threshold, if the router has one.
+func (r *Router) accepts(score float64) bool {
+	if r.scoreDirection == LowerIsBetter {
+		return r.threshold == nil || score <= r.Threshold()
+	}
+	if r.bands != nil && score < r.bands.low {
+		return false
+	}
+	return score > 0 && score >= r.Threshold()
+}
+
+// checkScoreDirection checks that the options of the router support its
+// score direction.
+//
+// KNN voting, route priors, reranker boosts and keyword fallback scores all
+// assume that higher scores are better.
+func (r *Router) checkScoreDirection() error {
+	if r.scoreDirection != LowerIsBetter {
+		return nil
+	}
+	switch {
+	case r.knn > 0:
+		return fmt.Errorf("lower-is-better scores do not support KNN voting")
+	case r.reranker != nil:
+		return fmt.Errorf("lower-is-better scores do not support rerankers")
+	case r.encoderFallback:
+		return fmt.Errorf("lower-is-better scores do not support encoder fallback")
+	case r.hasPriors():
+		return fmt.Errorf("lower-is-better scores do not support route priors")
+	case r.bands != nil:
+		return fmt.Errorf("lower-is-better scores do not support confidence bands")
+	}
+	return nil
+}
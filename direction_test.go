@@ -0,0 +1,94 @@
+package semanticrouter
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/conneroisu/go-semantic-router/stores/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestWithScoreDirection tests that a raw distance configuration matches
+// the nearest route under LowerIsBetter.
+func TestWithScoreDirection(t *testing.T) {
+	ctx := context.Background()
+	distance := WithCustomSimilarity("euclidean_distance", EuclideanDistance, 1.0)
+
+	// Higher-is-better picks the farthest route.
+	router, err := NewRouter(newTestRoutes(), newTestEncoder(), memory.NewStore(), distance)
+	require.NoError(t, err)
+	route, _, err := router.Match(ctx, "is it raining outside?")
+	require.NoError(t, err)
+	assert.Equal(t, "politics", route)
+
+	router, err = NewRouter(
+		newTestRoutes(),
+		newTestEncoder(),
+		memory.NewStore(),
+		distance,
+		WithScoreDirection(LowerIsBetter),
+	)
+	require.NoError(t, err)
+	for utterance, want := range map[string]string{
+		"is it raining outside?":   "chitchat",
+		"what about the election?": "politics",
+	} {
+		route, _, err := router.Match(ctx, utterance)
+		require.NoError(t, err)
+		assert.Equal(t, want, route, utterance)
+	}
+	route, score, err := router.Match(ctx, "is it raining outside?")
+	require.NoError(t, err)
+	assert.Equal(t, "chitchat", route)
+	assert.InDelta(t, math.Sqrt(0.06), score, 1e-9)
+
+	ranked, err := router.RankRoutes(ctx, "is it raining outside?")
+	require.NoError(t, err)
+	require.Len(t, ranked, 2)
+	assert.Equal(t, "chitchat", ranked[0].Route)
+	assert.Less(t, ranked[0].Score, ranked[1].Score)
+
+	probabilities, err := router.MatchProbabilities(ctx, "is it raining outside?")
+	require.NoError(t, err)
+	assert.Greater(t, probabilities["chitchat"], probabilities["politics"])
+}
+
+// TestScoreDirectionThreshold tests that the threshold is a maximum score
+// under LowerIsBetter.
+func TestScoreDirectionThreshold(t *testing.T) {
+	ctx := context.Background()
+	router, err := NewRouter(
+		newTestRoutes(),
+		newTestEncoder(),
+		memory.NewStore(),
+		WithCustomSimilarity("euclidean_distance", EuclideanDistance, 1.0),
+		WithScoreDirection(LowerIsBetter),
+		WithAdaptiveThreshold(0.25),
+	)
+	require.NoError(t, err)
+	route, _, err := router.Match(ctx, "is it raining outside?")
+	require.NoError(t, err)
+	assert.Equal(t, "chitchat", route)
+
+	// A false positive makes the threshold stricter.
+	router.RecordFeedback("is it raining outside?", route, false)
+	assert.InDelta(t, 0.24, router.Threshold(), 1e-9)
+	_, _, err = router.Match(ctx, "is it raining outside?")
+	assert.ErrorIs(t, err, ErrNoRouteFound)
+}
+
+// TestScoreDirectionUnsupported tests that options assuming higher scores
+// are better are rejected under LowerIsBetter.
+func TestScoreDirectionUnsupported(t *testing.T) {
+	for name, opt := range map[string]Option{
+		"knn":      WithKNNVoting(3),
+		"fallback": WithEncoderFallback(true),
+	} {
+		_, err := NewRouter(newTestRoutes(), newTestEncoder(), memory.NewStore(), opt, WithScoreDirection(LowerIsBetter))
+		assert.Error(t, err, name)
+	}
+	_, err := NewRouter(newTestRoutes(), newTestEncoder(), memory.NewStore(), WithScoreDirection(ScoreDirection(2)))
+	assert.Error(t, err)
+}
@@ -0,0 +1,43 @@
+package domain
+
+import "fmt"
+
+// MultiVectorEmbedding is a multi-vector embedding made of one vector per
+// token, such as the embeddings produced by ColBERT models.
+//
+// Weights optionally weighs each vector; if it is empty, every vector has a
+// weight of one. Otherwise it must have the same length as Vectors.
+type MultiVectorEmbedding struct {
+	Vectors [][]float64 `json:"vectors"` // Vectors are the token vectors.
+	Weights []float64   `json:"weights"` // Weights are the weights of the token vectors.
+}
+
+// MultiVectorUtterance represents a utterance with a multi-vector embedding
+// in the semantic router.
+type MultiVectorUtterance struct {
+	// Utterance is the utterance.
+	Utterance string `json:"utterance"`
+	// Embedding is the multi-vector embedding of the utterance.
+	Embedding MultiVectorEmbedding `json:"embedding"`
+}
+
+// Weight returns the weight of the i-th vector of the embedding.
+func (m MultiVectorEmbedding) Weight(i int) float64 {
+	if len(m.Weights) == 0 {
+		return 1
+	}
+	return m.Weights[i]
+}
+
+// Validate reports whether the embedding is well-formed, that is whether
+// Weights is either empty or has the same length as Vectors.
+func (m MultiVectorEmbedding) Validate() error {
+	if len(m.Weights) != 0 && len(m.Weights) != len(m.Vectors) {
+		return fmt.Errorf(
+			"multi-vector embedding has %d weights for %d vectors",
+			len(m.Weights),
+			len(m.Vectors),
+		)
+	}
+	return nil
+}
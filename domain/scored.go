@@ -0,0 +1,7 @@
+package domain
+
+// ScoredUtterance is a utterance along with its similarity score to a query.
+type ScoredUtterance struct {
+	Utterance string  `json:"utterance"` // Utterance is the utterance.
+	Score     float64 `json:"score"`     // Score is the similarity score of the utterance.
+}
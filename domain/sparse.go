@@ -0,0 +1,55 @@
+package domain
+
+import "sort"
+
+// SparseEmbedding is a sparse embedding represented by index/value pairs of
+// its non-zero components, such as the embeddings produced by SPLADE models.
+//
+// Indices must be sorted in ascending order and unique, see Sorted.
+type SparseEmbedding struct {
+	Indices []int     `json:"indices"` // Indices are the indices of the non-zero components.
+	Values  []float64 `json:"values"`  // Values are the values of the non-zero components.
+}
+
+// SparseUtterance represents a utterance with a sparse embedding in the
+// semantic router.
+type SparseUtterance struct {
+	// Utterance is the utterance.
+	Utterance string `json:"utterance"`
+	// Embedding is the sparse embedding of the utterance.
+	Embedding SparseEmbedding `json:"embedding"`
+}
+
+// NewSparseEmbedding creates a sparse embedding from the non-zero components
+// of the given dense embedding.
+func NewSparseEmbedding(dense []float64) SparseEmbedding {
+	var sparse SparseEmbedding
+	for i, v := range dense {
+		if v != 0 {
+			sparse.Indices = append(sparse.Indices, i)
+			sparse.Values = append(sparse.Values, v)
+		}
+	}
+	return sparse
+}
+
+// Sorted returns a copy of the sparse embedding with its index/value pairs
+// sorted by index.
+func (s SparseEmbedding) Sorted() SparseEmbedding {
+	pairs := make([]int, len(s.Indices))
+	for i := range pairs {
+		pairs[i] = i
+	}
+	sort.SliceStable(pairs, func(i, j int) bool {
+		return s.Indices[pairs[i]] < s.Indices[pairs[j]]
+	})
+	sorted := SparseEmbedding{
+		Indices: make([]int, len(s.Indices)),
+		Values:  make([]float64, len(s.Indices)),
+	}
+	for i, p := range pairs {
+		sorted.Indices[i] = s.Indices[p]
+		sorted.Values[i] = s.Values[p]
+	}
+	return sorted
+}
@@ -21,6 +21,15 @@ type Utterance struct {
 	EmbeddingBytes []byte `bun:"embedding" json:"embedding"`
 	// Embed is the Embed of the utterance.
 	Embed Embedding
+	// Tags are the tags of the utterance, such as the channels it applies
+	// to.
+	Tags []string `bun:"tags" json:"tags"`
+	// Variants are alternative forms of the utterance, such as its
+	// expanded form or known paraphrases, encoded alongside it.
+	Variants []string `bun:"variants" json:"variants,omitempty"`
+	// Embeds are embeddings of other alternative forms of the utterance,
+	// computed ahead of time and stored alongside its own.
+	Embeds [][]float64 `bun:"embeds" json:"embeds,omitempty"`
 }
 
 // UtterancePrime represents a utterance in the semantic router.
@@ -0,0 +1,36 @@
+package semanticrouter
+
+import "gonum.org/v1/gonum/mat"
+
+// dot returns the dot product of the given vectors, with the SIMD kernel of
+// the platform, if any, when both vectors are contiguous, see dotUnitary.
+// Other vectors fall back to mat.Dot.
+func dot(xq, index *mat.VecDense) float64 {
+	n := xq.Len()
+	q, x := xq.RawVector(), index.RawVector()
+	if q.Inc != 1 || x.Inc != 1 || index.Len() != n {
+		return mat.Dot(xq, index)
+	}
+	return dotUnitary(q.Data[:n], x.Data[:n])
+}
+
+// dotGeneric returns the dot product of a and b, which have the same
+// length, in pure Go.
+//
+// The products are summed into four accumulators so that the additions of
+// consecutive elements do not wait for each other.
+func dotGeneric(a, b []float64) float64 {
+	b = b[:len(a)]
+	var s0, s1, s2, s3 float64
+	i := 0
+	for ; i+4 <= len(a); i += 4 {
+		s0 += a[i] * b[i]
+		s1 += a[i+1] * b[i+1]
+		s2 += a[i+2] * b[i+2]
+		s3 += a[i+3] * b[i+3]
+	}
+	for ; i < len(a); i++ {
+		s0 += a[i] * b[i]
+	}
+	return (s0 + s1) + (s2 + s3)
+}
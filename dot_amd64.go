@@ -0,0 +1,34 @@
+//go:build !purego
+
+package semanticrouter
+
+import (
+	"golang.org/x/sys/cpu"
+	"gonum.org/v1/gonum/floats"
+)
+
+// hasAVX2 is whether the CPU supports the AVX2 and FMA instructions used by
+// dotAVX2.
+var hasAVX2 = cpu.X86.HasAVX2 && cpu.X86.HasFMA
+
+// dotAVX2 returns the dot product of a and b, whose length must be the same
+// multiple of 16, summing the products into four AVX2 accumulators with
+// fused multiply-adds. It is implemented in dot_amd64.s.
+//
+//go:noescape
+func dotAVX2(a, b []float64) float64
+
+// dotUnitary returns the dot product of a and b, which have the same length,
+// with dotAVX2 on CPUs supporting it. Other CPUs, and vectors too short for
+// dotAVX2, use the SSE2 kernel of floats.Dot.
+func dotUnitary(a, b []float64) float64 {
+	if !hasAVX2 || len(a) < 16 {
+		return floats.Dot(a, b)
+	}
+	n := len(a) &^ 15
+	sum := dotAVX2(a[:n], b[:n])
+	for i := n; i < len(a); i++ {
+		sum += a[i] * b[i]
+	}
+	return sum
+}
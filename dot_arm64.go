@@ -0,0 +1,24 @@
+//go:build !purego
+
+package semanticrouter
+
+// dotNEON returns the dot product of a and b, whose length must be the same
+// multiple of 8, summing the products into four NEON accumulators with fused
+// multiply-adds. It is implemented in dot_arm64.s.
+//
+//go:noescape
+func dotNEON(a, b []float64) float64
+
+// dotUnitary returns the dot product of a and b, which have the same length,
+// with dotNEON, which every arm64 CPU supports.
+func dotUnitary(a, b []float64) float64 {
+	if len(a) < 8 {
+		return dotGeneric(a, b)
+	}
+	n := len(a) &^ 7
+	sum := dotNEON(a[:n], b[:n])
+	for i := n; i < len(a); i++ {
+		sum += a[i] * b[i]
+	}
+	return sum
+}
@@ -0,0 +1,46 @@
+//go:build !purego
+
+#include "textflag.h"
+
+// func dotNEON(a, b []float64) float64
+TEXT ·dotNEON(SB), NOSPLIT, $0-56
+	MOVD a_base+0(FP), R0
+	MOVD a_len+8(FP), R2
+	MOVD b_base+24(FP), R1
+	VEOR V0.B16, V0.B16, V0.B16
+	VEOR V1.B16, V1.B16, V1.B16
+	VEOR V2.B16, V2.B16, V2.B16
+	VEOR V3.B16, V3.B16, V3.B16
+	LSR  $3, R2
+	CBZ  R2, reduce
+
+loop:
+	VLD1.P 64(R0), [V4.D2, V5.D2, V6.D2, V7.D2]
+	VLD1.P 64(R1), [V16.D2, V17.D2, V18.D2, V19.D2]
+	VFMLA  V4.D2, V16.D2, V0.D2
+	VFMLA  V5.D2, V17.D2, V1.D2
+	VFMLA  V6.D2, V18.D2, V2.D2
+	VFMLA  V7.D2, V19.D2, V3.D2
+	SUB    $1, R2
+	CBNZ   R2, loop
+
+reduce:
+	// Add the high lane of each accumulator to its low lane, F0 to F3, then
+	// the accumulators together.
+	VMOV  V0.D[1], R3
+	FMOVD R3, F4
+	FADDD F4, F0
+	VMOV  V1.D[1], R3
+	FMOVD R3, F5
+	FADDD F5, F1
+	VMOV  V2.D[1], R3
+	FMOVD R3, F6
+	FADDD F6, F2
+	VMOV  V3.D[1], R3
+	FMOVD R3, F7
+	FADDD F7, F3
+	FADDD F1, F0
+	FADDD F3, F2
+	FADDD F2, F0
+	FMOVD F0, ret+48(FP)
+	RET
@@ -0,0 +1,11 @@
+//go:build (!amd64 && !arm64) || purego
+
+package semanticrouter
+
+// dotUnitary returns the dot product of a and b, which have the same length.
+//
+// Platforms without a SIMD kernel, and builds with the purego tag, use
+// dotGeneric.
+func dotUnitary(a, b []float64) float64 {
+	return dotGeneric(a, b)
+}
@@ -0,0 +1,81 @@
+package semanticrouter
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// randomVec returns a vector of the given dimension with random components
+// in [-1, 1).
+func randomVec(rnd *rand.Rand, n int) *mat.VecDense {
+	data := make([]float64, n)
+	for i := range data {
+		data[i] = 2*rnd.Float64() - 1
+	}
+	return mat.NewVecDense(n, data)
+}
+
+// TestDot tests that the dot product of the platform matches mat.Dot and
+// the pure Go fallback within float tolerance, for every remainder of the
+// SIMD block sizes.
+func TestDot(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for _, n := range []int{1, 3, 4, 7, 8, 9, 15, 16, 17, 31, 32, 33, 63, 64, 65, 384, 1000, 1536} {
+		xq, index := randomVec(rnd, n), randomVec(rnd, n)
+		want := mat.Dot(xq, index)
+		tolerance := 1e-12 * float64(n+1)
+		if got := dot(xq, index); math.Abs(got-want) > tolerance {
+			t.Errorf("dot(n=%d) = %v; want %v", n, got, want)
+		}
+		if got := dotGeneric(xq.RawVector().Data, index.RawVector().Data); math.Abs(got-want) > tolerance {
+			t.Errorf("dotGeneric(n=%d) = %v; want %v", n, got, want)
+		}
+		if got := DotProduct(xq, index); math.Abs(got-want) > tolerance {
+			t.Errorf("DotProduct(n=%d) = %v; want %v", n, got, want)
+		}
+	}
+}
+
+// TestDotStrided tests that the dot product of vectors that are not
+// contiguous falls back to mat.Dot.
+func TestDotStrided(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	m := mat.NewDense(40, 2, nil)
+	for i := 0; i < 40; i++ {
+		m.Set(i, 0, rnd.Float64())
+		m.Set(i, 1, rnd.Float64())
+	}
+	xq, index := m.ColView(0).(*mat.VecDense), m.ColView(1).(*mat.VecDense)
+	if got, want := dot(xq, index), mat.Dot(xq, index); got != want {
+		t.Errorf("dot = %v; want %v", got, want)
+	}
+}
+
+// BenchmarkDot compares the dot product of the platform with mat.Dot and
+// the pure Go fallback on vectors of typical embedding dimensions.
+func BenchmarkDot(b *testing.B) {
+	rnd := rand.New(rand.NewSource(1))
+	for _, n := range []int{384, 1536, 3072} {
+		xq, index := randomVec(rnd, n), randomVec(rnd, n)
+		b.Run(fmt.Sprintf("gonum/%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				mat.Dot(xq, index)
+			}
+		})
+		b.Run(fmt.Sprintf("simd/%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				dot(xq, index)
+			}
+		})
+		b.Run(fmt.Sprintf("generic/%d", n), func(b *testing.B) {
+			q, x := xq.RawVector().Data, index.RawVector().Data
+			for i := 0; i < b.N; i++ {
+				dotGeneric(q, x)
+			}
+		})
+	}
+}
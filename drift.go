@@ -0,0 +1,60 @@
+package semanticrouter
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// DetectDrift re-encodes a random sample of sampleSize stored utterances and
+// returns the mean cosine distance between their fresh and stored
+// embeddings.
+//
+// A drift close to zero means the encoder still produces the stored
+// embeddings; a high drift, such as after a provider silently changed its
+// model, means the index should be rebuilt. Every utterance is sampled if
+// sampleSize is not positive or exceeds the number of utterances. Only dense
+// embeddings are supported; other kinds fail with an error wrapping
+// ErrNotSupported.
+func (r *Router) DetectDrift(ctx context.Context, sampleSize int) (driftScore float64, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.embeddings != denseEmbeddings {
+		return 0, fmt.Errorf("error detecting drift: only dense embeddings are supported: %w", ErrNotSupported)
+	}
+	var utterances []string
+	for _, route := range r.Routes {
+		for _, utter := range route.Utterances {
+			utterances = append(utterances, utter.Utterance)
+		}
+	}
+	if len(utterances) == 0 {
+		return 0, fmt.Errorf("error detecting drift: no utterances")
+	}
+	rand.Shuffle(len(utterances), func(i, j int) {
+		utterances[i], utterances[j] = utterances[j], utterances[i]
+	})
+	if sampleSize > 0 && sampleSize < len(utterances) {
+		utterances = utterances[:sampleSize]
+	}
+	for _, utterance := range utterances {
+		stored, err := r.get(ctx, utterance)
+		if err != nil {
+			return 0, ErrGetEmbedding{Message: "error getting embedding", Err: err}
+		}
+		fresh, err := r.encode(ctx, r.documentInput(utterance))
+		if err != nil {
+			return 0, ErrEncoding{Message: "error encoding utterance", Err: err}
+		}
+		if len(fresh) != len(stored) {
+			driftScore++
+			continue
+		}
+		storedVec := mat.NewVecDense(len(stored), stored)
+		freshVec := mat.NewVecDense(len(fresh), fresh)
+		driftScore += 1 - CosineFromNorms(storedVec, freshVec, Norm(storedVec), Norm(freshVec))
+	}
+	return driftScore / float64(len(utterances)), nil
+}
@@ -0,0 +1,30 @@
+package semanticrouter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/conneroisu/go-semantic-router/stores/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestDetectDrift tests that drift is zero for an unchanged encoder and
+// positive once the encoder returns altered embeddings.
+func TestDetectDrift(t *testing.T) {
+	ctx := context.Background()
+	encoder := newTestEncoder()
+	router, err := NewRouter(newTestRoutes(), encoder, memory.NewStore())
+	require.NoError(t, err)
+
+	drift, err := router.DetectDrift(ctx, 2)
+	require.NoError(t, err)
+	assert.InDelta(t, 0, drift, 1e-12)
+
+	for utterance, em := range encoder.embeddings {
+		encoder.embeddings[utterance] = []float64{em[2], em[0], em[1]}
+	}
+	drift, err = router.DetectDrift(ctx, 0)
+	require.NoError(t, err)
+	assert.Greater(t, drift, 0.5)
+}
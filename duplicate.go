@@ -0,0 +1,140 @@
+package semanticrouter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/conneroisu/go-semantic-router/domain"
+	"gonum.org/v1/gonum/mat"
+)
+
+// DuplicateMode is how utterances appearing more than once across the routes
+// of a router are handled when it is built, see WithOnDuplicate.
+//
+// Embeddings are stored keyed by utterance, so the occurrences of a
+// duplicate utterance share a single embedding in the store whatever the
+// mode.
+type DuplicateMode int
+
+const (
+	// DuplicateOverwrite encodes and stores every occurrence, each one
+	// overwriting the embedding stored by the previous one.
+	DuplicateOverwrite DuplicateMode = iota
+	// DuplicateSkip only encodes and stores the first occurrence.
+	DuplicateSkip
+	// DuplicateError fails the build with an ErrDuplicateUtterance for each
+	// duplicate utterance, before any utterance is encoded.
+	DuplicateError
+)
+
+// checkDuplicates returns an ErrDuplicateUtterance for each utterance
+// appearing more than once across the given routes, joined, if the router
+// uses DuplicateError.
+//
+// Utterances are compared by key, so utterances normalized to the same key
+// with WithKeyNormalization are duplicates, reported by their first
+// occurrence.
+func (r *Router) checkDuplicates(routes []Route) error {
+	if r.onDuplicate != DuplicateError {
+		return nil
+	}
+	var order []string
+	first := make(map[string]string)
+	occurrences := make(map[string][]string)
+	for _, route := range routes {
+		for _, utter := range route.Utterances {
+			key := r.key(utter.Utterance)
+			if _, ok := occurrences[key]; !ok {
+				order = append(order, key)
+				first[key] = utter.Utterance
+			}
+			occurrences[key] = append(occurrences[key], route.Name)
+		}
+	}
+	var errs []error
+	for _, key := range order {
+		if len(occurrences[key]) > 1 {
+			errs = append(errs, ErrDuplicateUtterance{
+				Utterance: first[key],
+				Routes:    occurrences[key],
+			})
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Deduplicate removes the near-duplicate utterances of each route of the
+// router, returning the number of removed utterances.
+//
+// Within each route, an utterance whose embedding has a cosine similarity
+// above the given threshold with an earlier utterance of the route is
+// removed, the earliest utterance standing for its near-duplicates.
+// Utterances are only compared within a route. Removed utterances are
+// deleted from the store as by UpdateRoute, so the store must implement
+// Deleter if any utterance is removed.
+//
+// Only dense embeddings can be deduplicated; other kinds of embeddings fail
+// with an error wrapping ErrNotSupported.
+func (r *Router) Deduplicate(
+	ctx context.Context,
+	threshold float64,
+) (removed int, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.embeddings != denseEmbeddings {
+		return 0, fmt.Errorf("error deduplicating utterances: only dense embeddings are supported: %w", ErrNotSupported)
+	}
+	for _, route := range r.Routes {
+		kept, err := r.nearDuplicates(ctx, route, threshold)
+		if err != nil {
+			return removed, err
+		}
+		if len(kept) == len(route.Utterances) {
+			continue
+		}
+		_, err = r.updateRoute(ctx, route.Name, kept)
+		if err != nil {
+			return removed, fmt.Errorf("error deduplicating route %q: %w", route.Name, err)
+		}
+		removed += len(route.Utterances) - len(kept)
+	}
+	return removed, nil
+}
+
+// nearDuplicates returns the utterances of the given route that are not
+// near-duplicates of an earlier utterance of the route, see Deduplicate.
+func (r *Router) nearDuplicates(
+	ctx context.Context,
+	route Route,
+	threshold float64,
+) ([]domain.Utterance, error) {
+	type keptVec struct {
+		vec  *mat.VecDense
+		norm float64
+	}
+	var kept []domain.Utterance
+	var vecs []keptVec
+	for _, utter := range route.Utterances {
+		em, err := r.get(ctx, utter.Utterance)
+		if err != nil {
+			return nil, ErrGetEmbedding{Message: "error getting embedding", Err: err}
+		}
+		vec := mat.NewVecDense(len(em), em)
+		norm := Norm(vec)
+		duplicate := false
+		for _, k := range vecs {
+			if k.vec.Len() == vec.Len() &&
+				k.norm > 0 && norm > 0 &&
+				CosineFromNorms(vec, k.vec, norm, k.norm) > threshold {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			kept = append(kept, utter)
+			vecs = append(vecs, keptVec{vec: vec, norm: norm})
+		}
+	}
+	return kept, nil
+}
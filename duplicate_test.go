@@ -0,0 +1,143 @@
+package semanticrouter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/conneroisu/go-semantic-router/domain"
+	"github.com/conneroisu/go-semantic-router/stores/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newDuplicateRoutes returns the test routes with "lovely weather today" also
+// in the politics route.
+func newDuplicateRoutes() []Route {
+	routes := newTestRoutes()
+	routes[1].Utterances = append(
+		routes[1].Utterances,
+		domain.Utterance{Utterance: "lovely weather today"},
+	)
+	return routes
+}
+
+// TestWithOnDuplicate tests each way of handling duplicate utterances.
+func TestWithOnDuplicate(t *testing.T) {
+	ctx := context.Background()
+	testCases := []struct {
+		name      string
+		mode      DuplicateMode
+		wantCalls int64
+	}{
+		{name: "overwrite", mode: DuplicateOverwrite, wantCalls: 5},
+		{name: "skip", mode: DuplicateSkip, wantCalls: 4},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			encoder := &countingEncoder{Encoder: newTestEncoder()}
+			router, err := NewRouter(
+				newDuplicateRoutes(),
+				encoder,
+				memory.NewStore(),
+				WithOnDuplicate(tc.mode),
+			)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantCalls, encoder.calls.Load())
+			route, _, err := router.Match(ctx, "is it raining outside?")
+			require.NoError(t, err)
+			assert.Equal(t, "chitchat", route)
+		})
+	}
+
+	t.Run("error", func(t *testing.T) {
+		encoder := &countingEncoder{Encoder: newTestEncoder()}
+		_, err := NewRouter(
+			newDuplicateRoutes(),
+			encoder,
+			memory.NewStore(),
+			WithOnDuplicate(DuplicateError),
+		)
+		var duplicate ErrDuplicateUtterance
+		require.ErrorAs(t, err, &duplicate)
+		assert.Equal(t, ErrDuplicateUtterance{
+			Utterance: "lovely weather today",
+			Routes:    []string{"chitchat", "politics"},
+		}, duplicate)
+		assert.EqualError(t, err, `utterance "lovely weather today" is duplicated in routes ["chitchat" "politics"]`)
+		assert.Zero(t, encoder.calls.Load())
+	})
+
+	t.Run("normalized", func(t *testing.T) {
+		routes := newTestRoutes()
+		routes[1].Utterances = append(
+			routes[1].Utterances,
+			domain.Utterance{Utterance: "Lovely Wéather Today"},
+		)
+		_, err := NewRouter(
+			routes,
+			newTestEncoder(),
+			memory.NewStore(),
+			WithOnDuplicate(DuplicateError),
+			WithKeyNormalization(true),
+		)
+		var duplicate ErrDuplicateUtterance
+		require.ErrorAs(t, err, &duplicate)
+		assert.Equal(t, ErrDuplicateUtterance{
+			Utterance: "lovely weather today",
+			Routes:    []string{"chitchat", "politics"},
+		}, duplicate)
+	})
+}
+
+// TestWithOnDuplicateSkipCentroids tests that skipped duplicates still count
+// in the centroids of their routes.
+func TestWithOnDuplicateSkipCentroids(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewStore()
+	_, err := NewRouter(
+		newDuplicateRoutes(),
+		newTestEncoder(),
+		store,
+		WithOnDuplicate(DuplicateSkip),
+		WithRouteCentroids(true),
+	)
+	require.NoError(t, err)
+	centroid, err := store.Get(ctx, CentroidKey("politics"))
+	require.NoError(t, err)
+	expected := []float64{1.0 / 3, 2.1 / 3, 0.1 / 3}
+	for i := range expected {
+		assert.InDelta(t, expected[i], centroid[i], 1e-12)
+	}
+}
+
+// TestDeduplicate tests that near-identical utterances of a route collapse
+// to the first of them, leaving other routes alone.
+func TestDeduplicate(t *testing.T) {
+	ctx := context.Background()
+	encoder := newTestEncoder()
+	encoder.embeddings["how is the weather today?"] = []float64{1.0, 0.1, 0.001}
+	store := memory.NewStore()
+	routes := newTestRoutes()
+	routes[0].Utterances = append(routes[0].Utterances, domain.Utterance{Utterance: "how is the weather today?"})
+	router, err := NewRouter(routes, encoder, store)
+	require.NoError(t, err)
+
+	removed, err := router.Deduplicate(ctx, 0.995)
+	require.NoError(t, err)
+	assert.Equal(t, 1, removed)
+	assert.Equal(t, []domain.Utterance{
+		{Utterance: "how's the weather today?"},
+		{Utterance: "lovely weather today"},
+	}, router.Routes[0].Utterances)
+	assert.Equal(t, newTestRoutes()[1].Utterances, router.Routes[1].Utterances)
+	_, err = store.Get(ctx, "how is the weather today?")
+	assert.Error(t, err)
+
+	route, _, err := router.Match(ctx, "is it raining outside?")
+	require.NoError(t, err)
+	assert.Equal(t, "chitchat", route)
+
+	removed, err = router.Deduplicate(ctx, 0.995)
+	require.NoError(t, err)
+	assert.Zero(t, removed)
+}
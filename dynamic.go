@@ -0,0 +1,405 @@
+package semanticrouter
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/conneroisu/go-semantic-router/domain"
+)
+
+// AddRoute encodes and stores the utterances of the given route and adds it
+// to the router.
+//
+// The route is validated along with the existing routes, see Route.Validate.
+// Its embeddings must have the dimension set with WithDimension, or else the
+// dimension of the existing embeddings, if any.
+//
+// AddRoute is safe for concurrent use with matching: matches started
+// while it runs wait for it to complete.
+func (r *Router) AddRoute(ctx context.Context, route Route) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	routes := append(slices.Clone(r.Routes), route)
+	err := validateRoutes(routes)
+	if err != nil {
+		return fmt.Errorf("error validating routes: %w", err)
+	}
+	for _, spec := range route.Similarities {
+		_, err = resolveSimilarity(spec)
+		if err != nil {
+			return fmt.Errorf("route %q: %w", route.Name, err)
+		}
+	}
+	dimension, err := r.expectedDimension(ctx)
+	if err != nil {
+		return err
+	}
+	err = r.storeRoute(ctx, route, dimension, nil)
+	if err != nil {
+		return err
+	}
+	r.Routes = routes
+	err = r.resolveRouteSimilarities()
+	if err != nil {
+		return err
+	}
+	return r.buildSpatialIndex(ctx)
+}
+
+// AddUtterances encodes and stores the given utterances and adds them to the
+// existing route with the given name.
+//
+// The embeddings must have the dimension set with WithDimension, or else the
+// dimension of the existing embeddings. If the router uses route centroids,
+// the centroid of the route is updated incrementally with the new
+// embeddings, without reading the existing ones.
+//
+// AddUtterances is safe for concurrent use with matching: matches started
+// while it runs wait for it to complete.
+func (r *Router) AddUtterances(
+	ctx context.Context,
+	name string,
+	utters ...domain.Utterance,
+) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	pos := slices.IndexFunc(r.Routes, func(route Route) bool {
+		return route.Name == name
+	})
+	if pos == -1 {
+		return fmt.Errorf("route not found: %q", name)
+	}
+	route := r.Routes[pos]
+	for _, utter := range utters {
+		if strings.TrimSpace(utter.Utterance) == "" {
+			return ErrEmptyUtterance{Route: name}
+		}
+	}
+	dimension, err := r.expectedDimension(ctx)
+	if err != nil {
+		return err
+	}
+	var centroid routeCentroid
+	if r.centroids {
+		centroid, err = r.centroid(ctx, route)
+		if err != nil {
+			return err
+		}
+	}
+	centroid, err = r.storeUtterances(ctx, route, centroid, utters, dimension, nil)
+	if err != nil {
+		return err
+	}
+	if r.centroids && len(utters) > 0 {
+		r.setCentroid(name, centroid)
+	}
+	routes := slices.Clone(r.Routes)
+	routes[pos].Utterances = append(slices.Clone(route.Utterances), utters...)
+	r.Routes = routes
+	return r.buildSpatialIndex(ctx)
+}
+
+// Deleter is a Store that can also delete the embeddings of utterances.
+//
+// UpdateRoute requires the router's store to implement Deleter when
+// utterances are removed from a route.
+type Deleter interface {
+	Store
+	Delete(ctx context.Context, utterance string) error
+}
+
+// UpdateRoute replaces the utterances of the existing route with the given
+// name, returning the number of utterances that were encoded.
+//
+// Utterances are identified by their text: only the utterances the route did
+// not have are encoded and stored, and the embeddings of the removed ones are
+// deleted from the store, unless another route still has them. Kept
+// utterances pick up the tags of their new version without being encoded
+// again, unless their variants or precomputed embeddings changed, see
+// domain.Utterance.Variants, in which case they are encoded again and their
+// alternative embeddings no longer used are deleted. If the router uses
+// route centroids, the centroid of the route is recomputed from the stored
+// embeddings.
+//
+// If embeddings must be deleted but the router's store does not implement
+// Deleter, UpdateRoute fails with an error wrapping ErrNotSupported before
+// anything is stored, and the route is not changed.
+//
+// UpdateRoute is safe for concurrent use with matching: matches started
+// while it runs wait for it to complete.
+func (r *Router) UpdateRoute(
+	ctx context.Context,
+	name string,
+	utterances []domain.Utterance,
+) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.updateRoute(ctx, name, utterances)
+}
+
+// updateRoute replaces the utterances of the existing route with the given
+// name, see UpdateRoute, without locking the router.
+func (r *Router) updateRoute(
+	ctx context.Context,
+	name string,
+	utterances []domain.Utterance,
+) (int, error) {
+	pos := slices.IndexFunc(r.Routes, func(route Route) bool {
+		return route.Name == name
+	})
+	if pos == -1 {
+		return 0, fmt.Errorf("route not found: %q", name)
+	}
+	routes := slices.Clone(r.Routes)
+	route := routes[pos]
+	routes[pos].Utterances = slices.Clone(utterances)
+	err := validateRoutes(routes)
+	if err != nil {
+		return 0, fmt.Errorf("error validating routes: %w", err)
+	}
+	old := make(map[string]domain.Utterance, len(route.Utterances))
+	for _, utter := range route.Utterances {
+		old[r.key(utter.Utterance)] = utter
+	}
+	current := make(map[string]bool)
+	for _, rt := range routes {
+		for _, utter := range rt.Utterances {
+			current[r.key(utter.Utterance)] = true
+		}
+	}
+	var added []domain.Utterance
+	var kept []string
+	for _, utter := range utterances {
+		prev, ok := old[r.key(utter.Utterance)]
+		if ok && sameVariants(prev, utter) {
+			kept = append(kept, utter.Utterance)
+			continue
+		}
+		added = append(added, utter)
+	}
+	var removed []domain.Utterance
+	for _, utter := range route.Utterances {
+		if current[r.key(utter.Utterance)] {
+			continue
+		}
+		// Utterances with the same key are deleted once.
+		current[r.key(utter.Utterance)] = true
+		removed = append(removed, utter)
+	}
+	var stale []domain.Utterance
+	for _, utter := range utterances {
+		prev, ok := old[r.key(utter.Utterance)]
+		if ok && variantCount(prev) > variantCount(utter) {
+			stale = append(stale, utter)
+		}
+	}
+	if _, ok := r.Storage.(Deleter); !ok && len(removed)+len(stale) > 0 {
+		return 0, fmt.Errorf("error updating route %q: store %T does not implement Deleter: %w", name, r.Storage, ErrNotSupported)
+	}
+	dimension, err := r.expectedDimension(ctx)
+	if err != nil {
+		return 0, err
+	}
+	var centroid routeCentroid
+	if r.centroids {
+		for _, utter := range kept {
+			em, err := r.get(ctx, utter)
+			if err != nil {
+				return 0, ErrGetEmbedding{Message: "error getting embedding", Err: err}
+			}
+			centroid = centroid.add(em)
+		}
+	}
+	centroid, err = r.storeUtterances(ctx, route, centroid, added, dimension, nil)
+	if err != nil {
+		return 0, err
+	}
+	if r.centroids && len(added) == 0 {
+		err = r.storeCentroid(ctx, name, centroid)
+		if err != nil {
+			return 0, err
+		}
+	}
+	for _, utter := range removed {
+		err = r.deleteUtterance(ctx, utter.Utterance)
+		if err == nil {
+			err = r.deleteVariants(ctx, utter)
+		}
+		if err != nil {
+			return len(added), fmt.Errorf(
+				"error deleting utterance: %s: %w",
+				utter.Utterance,
+				err,
+			)
+		}
+	}
+	for _, utter := range stale {
+		prev := old[r.key(utter.Utterance)]
+		err = r.deleteStaleVariants(ctx, prev, variantCount(utter))
+		if err != nil {
+			return len(added), err
+		}
+	}
+	r.Routes = routes
+	if r.centroids {
+		r.setCentroid(name, centroid)
+	}
+	return len(added), r.buildSpatialIndex(ctx)
+}
+
+// expectedDimension returns the dimension the embeddings of new utterances
+// must have: the one set with WithDimension, or else the dimension of the
+// existing embeddings, zero if there are none.
+func (r *Router) expectedDimension(ctx context.Context) (int, error) {
+	if r.fixedDimension > 0 || r.embeddings != denseEmbeddings {
+		return r.fixedDimension, nil
+	}
+	dimension, err := r.dimension(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("error getting dimension: %w", err)
+	}
+	return dimension, nil
+}
+
+// SetRoutes replaces every route of the router with the given routes,
+// returning the number of utterances that were encoded.
+//
+// The routes are checked as by NewRouterContext before anything is stored.
+// Utterances are identified by their text, as with UpdateRoute: only the
+// utterances no route had, or whose variants changed, are encoded and
+// stored, and if the router's store implements Deleter the embeddings of the
+// utterances no route has anymore are deleted. If the router uses route
+// centroids, the centroid of every route is recomputed from the stored
+// embeddings.
+//
+// SetRoutes is safe for concurrent use with matching: matches started while
+// it runs wait for it to complete, so that no match observes a mix of the
+// previous and the new routes. If it fails, the routes are left unchanged.
+func (r *Router) SetRoutes(ctx context.Context, routes []Route) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	err := validateRoutes(routes)
+	if err != nil {
+		return 0, fmt.Errorf("error validating routes: %w", err)
+	}
+	routeFuncs, err := r.checkRoutes(routes)
+	if err != nil {
+		return 0, err
+	}
+	dimension, err := r.expectedDimension(ctx)
+	if err != nil {
+		return 0, err
+	}
+	previous := make(map[string]domain.Utterance)
+	for _, route := range r.Routes {
+		for _, utter := range route.Utterances {
+			previous[r.key(utter.Utterance)] = utter
+		}
+	}
+	current := make(map[string]bool)
+	stored := make(map[string]bool)
+	centroids := make(map[string]routeCentroid)
+	var encoded int
+	for _, route := range routes {
+		var added []domain.Utterance
+		var centroid routeCentroid
+		for _, utter := range route.Utterances {
+			current[r.key(utter.Utterance)] = true
+			prev, ok := previous[r.key(utter.Utterance)]
+			if !ok || !sameVariants(prev, utter) {
+				added = append(added, utter)
+				continue
+			}
+			if r.centroids {
+				em, err := r.get(ctx, utter.Utterance)
+				if err != nil {
+					return 0, ErrGetEmbedding{Message: "error getting embedding", Err: err}
+				}
+				centroid = centroid.add(em)
+			}
+		}
+		centroid, err = r.storeUtterances(ctx, route, centroid, added, dimension, stored)
+		if err != nil {
+			return 0, err
+		}
+		if r.centroids && len(route.Utterances) > 0 {
+			if len(added) == 0 {
+				err = r.storeCentroid(ctx, route.Name, centroid)
+				if err != nil {
+					return 0, err
+				}
+			}
+			centroids[route.Name] = centroid
+		}
+		encoded += len(added)
+	}
+	if _, ok := r.Storage.(Deleter); ok {
+		for _, route := range r.Routes {
+			for _, utter := range route.Utterances {
+				if current[r.key(utter.Utterance)] {
+					continue
+				}
+				// Utterances with the same key are deleted once.
+				current[r.key(utter.Utterance)] = true
+				err = r.deleteUtterance(ctx, utter.Utterance)
+				if err == nil {
+					err = r.deleteVariants(ctx, utter)
+				}
+				if err != nil {
+					return encoded, fmt.Errorf("error deleting utterance: %s: %w", utter.Utterance, err)
+				}
+			}
+		}
+		for _, route := range routes {
+			for _, utter := range route.Utterances {
+				prev, ok := previous[r.key(utter.Utterance)]
+				if !ok || variantCount(prev) <= variantCount(utter) {
+					continue
+				}
+				err = r.deleteStaleVariants(ctx, prev, variantCount(utter))
+				if err != nil {
+					return encoded, err
+				}
+			}
+		}
+	}
+	r.Routes = routes
+	r.routeFuncs = routeFuncs
+	if r.centroids {
+		r.centroidsMu.Lock()
+		r.centroidState = centroids
+		r.centroidsMu.Unlock()
+	}
+	return encoded, r.buildSpatialIndex(ctx)
+}
+
+// checkRoutes runs the checks of NewRouterContext on the given routes with
+// the router's options, without changing the router, returning the
+// similarity functions the routes override.
+func (r *Router) checkRoutes(routes []Route) (map[string][]biFuncCoefficient, error) {
+	candidate := *r
+	candidate.Routes = routes
+	err := candidate.resolveRouteSimilarities()
+	if err != nil {
+		return nil, fmt.Errorf("error resolving route similarities: %w", err)
+	}
+	err = candidate.checkCoefficients()
+	if err != nil {
+		return nil, fmt.Errorf("error checking coefficients: %w", err)
+	}
+	err = candidate.checkEmbeddings()
+	if err != nil {
+		return nil, err
+	}
+	err = candidate.checkScoreDirection()
+	if err != nil {
+		return nil, err
+	}
+	err = candidate.checkDuplicates(routes)
+	if err != nil {
+		return nil, err
+	}
+	return candidate.routeFuncs, nil
+}
@@ -0,0 +1,260 @@
+package semanticrouter
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/conneroisu/go-semantic-router/domain"
+	"github.com/conneroisu/go-semantic-router/stores/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestAddRoute tests building a router incrementally from an empty state
+// with a fixed dimension.
+func TestAddRoute(t *testing.T) {
+	ctx := context.Background()
+	encoder := newTestEncoder()
+	encoder.embeddings["too short"] = []float64{1.0, 0.0}
+	router, err := NewRouter(nil, encoder, memory.NewStore(), WithDimension(3))
+	require.NoError(t, err)
+	_, _, err = router.Match(ctx, "is it raining outside?")
+	assert.Error(t, err)
+
+	routes := newTestRoutes()
+	require.NoError(t, router.AddRoute(ctx, routes[0]))
+	route, _, err := router.Match(ctx, "is it raining outside?")
+	require.NoError(t, err)
+	assert.Equal(t, "chitchat", route)
+
+	err = router.AddRoute(ctx, Route{
+		Name:       "short",
+		Utterances: []domain.Utterance{{Utterance: "too short"}},
+	})
+	var mismatch ErrDimensionMismatch
+	require.ErrorAs(t, err, &mismatch)
+	assert.Equal(t, 3, mismatch.Expected)
+
+	assert.ErrorContains(t, router.AddRoute(ctx, routes[0]), "duplicate route name")
+
+	require.NoError(t, router.AddRoute(ctx, routes[1]))
+	route, _, err = router.Match(ctx, "what about the election?")
+	require.NoError(t, err)
+	assert.Equal(t, "politics", route)
+	assert.Len(t, router.Routes, 2)
+}
+
+// TestAddRouteInferredDimension tests that added routes must match the
+// dimension of the existing embeddings without WithDimension.
+func TestAddRouteInferredDimension(t *testing.T) {
+	ctx := context.Background()
+	encoder := newTestEncoder()
+	encoder.embeddings["too short"] = []float64{1.0, 0.0}
+	router, err := NewRouter(newTestRoutes(), encoder, memory.NewStore())
+	require.NoError(t, err)
+
+	err = router.AddRoute(ctx, Route{
+		Name:       "short",
+		Utterances: []domain.Utterance{{Utterance: "too short"}},
+	})
+	assert.ErrorAs(t, err, &ErrDimensionMismatch{})
+
+	_, err = NewRouter(
+		newTestRoutes(),
+		encoder,
+		memory.NewStore(),
+		WithDimension(2),
+	)
+	assert.ErrorAs(t, err, &ErrDimensionMismatch{})
+}
+
+// TestUpdateRoute tests that updating a route only encodes its changed
+// utterances and deletes the stale ones.
+func TestUpdateRoute(t *testing.T) {
+	ctx := context.Background()
+	base := newTestEncoder()
+	base.embeddings["a sunny day"] = []float64{0.7, 0.0, 0.3}
+	encoder := &countingEncoder{Encoder: base}
+	store := memory.NewStore()
+	router, err := NewRouter(newTestRoutes(), encoder, store)
+	require.NoError(t, err)
+	encoder.calls.Store(0)
+
+	count, err := router.UpdateRoute(ctx, "chitchat", []domain.Utterance{
+		{Utterance: "how's the weather today?"},
+		{Utterance: "a sunny day"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+	assert.EqualValues(t, 1, encoder.calls.Load())
+	assert.Equal(t, []domain.Utterance{
+		{Utterance: "how's the weather today?"},
+		{Utterance: "a sunny day"},
+	}, router.Routes[0].Utterances)
+
+	em, err := store.Get(ctx, "a sunny day")
+	require.NoError(t, err)
+	assert.Equal(t, []float64{0.7, 0.0, 0.3}, em)
+	_, err = store.Get(ctx, "lovely weather today")
+	assert.Error(t, err)
+
+	_, err = router.UpdateRoute(ctx, "missing", nil)
+	assert.ErrorContains(t, err, "route not found")
+	_, err = router.UpdateRoute(ctx, "chitchat", nil)
+	assert.Error(t, err)
+}
+
+// TestUpdateRouteWithoutDeleter tests that removing utterances from a route
+// of a router whose store cannot delete fails before anything is stored.
+func TestUpdateRouteWithoutDeleter(t *testing.T) {
+	ctx := context.Background()
+	base := newTestEncoder()
+	base.embeddings["a sunny day"] = []float64{0.7, 0.0, 0.3}
+	encoder := &countingEncoder{Encoder: base}
+	store := memory.NewStore()
+	router, err := NewRouter(newTestRoutes(), encoder, &countingStore{store: store}, WithRouteCentroids(true))
+	require.NoError(t, err)
+	encoder.calls.Store(0)
+	centroid, err := store.Get(ctx, CentroidKey("chitchat"))
+	require.NoError(t, err)
+
+	_, err = router.UpdateRoute(ctx, "chitchat", []domain.Utterance{
+		{Utterance: "how's the weather today?"},
+		{Utterance: "a sunny day"},
+	})
+	assert.ErrorIs(t, err, ErrNotSupported)
+	assert.Zero(t, encoder.calls.Load())
+	assert.Equal(t, newTestRoutes(), router.Routes)
+	_, err = store.Get(ctx, "a sunny day")
+	assert.Error(t, err)
+	em, err := store.Get(ctx, CentroidKey("chitchat"))
+	require.NoError(t, err)
+	assert.Equal(t, centroid, em)
+}
+
+// TestAddRouteConcurrentMatch tests that routes can be added while other
+// goroutines are matching; run with -race.
+func TestAddRouteConcurrentMatch(t *testing.T) {
+	ctx := context.Background()
+	encoder := newTestEncoder()
+	const added = 20
+	for i := 0; i < added; i++ {
+		encoder.embeddings[fmt.Sprintf("utterance %d", i)] = []float64{0.0, 0.0, 1.0 + float64(i)}
+	}
+	router, err := NewRouter(newTestRoutes(), encoder, memory.NewStore())
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				route, _, err := router.Match(ctx, "is it raining outside?")
+				assert.NoError(t, err)
+				assert.Equal(t, "chitchat", route)
+				_, err = router.ScoreAll(ctx, "what about the election?")
+				assert.NoError(t, err)
+			}
+		}()
+	}
+	for i := 0; i < added; i++ {
+		err := router.AddRoute(ctx, Route{
+			Name:       fmt.Sprintf("route %d", i),
+			Utterances: []domain.Utterance{{Utterance: fmt.Sprintf("utterance %d", i)}},
+		})
+		require.NoError(t, err)
+	}
+	close(done)
+	wg.Wait()
+	assert.Len(t, router.Routes, len(newTestRoutes())+added)
+}
+
+// TestSetRoutes tests that replacing the routes of a router only encodes the
+// new utterances and deletes the embeddings of the removed ones.
+func TestSetRoutes(t *testing.T) {
+	ctx := context.Background()
+	encoder := &countingEncoder{Encoder: newTestEncoder()}
+	store := memory.NewStore()
+	router, err := NewRouter(newTestRoutes(), encoder, store)
+	require.NoError(t, err)
+	built := encoder.calls.Load()
+
+	routes := []Route{
+		{Name: "weather", Utterances: []domain.Utterance{
+			{Utterance: "how's the weather today?"},
+			{Utterance: "is it raining outside?"},
+		}},
+		newTestRoutes()[1],
+	}
+	encoded, err := router.SetRoutes(ctx, routes)
+	require.NoError(t, err)
+	assert.Equal(t, 1, encoded)
+	assert.Equal(t, built+1, encoder.calls.Load())
+	assert.Equal(t, routes, router.Routes)
+	_, err = store.Get(ctx, "lovely weather today")
+	assert.Error(t, err)
+	route, _, err := router.Match(ctx, "lovely weather today")
+	require.NoError(t, err)
+	assert.Equal(t, "weather", route)
+
+	_, err = router.SetRoutes(ctx, []Route{routes[0], routes[0]})
+	assert.Error(t, err)
+	assert.Equal(t, routes, router.Routes)
+}
+
+// TestSetRoutesChecks tests that replacing the routes of a router with routes
+// its options reject fails before anything is encoded, leaving the routes
+// unchanged.
+func TestSetRoutesChecks(t *testing.T) {
+	ctx := context.Background()
+	for name, tc := range map[string]struct {
+		opts   []Option
+		routes func() []Route
+	}{
+		"priors under lower-is-better": {
+			opts: []Option{WithScoreDirection(LowerIsBetter)},
+			routes: func() []Route {
+				routes := newTestRoutes()
+				routes[0].Prior = 0.1
+				return routes
+			},
+		},
+		"duplicate utterances": {
+			opts: []Option{WithOnDuplicate(DuplicateError)},
+			routes: func() []Route {
+				routes := newTestRoutes()
+				routes[1].Utterances = append(routes[1].Utterances, routes[0].Utterances[0])
+				return routes
+			},
+		},
+		"no positive coefficient": {
+			routes: func() []Route {
+				routes := newTestRoutes()
+				routes[0].Similarities = []SimilaritySpec{{Name: SimilarityDotProduct, Coefficient: -1}}
+				return routes
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			encoder := &countingEncoder{Encoder: newTestEncoder()}
+			router, err := NewRouter(newTestRoutes(), encoder, memory.NewStore(), tc.opts...)
+			require.NoError(t, err)
+			routes := tc.routes()
+			routes[0].Utterances = append(routes[0].Utterances, domain.Utterance{Utterance: "a brand new utterance"})
+			built := encoder.calls.Load()
+			_, err = router.SetRoutes(ctx, routes)
+			assert.Error(t, err)
+			assert.Equal(t, built, encoder.calls.Load())
+			assert.Equal(t, newTestRoutes(), router.Routes)
+		})
+	}
+}
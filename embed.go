@@ -0,0 +1,34 @@
+package semanticrouter
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Embed returns the embedding Match computes for the given utterance, so
+// that callers can reuse it without encoding the utterance again.
+//
+// The utterance is encoded as a query: it is preceded by the query prefix,
+// see WithQueryPrefix, and the embedding is truncated to the output
+// dimension, see WithOutputDimension. If the router has a query cache, the
+// embedding is taken from and added to the cache, so that matching the
+// utterance after embedding it, or the other way around, calls the encoder
+// once. The returned embedding is a copy that the caller may modify.
+//
+// Empty and whitespace-only utterances are rejected with an
+// ErrEmptyUtterance. Only dense embeddings are supported.
+func (r *Router) Embed(ctx context.Context, utterance string) ([]float64, error) {
+	if r.embeddings != denseEmbeddings {
+		return nil, fmt.Errorf("error embedding utterance: router uses %s embeddings: %w", r.embeddings, ErrNotSupported)
+	}
+	if strings.TrimSpace(utterance) == "" {
+		return nil, ErrEmptyUtterance{}
+	}
+	em, err := r.encodeCached(ctx, utterance)
+	if err != nil {
+		return nil, ErrEncoding{Message: "error encoding utterance", Err: err}
+	}
+	return slices.Clone(em), nil
+}
@@ -0,0 +1,51 @@
+package semanticrouter
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/conneroisu/go-semantic-router/stores/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestEmbed tests that Embed returns the query embedding of Match, with the
+// query prefix and the output dimension applied, and shares the query cache
+// with Match.
+func TestEmbed(t *testing.T) {
+	ctx := context.Background()
+	base := newTestEncoder()
+	base.embeddings["query: is it raining outside?"] = []float64{0.8, 0.0, 0.1}
+	encoder := &countingEncoder{Encoder: base}
+	router, err := NewRouter(
+		newTestRoutes(),
+		encoder,
+		memory.NewStore(),
+		WithQueryPrefix("query: "),
+		WithOutputDimension(2),
+		WithQueryCache(8),
+	)
+	require.NoError(t, err)
+	built := encoder.calls.Load()
+
+	em, err := router.Embed(ctx, "is it raining outside?")
+	require.NoError(t, err)
+	assert.Equal(t, []float64{0.8, 0.0}, em)
+	assert.Equal(t, built+1, encoder.calls.Load())
+
+	em[0] = 42
+	route, _, err := router.Match(ctx, "is it raining outside?")
+	require.NoError(t, err)
+	assert.Equal(t, "chitchat", route)
+	assert.Equal(t, built+1, encoder.calls.Load(), "Match reuses the embedding")
+
+	again, err := router.Embed(ctx, "is it raining outside?")
+	require.NoError(t, err)
+	assert.Equal(t, []float64{0.8, 0.0}, again)
+
+	_, err = router.Embed(ctx, "  ")
+	assert.True(t, errors.As(err, &ErrEmptyUtterance{}))
+	_, err = router.Embed(ctx, "unknown")
+	assert.True(t, errors.As(err, &ErrEncoding{}))
+}
@@ -0,0 +1,56 @@
+package semanticrouter
+
+import "fmt"
+
+// embeddingKind is the kind of embeddings a router encodes utterances into.
+type embeddingKind int
+
+const (
+	// denseEmbeddings are dense vectors produced by an Encoder.
+	denseEmbeddings embeddingKind = iota
+	// sparseEmbeddings are sparse vectors produced by a SparseEncoder.
+	sparseEmbeddings
+	// multiVectorEmbeddings are per-token vectors produced by a
+	// MultiVectorEncoder.
+	multiVectorEmbeddings
+)
+
+// String returns the name of the kind of embeddings, as recorded in
+// IndexInfo.
+func (k embeddingKind) String() string {
+	switch k {
+	case sparseEmbeddings:
+		return "sparse"
+	case multiVectorEmbeddings:
+		return "multi-vector"
+	default:
+		return "dense"
+	}
+}
+
+// checkEmbeddings checks that the router's encoder and store support the
+// configured kind of embeddings, and that the other options support it.
+func (r *Router) checkEmbeddings() error {
+	if r.centroids && r.embeddings != denseEmbeddings {
+		return fmt.Errorf("route centroids require dense embeddings")
+	}
+	switch r.embeddings {
+	case sparseEmbeddings:
+		_, isSparseEncoder := r.Encoder.(SparseEncoder)
+		_, isSparseStore := r.Storage.(SparseStore)
+		if !isSparseEncoder || !isSparseStore {
+			return fmt.Errorf(
+				"sparse embeddings require a SparseEncoder and a SparseStore",
+			)
+		}
+	case multiVectorEmbeddings:
+		_, isMultiEncoder := r.Encoder.(MultiVectorEncoder)
+		_, isMultiStore := r.Storage.(MultiVectorStore)
+		if !isMultiEncoder || !isMultiStore {
+			return fmt.Errorf(
+				"multi-vector embeddings require a MultiVectorEncoder and a MultiVectorStore",
+			)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+// Package encodertest provides a suite of tests checking that an encoder
+// implements the behavioral contract of semanticrouter.Encoder, so that every
+// encoder, including third-party ones, behaves the same under a router.
+//
+// The suite is run from the tests of an encoder package:
+//
+//	func TestSuite(t *testing.T) {
+//		encodertest.RunSuite(t, func() encodertest.Encoder {
+//			return mypkg.NewEncoder()
+//		}, encodertest.Options{Deterministic: true})
+//	}
+package encodertest
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Encoder is an encoder tested by the suite. It has the method set of
+// semanticrouter.Encoder, which the package does not import so that the
+// suite can be used by the tests of the semanticrouter package itself.
+type Encoder interface {
+	Encode(ctx context.Context, utterance string) ([]float64, error)
+}
+
+// DefaultInputs are the utterances encoded by the suite unless
+// Options.Inputs is set.
+var DefaultInputs = []string{
+	"how's the weather today?",
+	"who will win the vote?",
+	"hello world",
+}
+
+// Options describe the encoder tested by the suite.
+type Options struct {
+	Inputs              []string // Inputs are the utterances encoded by the suite, DefaultInputs if empty.
+	Dimension           int      // Dimension is the expected dimension of the embeddings, any stable dimension if zero.
+	Deterministic       bool     // Deterministic is whether the encoder returns the same embedding for the same utterance.
+	IgnoresCancellation bool     // IgnoresCancellation is whether the encoder may succeed with a canceled context, such as a local encoder.
+}
+
+// RunSuite runs the suite of encoder tests against encoders returned by
+// newEncoder, which is called once per test.
+func RunSuite(t *testing.T, newEncoder func() Encoder, opts Options) {
+	t.Helper()
+	if len(opts.Inputs) == 0 {
+		opts.Inputs = DefaultInputs
+	}
+	tests := []struct {
+		name string
+		test func(t *testing.T, e Encoder, opts Options)
+	}{
+		{"Dimension", testDimension},
+		{"Determinism", testDeterminism},
+		{"EmptyInput", testEmptyInput},
+		{"ContextCancellation", testContextCancellation},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.test(t, newEncoder(), opts)
+		})
+	}
+}
+
+// encodeInputs encodes every input of the options, checking that the
+// embeddings are non-empty, finite and of the same dimension.
+func encodeInputs(t *testing.T, e Encoder, opts Options) [][]float64 {
+	t.Helper()
+	embeddings := make([][]float64, len(opts.Inputs))
+	for i, input := range opts.Inputs {
+		embedding, err := e.Encode(context.Background(), input)
+		require.NoError(t, err, "encoding %q", input)
+		require.NotEmpty(t, embedding, "embedding of %q", input)
+		for j, v := range embedding {
+			require.False(t, math.IsNaN(v) || math.IsInf(v, 0), "component %d of the embedding of %q is %v", j, input, v)
+		}
+		if i > 0 {
+			require.Len(t, embedding, len(embeddings[0]), "dimension of the embedding of %q", input)
+		}
+		embeddings[i] = embedding
+	}
+	return embeddings
+}
+
+// testDimension tests that every embedding has the same dimension, the
+// expected one if set.
+func testDimension(t *testing.T, e Encoder, opts Options) {
+	embeddings := encodeInputs(t, e, opts)
+	if opts.Dimension > 0 {
+		assert.Len(t, embeddings[0], opts.Dimension)
+	}
+	again := encodeInputs(t, e, opts)
+	assert.Len(t, again[0], len(embeddings[0]))
+}
+
+// testDeterminism tests that a deterministic encoder returns the same
+// embedding every time it encodes an utterance.
+func testDeterminism(t *testing.T, e Encoder, opts Options) {
+	if !opts.Deterministic {
+		t.Skip("encoder is not deterministic")
+	}
+	embeddings := encodeInputs(t, e, opts)
+	again := encodeInputs(t, e, opts)
+	for i, input := range opts.Inputs {
+		assert.Equal(t, embeddings[i], again[i], "embeddings of %q", input)
+	}
+}
+
+// testEmptyInput tests that encoding an empty utterance either fails or
+// returns an embedding of the same dimension as the others.
+func testEmptyInput(t *testing.T, e Encoder, opts Options) {
+	embeddings := encodeInputs(t, e, opts)
+	embedding, err := e.Encode(context.Background(), "")
+	if err != nil {
+		assert.Empty(t, embedding)
+		return
+	}
+	assert.Len(t, embedding, len(embeddings[0]))
+}
+
+// testContextCancellation tests that encoding with a canceled context fails
+// with an error wrapping the context's error.
+func testContextCancellation(t *testing.T, e Encoder, opts Options) {
+	if opts.IgnoresCancellation {
+		t.Skip("encoder ignores cancellation")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	embedding, err := e.Encode(ctx, opts.Inputs[0])
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, context.Canceled), "error %v does not wrap context.Canceled", err)
+	assert.Empty(t, embedding)
+}
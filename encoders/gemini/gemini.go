@@ -0,0 +1,113 @@
+// Package gemini provides an encoder for the Gemini embedding models using
+// the unified Google Gen AI SDK, google.golang.org/genai.
+//
+// The client is created and configured by the caller, for either the Gemini
+// API or Vertex AI:
+//
+//	client, err := genai.NewClient(ctx, &genai.ClientConfig{
+//		APIKey:  os.Getenv("GEMINI_API_KEY"),
+//		Backend: genai.BackendGeminiAPI,
+//	})
+//	if err != nil {
+//		return err
+//	}
+//	encoder := gemini.NewEncoder(
+//		client,
+//		gemini.ModelGeminiEmbedding001,
+//		gemini.WithTaskType(gemini.TaskSemanticSimilarity),
+//	)
+package gemini
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"google.golang.org/genai"
+)
+
+const (
+	// ModelTextEmbedding004 is the text-embedding-004 model.
+	ModelTextEmbedding004 = "text-embedding-004"
+	// ModelGeminiEmbedding001 is the gemini-embedding-001 model.
+	ModelGeminiEmbedding001 = "gemini-embedding-001"
+)
+
+const (
+	// TaskRetrievalQuery optimizes embeddings for search queries.
+	TaskRetrievalQuery = "RETRIEVAL_QUERY"
+	// TaskRetrievalDocument optimizes embeddings for the documents searched.
+	TaskRetrievalDocument = "RETRIEVAL_DOCUMENT"
+	// TaskSemanticSimilarity optimizes embeddings for comparing texts with
+	// each other.
+	TaskSemanticSimilarity = "SEMANTIC_SIMILARITY"
+	// TaskClassification optimizes embeddings for classifying texts.
+	TaskClassification = "CLASSIFICATION"
+)
+
+// Encoder encodes utterances with a Gemini embedding model.
+type Encoder struct {
+	Client    *genai.Client // Client is the Gen AI client used to send requests.
+	Model     string        // Model is the name of the embedding model.
+	TaskType  string        // TaskType is the task the embeddings are optimized for, the model's default if empty.
+	Dimension int           // Dimension is the dimension the embeddings are truncated to, the model's default if zero.
+}
+
+// Option is a function that configures an Encoder.
+type Option func(*Encoder)
+
+// WithTaskType sets the task the embeddings are optimized for, such as
+// TaskRetrievalQuery or TaskRetrievalDocument.
+//
+// A router encodes its utterances and its queries with the same encoder,
+// so a symmetric task such as TaskSemanticSimilarity or TaskClassification
+// usually suits routing best.
+func WithTaskType(taskType string) Option {
+	return func(e *Encoder) {
+		e.TaskType = taskType
+	}
+}
+
+// WithOutputDimensionality sets the dimension the model truncates the
+// embeddings to. Only newer models, such as text-embedding-004 and
+// gemini-embedding-001, support it.
+func WithOutputDimensionality(dimension int) Option {
+	return func(e *Encoder) {
+		e.Dimension = dimension
+	}
+}
+
+// NewEncoder creates a new Encoder using the given model with the given
+// preconfigured client.
+func NewEncoder(client *genai.Client, model string, opts ...Option) *Encoder {
+	e := &Encoder{Client: client, Model: model}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// Encode encodes the given utterance with the embedding model.
+func (e *Encoder) Encode(
+	ctx context.Context,
+	utterance string,
+) ([]float64, error) {
+	config := &genai.EmbedContentConfig{TaskType: e.TaskType}
+	if e.Dimension > 0 {
+		dimension := int32(e.Dimension)
+		config.OutputDimensionality = &dimension
+	}
+	res, err := e.Client.Models.EmbedContent(ctx, e.Model, genai.Text(utterance), config)
+	if err != nil {
+		return nil, fmt.Errorf("error embedding content: %w", err)
+	}
+	if len(res.Embeddings) == 0 || res.Embeddings[0] == nil {
+		return nil, errors.New("no embedding in response")
+	}
+	values := res.Embeddings[0].Values
+	embedding := make([]float64, len(values))
+	for i, v := range values {
+		embedding[i] = float64(v)
+	}
+	return embedding, nil
+}
@@ -0,0 +1,109 @@
+package gemini
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genai"
+)
+
+// newTestClient returns a Gemini API client sending its requests to the
+// given server.
+func newTestClient(t *testing.T, server *httptest.Server) *genai.Client {
+	t.Helper()
+	client, err := genai.NewClient(context.Background(), &genai.ClientConfig{
+		APIKey:      "test-key",
+		Backend:     genai.BackendGeminiAPI,
+		HTTPClient:  server.Client(),
+		HTTPOptions: genai.HTTPOptions{BaseURL: server.URL + "/"},
+	})
+	require.NoError(t, err)
+	return client
+}
+
+func TestEncoder(t *testing.T) {
+	var body struct {
+		Requests []struct {
+			Model                string `json:"model"`
+			TaskType             string `json:"taskType"`
+			OutputDimensionality int    `json:"outputDimensionality"`
+			Content              struct {
+				Parts []struct {
+					Text string `json:"text"`
+				} `json:"parts"`
+			} `json:"content"`
+		} `json:"requests"`
+	}
+	var path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"embeddings": [{"values": [0.5, -0.25, 1]}]}`))
+	}))
+	defer server.Close()
+
+	encoder := NewEncoder(
+		newTestClient(t, server),
+		ModelGeminiEmbedding001,
+		WithTaskType(TaskRetrievalQuery),
+		WithOutputDimensionality(3),
+	)
+	embedding, err := encoder.Encode(context.Background(), "hello world")
+	require.NoError(t, err)
+	assert.Equal(t, []float64{0.5, -0.25, 1}, embedding)
+
+	assert.True(t, strings.HasSuffix(path, "models/gemini-embedding-001:batchEmbedContents"), path)
+	require.Len(t, body.Requests, 1)
+	assert.Equal(t, "models/gemini-embedding-001", body.Requests[0].Model)
+	assert.Equal(t, TaskRetrievalQuery, body.Requests[0].TaskType)
+	assert.Equal(t, 3, body.Requests[0].OutputDimensionality)
+	require.Len(t, body.Requests[0].Content.Parts, 1)
+	assert.Equal(t, "hello world", body.Requests[0].Content.Parts[0].Text)
+}
+
+func TestEncoderError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error": {"code": 400, "message": "invalid model", "status": "INVALID_ARGUMENT"}}`))
+	}))
+	defer server.Close()
+
+	encoder := NewEncoder(newTestClient(t, server), "unknown")
+	_, err := encoder.Encode(context.Background(), "hello world")
+	assert.ErrorContains(t, err, "invalid model")
+
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"embeddings": []}`))
+	})
+	_, err = encoder.Encode(context.Background(), "hello world")
+	assert.ErrorContains(t, err, "no embedding")
+}
+
+// TestEncoderIntegration encodes an utterance with the Gemini API, if the
+// GEMINI_API_KEY environment variable is set.
+func TestEncoderIntegration(t *testing.T) {
+	apiKey := os.Getenv("GEMINI_API_KEY")
+	if apiKey == "" {
+		t.Skip("GEMINI_API_KEY is not set")
+	}
+	ctx := context.Background()
+	client, err := genai.NewClient(ctx, &genai.ClientConfig{
+		APIKey:  apiKey,
+		Backend: genai.BackendGeminiAPI,
+	})
+	require.NoError(t, err)
+	encoder := NewEncoder(client, ModelTextEmbedding004, WithOutputDimensionality(256))
+	embedding, err := encoder.Encode(ctx, "hello world")
+	require.NoError(t, err)
+	assert.Len(t, embedding, 256)
+}
@@ -8,24 +8,24 @@ import (
 
 // GoogleEncoder encodes a query string into a Google search URL.
 type GoogleEncoder struct {
-	Ctx context.Context
-
 	client genai.Client
 	name   string
 }
 
 // NewGoogleEncoder creates a new GoogleEncoder.
 func NewGoogleEncoder(
-	ctx context.Context,
 	client genai.Client,
 ) *GoogleEncoder {
 	return &GoogleEncoder{client: client}
 }
 
 // Encode encodes a query string into a Google search URL.
-func (e *GoogleEncoder) Encode(query string) ([]float64, error) {
+func (e *GoogleEncoder) Encode(
+	ctx context.Context,
+	query string,
+) ([]float64, error) {
 	model := e.client.EmbeddingModel(e.name)
-	embedding, err := model.EmbedContent(e.Ctx)
+	embedding, err := model.EmbedContent(ctx, genai.Text(query))
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,210 @@
+// Package nomic provides an encoder for the Nomic embedding models using the
+// Nomic Atlas embedding API.
+//
+//	encoder := nomic.NewEncoder(
+//		os.Getenv("NOMIC_API_KEY"),
+//		nomic.ModelNomicEmbedTextV15,
+//		nomic.WithTaskType(nomic.TaskClassification),
+//		nomic.WithDimensionality(256),
+//	)
+package nomic
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// DefaultBaseURL is the base URL of the Nomic Atlas API.
+const DefaultBaseURL = "https://api-atlas.nomic.ai/v1"
+
+const (
+	// ModelNomicEmbedTextV1 is the nomic-embed-text-v1 model.
+	ModelNomicEmbedTextV1 = "nomic-embed-text-v1"
+	// ModelNomicEmbedTextV15 is the nomic-embed-text-v1.5 model, which
+	// supports Matryoshka dimensionality reduction.
+	ModelNomicEmbedTextV15 = "nomic-embed-text-v1.5"
+)
+
+const (
+	// TaskSearchQuery optimizes embeddings for search queries.
+	TaskSearchQuery = "search_query"
+	// TaskSearchDocument optimizes embeddings for the documents searched.
+	TaskSearchDocument = "search_document"
+	// TaskClustering optimizes embeddings for grouping texts.
+	TaskClustering = "clustering"
+	// TaskClassification optimizes embeddings for classifying texts.
+	TaskClassification = "classification"
+)
+
+// Encoder encodes utterances with a Nomic embedding model.
+type Encoder struct {
+	BaseURL        string       // BaseURL is the base URL of the API, DefaultBaseURL by default.
+	APIKey         string       // APIKey is sent as a bearer token.
+	Model          string       // Model is the name of the embedding model.
+	TaskType       string       // TaskType is the task the embeddings are optimized for, the API's default if empty.
+	Dimensionality int          // Dimensionality is the dimension the embeddings are truncated to, the model's default if zero.
+	Client         *http.Client // Client is the HTTP client used to send requests.
+}
+
+// Option is a function that configures an Encoder.
+type Option func(*Encoder)
+
+// WithTaskType sets the task the embeddings are optimized for, such as
+// TaskSearchQuery or TaskSearchDocument.
+//
+// A router encodes its utterances and its queries with the same encoder,
+// so a symmetric task such as TaskClassification or TaskClustering usually
+// suits routing best.
+func WithTaskType(taskType string) Option {
+	return func(e *Encoder) {
+		e.TaskType = taskType
+	}
+}
+
+// WithDimensionality sets the dimension the model truncates the embeddings
+// to. Only Matryoshka models, such as nomic-embed-text-v1.5, support it.
+func WithDimensionality(dimensionality int) Option {
+	return func(e *Encoder) {
+		e.Dimensionality = dimensionality
+	}
+}
+
+// WithBaseURL sets the base URL of the API.
+func WithBaseURL(baseURL string) Option {
+	return func(e *Encoder) {
+		e.BaseURL = baseURL
+	}
+}
+
+// WithHTTPClient sets the HTTP client used to send requests.
+func WithHTTPClient(client *http.Client) Option {
+	return func(e *Encoder) {
+		e.Client = client
+	}
+}
+
+// NewEncoder creates a new Encoder using the given model with the given API
+// key.
+func NewEncoder(apiKey, model string, opts ...Option) *Encoder {
+	e := &Encoder{
+		BaseURL: DefaultBaseURL,
+		APIKey:  apiKey,
+		Model:   model,
+		Client:  http.DefaultClient,
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// APIError is the error returned when the API responds with a status other
+// than 200 OK, such as 401 for an invalid API key or 429 when rate limited.
+type APIError struct {
+	StatusCode int    // StatusCode is the HTTP status of the response.
+	Message    string // Message is the error detail of the response, if any.
+}
+
+// Error returns the error message of the APIError.
+func (e APIError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("status %d", e.StatusCode)
+	}
+	return fmt.Sprintf("status %d: %s", e.StatusCode, e.Message)
+}
+
+// embeddingRequest is the body of an embedding request.
+type embeddingRequest struct {
+	Model          string   `json:"model"`
+	Texts          []string `json:"texts"`
+	TaskType       string   `json:"task_type,omitempty"`
+	Dimensionality int      `json:"dimensionality,omitempty"`
+}
+
+// embeddingResponse is the body of an embedding response.
+type embeddingResponse struct {
+	Embeddings [][]float64     `json:"embeddings"`
+	Detail     json.RawMessage `json:"detail"`
+}
+
+// detail returns the error detail of the response, which is either a
+// string or a list of validation errors.
+func (r embeddingResponse) detail() string {
+	var detail string
+	if json.Unmarshal(r.Detail, &detail) == nil {
+		return detail
+	}
+	return string(r.Detail)
+}
+
+// Encode encodes the given utterance with the embedding model.
+func (e *Encoder) Encode(
+	ctx context.Context,
+	utterance string,
+) ([]float64, error) {
+	embedding, err := e.encode(ctx, utterance)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding utterance %q: %w", utterance, err)
+	}
+	return embedding, nil
+}
+
+// encode sends the embedding request of the given utterance.
+func (e *Encoder) encode(
+	ctx context.Context,
+	utterance string,
+) ([]float64, error) {
+	body, err := json.Marshal(embeddingRequest{
+		Model:          e.Model,
+		Texts:          []string{utterance},
+		TaskType:       e.TaskType,
+		Dimensionality: e.Dimensionality,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling request: %w", err)
+	}
+	baseURL := e.BaseURL
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		strings.TrimSuffix(baseURL, "/")+"/embedding/text",
+		bytes.NewReader(body),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+e.APIKey)
+	client := e.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error sending request: %w", err)
+	}
+	defer resp.Body.Close()
+	var res embeddingResponse
+	err = json.NewDecoder(resp.Body).Decode(&res)
+	if resp.StatusCode != http.StatusOK {
+		apiErr := APIError{StatusCode: resp.StatusCode}
+		if err == nil && len(res.Detail) > 0 {
+			apiErr.Message = res.detail()
+		}
+		return nil, fmt.Errorf("error creating embedding: %w", apiErr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error decoding response: %w", err)
+	}
+	if len(res.Embeddings) == 0 {
+		return nil, fmt.Errorf("error creating embedding: empty response")
+	}
+	return res.Embeddings[0], nil
+}
@@ -0,0 +1,128 @@
+package nomic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestEncoder tests the encoder against a mock embedding server.
+func TestEncoder(t *testing.T) {
+	var req embeddingRequest
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPost, r.Method)
+			assert.Equal(t, "/v1/embedding/text", r.URL.Path)
+			assert.Equal(t, "Bearer key", r.Header.Get("Authorization"))
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			_, _ = w.Write([]byte(`{"embeddings":[[0.5,-0.25,1]],"usage":{"total_tokens":2}}`))
+		},
+	))
+	defer server.Close()
+
+	encoder := NewEncoder(
+		"key",
+		ModelNomicEmbedTextV15,
+		WithBaseURL(server.URL+"/v1/"),
+		WithHTTPClient(server.Client()),
+		WithTaskType(TaskClassification),
+		WithDimensionality(3),
+	)
+	em, err := encoder.Encode(context.Background(), "hello world")
+	require.NoError(t, err)
+	assert.Equal(t, []float64{0.5, -0.25, 1}, em)
+	assert.Equal(t, embeddingRequest{
+		Model:          ModelNomicEmbedTextV15,
+		Texts:          []string{"hello world"},
+		TaskType:       TaskClassification,
+		Dimensionality: 3,
+	}, req)
+}
+
+// TestEncoderErrors tests that the errors of the API are returned as
+// APIErrors wrapped with the utterance.
+func TestEncoderErrors(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		status  int
+		body    string
+		message string
+	}{
+		{
+			name:    "rate limit",
+			status:  http.StatusTooManyRequests,
+			body:    `{"detail":"Rate limit exceeded"}`,
+			message: "Rate limit exceeded",
+		},
+		{
+			name:    "auth failure",
+			status:  http.StatusUnauthorized,
+			body:    `{"detail":"Invalid API key"}`,
+			message: "Invalid API key",
+		},
+		{
+			name:   "no body",
+			status: http.StatusBadGateway,
+			body:   `<html>Bad Gateway</html>`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(
+				func(w http.ResponseWriter, _ *http.Request) {
+					w.WriteHeader(tt.status)
+					_, _ = w.Write([]byte(tt.body))
+				},
+			))
+			defer server.Close()
+
+			encoder := NewEncoder(
+				"key",
+				ModelNomicEmbedTextV15,
+				WithBaseURL(server.URL),
+				WithHTTPClient(server.Client()),
+			)
+			_, err := encoder.Encode(context.Background(), "hello world")
+			require.Error(t, err)
+			assert.ErrorContains(t, err, `utterance "hello world"`)
+			var apiErr APIError
+			require.True(t, errors.As(err, &apiErr))
+			assert.Equal(t, tt.status, apiErr.StatusCode)
+			assert.Equal(t, tt.message, apiErr.Message)
+		})
+	}
+}
+
+// TestEncoderEmptyResponse tests that a response without embeddings is an
+// error.
+func TestEncoderEmptyResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte(`{"embeddings":[]}`))
+		},
+	))
+	defer server.Close()
+
+	encoder := NewEncoder("key", ModelNomicEmbedTextV15, WithBaseURL(server.URL))
+	_, err := encoder.Encode(context.Background(), "hello world")
+	assert.ErrorContains(t, err, "empty response")
+}
+
+// TestEncoderIntegration encodes an utterance with the Nomic API, if the
+// NOMIC_API_KEY environment variable is set.
+func TestEncoderIntegration(t *testing.T) {
+	apiKey := os.Getenv("NOMIC_API_KEY")
+	if apiKey == "" {
+		t.Skip("NOMIC_API_KEY is not set")
+	}
+	encoder := NewEncoder(apiKey, ModelNomicEmbedTextV15, WithDimensionality(256))
+	em, err := encoder.Encode(context.Background(), "hello world")
+	require.NoError(t, err)
+	assert.Len(t, em, 256)
+}
@@ -5,7 +5,7 @@ package ollama
 
 import (
 	"context"
-	"log"
+	"fmt"
 
 	"github.com/ollama/ollama/api"
 )
@@ -22,15 +22,17 @@ func NewEncoder(client *api.Client) *Encoder {
 }
 
 // Encode encodes a query string into a Ollama embedding.
-func (e *Encoder) Encode(query string) (result []float64, err error) {
+func (e *Encoder) Encode(
+	ctx context.Context,
+	query string,
+) (result []float64, err error) {
 	req := &api.EmbeddingRequest{
 		Model:  e.Model,
 		Prompt: query,
 	}
-	ctx := context.Background()
 	em, err := e.Client.Embeddings(ctx, req)
 	if err != nil {
-		log.Fatal(err)
+		return nil, fmt.Errorf("error creating embedding: %w", err)
 	}
 	result = em.Embedding
 	return result, nil
@@ -2,31 +2,33 @@ package encoders
 
 import (
 	"context"
-	"log"
+	"fmt"
 
 	openai "github.com/sashabaranov/go-openai"
 )
 
 // OpenAIEncoder encodes a query string into an OpenAI embedding.
 type OpenAIEncoder struct {
-	Ctx    context.Context
 	APIKey string
 	Model  openai.EmbeddingModel
 }
 
 // Encode encodes the given utterance using the OpenAI API.
-func (o OpenAIEncoder) Encode(utterance string) ([]float64, error) {
+func (o OpenAIEncoder) Encode(
+	ctx context.Context,
+	utterance string,
+) ([]float64, error) {
 	client := openai.NewClient(o.APIKey)
 	queryReq := openai.EmbeddingRequest{
 		Input: utterance,
 		Model: openai.AdaEmbeddingV2,
 	}
 	queryResponse, err := client.CreateEmbeddings(
-		context.Background(),
+		ctx,
 		queryReq,
 	)
 	if err != nil {
-		log.Fatal("Error creating query embedding:", err)
+		return nil, fmt.Errorf("error creating query embedding: %w", err)
 	}
 	var floats []float32
 	for _, f := range queryResponse.Data[0].Embedding {
@@ -0,0 +1,138 @@
+// Package openaicompat provides an encoder for any embedding API that is
+// compatible with the OpenAI embeddings endpoint.
+//
+// Many providers (DeepSeek, Together, Groq, vLLM, ...) expose such an
+// endpoint, so a single encoder covers all of them.
+package openaicompat
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Encoder encodes utterances using an OpenAI-compatible embeddings endpoint.
+type Encoder struct {
+	BaseURL string            // BaseURL is the base URL of the API, e.g. https://api.deepseek.com/v1.
+	APIKey  string            // APIKey is sent as a bearer token if non-empty.
+	Model   string            // Model is the name of the embedding model.
+	Headers map[string]string // Headers are additional headers sent with each request.
+	Client  *http.Client      // Client is the HTTP client used to send requests.
+}
+
+// Option is a function that configures an Encoder.
+type Option func(*Encoder)
+
+// WithHeader sets an additional header sent with each request.
+func WithHeader(key, value string) Option {
+	return func(e *Encoder) {
+		e.Headers[key] = value
+	}
+}
+
+// WithHTTPClient sets the HTTP client used to send requests.
+func WithHTTPClient(client *http.Client) Option {
+	return func(e *Encoder) {
+		e.Client = client
+	}
+}
+
+// NewEncoder creates a new Encoder targeting the embeddings endpoint found
+// under the given base URL.
+func NewEncoder(baseURL, apiKey, model string, opts ...Option) *Encoder {
+	e := &Encoder{
+		BaseURL: baseURL,
+		APIKey:  apiKey,
+		Model:   model,
+		Headers: make(map[string]string),
+		Client:  http.DefaultClient,
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// embeddingRequest is the body of an embeddings request.
+type embeddingRequest struct {
+	Model string `json:"model"`
+	Input string `json:"input"`
+}
+
+// embeddingResponse is the body of an embeddings response.
+type embeddingResponse struct {
+	Data []struct {
+		Embedding []float64 `json:"embedding"`
+	} `json:"data"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// Encode encodes the given utterance using the embeddings endpoint.
+func (e *Encoder) Encode(
+	ctx context.Context,
+	utterance string,
+) ([]float64, error) {
+	body, err := json.Marshal(embeddingRequest{
+		Model: e.Model,
+		Input: utterance,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		strings.TrimSuffix(e.BaseURL, "/")+"/embeddings",
+		bytes.NewReader(body),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if e.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+e.APIKey)
+	}
+	for key, value := range e.Headers {
+		req.Header.Set(key, value)
+	}
+	client := e.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error sending request: %w", err)
+	}
+	defer resp.Body.Close()
+	var res embeddingResponse
+	err = json.NewDecoder(resp.Body).Decode(&res)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"error decoding response with status %d: %w",
+			resp.StatusCode,
+			err,
+		)
+	}
+	if resp.StatusCode != http.StatusOK {
+		if res.Error != nil {
+			return nil, fmt.Errorf(
+				"error creating embedding: status %d: %s",
+				resp.StatusCode,
+				res.Error.Message,
+			)
+		}
+		return nil, fmt.Errorf(
+			"error creating embedding: status %d",
+			resp.StatusCode,
+		)
+	}
+	if len(res.Data) == 0 {
+		return nil, fmt.Errorf("error creating embedding: empty response")
+	}
+	return res.Data[0].Embedding, nil
+}
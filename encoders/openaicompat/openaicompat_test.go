@@ -0,0 +1,47 @@
+package openaicompat
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestEncoder tests the encoder against a mock embeddings server.
+func TestEncoder(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/v1/embeddings", r.URL.Path)
+			assert.Equal(t, "Bearer key", r.Header.Get("Authorization"))
+			assert.Equal(t, "value", r.Header.Get("X-Custom"))
+			var req embeddingRequest
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			assert.Equal(t, "model", req.Model)
+			if req.Input == "fail" {
+				w.WriteHeader(http.StatusTooManyRequests)
+				_, _ = w.Write([]byte(`{"error":{"message":"rate limited"}}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"data":[{"embedding":[0.1,0.2,0.3]}]}`))
+		},
+	))
+	defer server.Close()
+
+	encoder := NewEncoder(
+		server.URL+"/v1/",
+		"key",
+		"model",
+		WithHeader("X-Custom", "value"),
+		WithHTTPClient(server.Client()),
+	)
+	em, err := encoder.Encode(context.Background(), "hello")
+	require.NoError(t, err)
+	assert.Equal(t, []float64{0.1, 0.2, 0.3}, em)
+
+	_, err = encoder.Encode(context.Background(), "fail")
+	assert.ErrorContains(t, err, "status 429: rate limited")
+}
@@ -0,0 +1,63 @@
+// Package ratelimit provides an encoder wrapper limiting the rate of calls
+// to another encoder with a token bucket.
+//
+// Wrapping the encoder of a router keeps the build of a large set of routes
+// under the quota of an embedding API, instead of failing with rate limit
+// errors:
+//
+//	router, err := semanticrouter.NewRouter(
+//		routes,
+//		ratelimit.NewEncoder(encoder, 50, 10),
+//		store,
+//	)
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+
+	semanticrouter "github.com/conneroisu/go-semantic-router"
+	"golang.org/x/time/rate"
+)
+
+// Encoder is an encoder waiting for a token of a token bucket before each
+// call to an underlying encoder.
+//
+// It is safe for concurrent use, concurrent calls sharing the bucket, as
+// with semanticrouter.WithAutoConcurrency.
+type Encoder struct {
+	Encoder semanticrouter.Encoder // Encoder is the underlying encoder.
+	Limiter *rate.Limiter          // Limiter is the token bucket of the calls.
+}
+
+// NewEncoder creates a new Encoder allowing rps calls per second to the
+// given encoder on average, and bursts of up to burst calls.
+//
+// The bucket starts full. A burst below one is raised to one, so that calls
+// are not blocked forever.
+func NewEncoder(
+	encoder semanticrouter.Encoder,
+	rps float64,
+	burst int,
+) *Encoder {
+	return &Encoder{
+		Encoder: encoder,
+		Limiter: rate.NewLimiter(rate.Limit(rps), max(burst, 1)),
+	}
+}
+
+// Encode waits for a token, or for the context to be done, then encodes the
+// utterance with the underlying encoder.
+//
+// If the context is done first, or its deadline expires before a token is
+// available, the utterance is not encoded and an error is returned.
+func (e *Encoder) Encode(
+	ctx context.Context,
+	utterance string,
+) ([]float64, error) {
+	err := e.Limiter.Wait(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error waiting for rate limit: %w", err)
+	}
+	return e.Encoder.Encode(ctx, utterance)
+}
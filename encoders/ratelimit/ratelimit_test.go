@@ -0,0 +1,64 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fixedEncoder is an encoder returning the same embedding for every
+// utterance.
+type fixedEncoder []float64
+
+// Encode returns the fixed embedding.
+func (f fixedEncoder) Encode(context.Context, string) ([]float64, error) {
+	return f, nil
+}
+
+// TestEncoder tests that calls beyond the burst are spaced according to the
+// rate.
+func TestEncoder(t *testing.T) {
+	ctx := context.Background()
+	const (
+		rps     = 50
+		burst   = 2
+		calls   = 7
+		spacing = time.Second / rps
+	)
+	encoder := NewEncoder(fixedEncoder{1.0, 2.0}, rps, burst)
+	start := time.Now()
+	var at []time.Duration
+	for i := 0; i < calls; i++ {
+		embedding, err := encoder.Encode(ctx, "hello")
+		require.NoError(t, err)
+		assert.Equal(t, []float64{1.0, 2.0}, embedding)
+		at = append(at, time.Since(start))
+	}
+	for i := 0; i < burst; i++ {
+		assert.Less(t, at[i], spacing/2, "call %d of the burst waited", i)
+	}
+	for i := burst; i < calls; i++ {
+		assert.GreaterOrEqual(t, at[i], time.Duration(i-burst+1)*spacing-2*time.Millisecond, "call %d", i)
+	}
+}
+
+// TestEncoderCanceled tests that waiting for a token stops once the context
+// is done.
+func TestEncoderCanceled(t *testing.T) {
+	encoder := NewEncoder(fixedEncoder{1.0}, 0.001, 0)
+	_, err := encoder.Encode(context.Background(), "hello")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = encoder.Encode(ctx, "hello")
+	assert.ErrorIs(t, err, context.Canceled)
+
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = encoder.Encode(ctx, "hello")
+	assert.Error(t, err)
+}
@@ -0,0 +1,68 @@
+// Package validate provides an encoder wrapper validating the embeddings
+// returned by another encoder.
+//
+// Wrapping the encoder of a router guards its build against API quirks such
+// as truncated vectors or NaN components:
+//
+//	router, err := semanticrouter.NewRouter(
+//		routes,
+//		validate.NewEncoder(encoder, 1536),
+//		store,
+//	)
+package validate
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	semanticrouter "github.com/conneroisu/go-semantic-router"
+)
+
+// Encoder is an encoder checking that the embeddings returned by an
+// underlying encoder have the expected dimension and only finite components.
+type Encoder struct {
+	Encoder   semanticrouter.Encoder // Encoder is the underlying encoder.
+	Dimension int                    // Dimension is the expected dimension of the embeddings, zero to accept any.
+}
+
+// NewEncoder creates a new Encoder validating the embeddings of the given
+// encoder against the given dimension.
+func NewEncoder(encoder semanticrouter.Encoder, dimension int) *Encoder {
+	return &Encoder{Encoder: encoder, Dimension: dimension}
+}
+
+// Encode encodes the utterance with the underlying encoder and validates the
+// returned embedding.
+func (e *Encoder) Encode(
+	ctx context.Context,
+	utterance string,
+) ([]float64, error) {
+	embedding, err := e.Encoder.Encode(ctx, utterance)
+	if err != nil {
+		return nil, err
+	}
+	err = e.Validate(embedding)
+	if err != nil {
+		return nil, fmt.Errorf("invalid embedding of utterance %q: %w", utterance, err)
+	}
+	return embedding, nil
+}
+
+// Validate checks that the embedding has the expected dimension and that
+// none of its components is NaN or infinite.
+func (e *Encoder) Validate(embedding []float64) error {
+	if e.Dimension > 0 && len(embedding) != e.Dimension {
+		return fmt.Errorf(
+			"embedding has dimension %d, expected %d",
+			len(embedding),
+			e.Dimension,
+		)
+	}
+	for i, v := range embedding {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return fmt.Errorf("embedding component %d is %v", i, v)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package validate
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	semanticrouter "github.com/conneroisu/go-semantic-router"
+	"github.com/conneroisu/go-semantic-router/domain"
+	"github.com/conneroisu/go-semantic-router/stores/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fixedEncoder is an encoder returning the same embedding for every
+// utterance.
+type fixedEncoder []float64
+
+// Encode returns the fixed embedding.
+func (f fixedEncoder) Encode(context.Context, string) ([]float64, error) {
+	return f, nil
+}
+
+func TestEncoder(t *testing.T) {
+	ctx := context.Background()
+	testCases := []struct {
+		name      string
+		embedding []float64
+		wantErr   string
+	}{
+		{name: "valid", embedding: []float64{0.1, 0.2, 0.3}},
+		{name: "short", embedding: []float64{0.1, 0.2}, wantErr: "embedding has dimension 2, expected 3"},
+		{name: "long", embedding: []float64{0.1, 0.2, 0.3, 0.4}, wantErr: "embedding has dimension 4, expected 3"},
+		{name: "nan", embedding: []float64{0.1, math.NaN(), 0.3}, wantErr: "embedding component 1 is NaN"},
+		{name: "inf", embedding: []float64{0.1, 0.2, math.Inf(-1)}, wantErr: "embedding component 2 is -Inf"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			encoder := NewEncoder(fixedEncoder(tc.embedding), 3)
+			embedding, err := encoder.Encode(ctx, "hello")
+			if tc.wantErr == "" {
+				require.NoError(t, err)
+				assert.Equal(t, tc.embedding, embedding)
+				return
+			}
+			assert.ErrorContains(t, err, `invalid embedding of utterance "hello": `+tc.wantErr)
+		})
+	}
+}
+
+func TestEncoderGuardsNewRouter(t *testing.T) {
+	_, err := semanticrouter.NewRouter(
+		[]semanticrouter.Route{{
+			Name:       "chitchat",
+			Utterances: []domain.Utterance{{Utterance: "hello"}},
+		}},
+		NewEncoder(fixedEncoder{0.1, math.NaN()}, 2),
+		memory.NewStore(),
+	)
+	assert.ErrorContains(t, err, "embedding component 1 is NaN")
+}
@@ -0,0 +1,97 @@
+package semanticrouter
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Enumerator is a Store that can also list the utterances it has embeddings
+// for.
+//
+// Enumeration is optional: the memory and valkey stores implement it, but
+// vector stores and other remote stores may not, in which case the features
+// relying on it, such as PruneStore, fail with an error wrapping
+// ErrNotSupported.
+type Enumerator interface {
+	Store
+	// Keys returns the utterances the store has embeddings for, as stored,
+	// that is normalized if the router uses WithKeyNormalization.
+	Keys(ctx context.Context) ([]string, error)
+}
+
+// PruneStore deletes from the router's store the embeddings of the
+// utterances no route of the router has anymore, such as those left behind
+// by routes removed from the configuration, returning the number of deleted
+// utterances.
+//
+// The store must implement both Enumerator and Deleter. Since a store may
+// be shared with other routers and applications, only the stored keys for
+// which owns reports true are deleted, and owns must not be nil. Keys
+// prefixed with TenantKeyPrefix belong to the tenants of a TenantRouter and
+// are never deleted.
+//
+// PruneStore is safe for concurrent use with matching and with AddRoute,
+// AddUtterances and UpdateRoute.
+func (r *Router) PruneStore(ctx context.Context, owns func(key string) bool) (int, error) {
+	if owns == nil {
+		return 0, fmt.Errorf("error pruning store: no key filter")
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	enumerator, ok := r.Storage.(Enumerator)
+	if !ok {
+		return 0, fmt.Errorf("error pruning store: store %T does not implement Enumerator: %w", r.Storage, ErrNotSupported)
+	}
+	if _, ok := r.Storage.(Deleter); !ok {
+		return 0, fmt.Errorf("error pruning store: store %T does not implement Deleter: %w", r.Storage, ErrNotSupported)
+	}
+	used := map[string]bool{r.key(IndexInfoKey): true}
+	for _, route := range r.Routes {
+		for _, utter := range route.Utterances {
+			used[r.key(utter.Utterance)] = true
+			for i := range variantCount(utter) {
+				used[r.key(VariantKey(utter.Utterance, i))] = true
+			}
+		}
+		if r.centroids {
+			used[r.key(CentroidKey(route.Name))] = true
+		}
+	}
+	keys, err := r.keys(ctx, enumerator)
+	if err != nil {
+		return 0, fmt.Errorf("error listing stored utterances: %w", err)
+	}
+	var pruned int
+	for _, key := range keys {
+		if used[key] || strings.HasPrefix(key, tenantKeyPrefix) || !owns(key) {
+			continue
+		}
+		if ctx.Err() != nil {
+			return pruned, ctx.Err()
+		}
+		// Normalizing keys is idempotent, so stored keys can be deleted as
+		// utterances.
+		err = r.deleteUtterance(ctx, key)
+		if err != nil {
+			return pruned, fmt.Errorf("error deleting utterance: %s: %w", key, err)
+		}
+		pruned++
+	}
+	return pruned, nil
+}
+
+// keys lists the utterances stored in the given enumerator, the router's
+// store.
+func (r *Router) keys(
+	ctx context.Context,
+	enumerator Enumerator,
+) ([]string, error) {
+	callCtx, cancel := r.callContext(ctx)
+	defer cancel()
+	keys, err := enumerator.Keys(callCtx)
+	if err != nil {
+		return nil, callError(callCtx, err)
+	}
+	return keys, nil
+}
@@ -0,0 +1,67 @@
+package semanticrouter
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/conneroisu/go-semantic-router/domain"
+	"github.com/conneroisu/go-semantic-router/stores/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPruneStore tests that pruning the store deletes the embeddings of the
+// utterances no route has, keeping those of the routes and their centroids
+// along with the keys the router does not own.
+func TestPruneStore(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewStore()
+	orphan := domain.Utterance{Utterance: "a removed utterance"}
+	require.NoError(t, orphan.SetEmbedding([]float64{1.0, 0.0, 0.0}))
+	require.NoError(t, store.Store(ctx, orphan))
+	foreign := domain.Utterance{Utterance: "other:an utterance of another application"}
+	require.NoError(t, foreign.SetEmbedding([]float64{1.0, 0.0, 0.0}))
+	require.NoError(t, store.Store(ctx, foreign))
+	tenant := domain.Utterance{Utterance: TenantKeyPrefix("acme") + "a tenant utterance"}
+	require.NoError(t, tenant.SetEmbedding([]float64{1.0, 0.0, 0.0}))
+	require.NoError(t, store.Store(ctx, tenant))
+	owns := func(key string) bool {
+		return !strings.HasPrefix(key, "other:")
+	}
+	router, err := NewRouter(newTestRoutes(), newTestEncoder(), store, WithRouteCentroids(true))
+	require.NoError(t, err)
+
+	pruned, err := router.PruneStore(ctx, owns)
+	require.NoError(t, err)
+	assert.Equal(t, 1, pruned)
+	keys, err := store.Keys(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"how's the weather today?",
+		"i love the president",
+		"lovely weather today",
+		"other:an utterance of another application",
+		CentroidKey("chitchat"),
+		CentroidKey("politics"),
+		tenant.Utterance,
+		"who will win the vote?",
+	}, keys)
+
+	route, _, err := router.Match(ctx, "is it raining outside?")
+	require.NoError(t, err)
+	assert.Equal(t, "chitchat", route)
+
+	pruned, err = router.PruneStore(ctx, owns)
+	require.NoError(t, err)
+	assert.Zero(t, pruned)
+
+	_, err = router.PruneStore(ctx, nil)
+	assert.Error(t, err)
+
+	// Stores which cannot list their utterances are not supported.
+	router, err = NewRouter(newTestRoutes(), newTestEncoder(), &countingStore{store: memory.NewStore()})
+	require.NoError(t, err)
+	_, err = router.PruneStore(ctx, owns)
+	assert.ErrorIs(t, err, ErrNotSupported)
+}
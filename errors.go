@@ -0,0 +1,129 @@
+package semanticrouter
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrNoRouteFound is the error returned when no route matches an utterance,
+// for instance because no route scores above the threshold. Use TryMatch to
+// tell it apart from other errors without testing for it.
+var ErrNoRouteFound = errors.New("no route found")
+
+// ErrEncoding is the error returned when an utterance cannot be encoded.
+type ErrEncoding struct {
+	Message string // Message describes the failed operation.
+	Err     error  // Err is the error returned by the encoder.
+}
+
+// Error returns the message of the error along with the encoder's error.
+func (e ErrEncoding) Error() string {
+	if e.Err == nil {
+		return e.Message
+	}
+	return e.Message + ": " + e.Err.Error()
+}
+
+// Unwrap returns the error returned by the encoder.
+func (e ErrEncoding) Unwrap() error {
+	return e.Err
+}
+
+// ErrGetEmbedding is the error returned when the embedding of an utterance
+// cannot be retrieved from the store.
+type ErrGetEmbedding struct {
+	Message string // Message describes the failed operation.
+	Err     error  // Err is the error returned by the store.
+}
+
+// Error returns the message of the error along with the store's error.
+func (e ErrGetEmbedding) Error() string {
+	if e.Err == nil {
+		return e.Message
+	}
+	return e.Message + ": " + e.Err.Error()
+}
+
+// Unwrap returns the error returned by the store.
+func (e ErrGetEmbedding) Unwrap() error {
+	return e.Err
+}
+
+// ErrEmptyUtterance is the error returned when an utterance is empty or only
+// contains whitespace, instead of encoding it.
+type ErrEmptyUtterance struct {
+	Route string // Route is the name of the route of the utterance, empty for queries.
+}
+
+// Error returns a message naming the route of the empty utterance, if any.
+func (e ErrEmptyUtterance) Error() string {
+	if e.Route == "" {
+		return "utterance is empty"
+	}
+	return fmt.Sprintf("route %q has an empty utterance", e.Route)
+}
+
+// ErrDimensionMismatch is the error returned when the embedding of an index
+// utterance does not have the dimension of the query, see
+// WithStrictDimensions.
+type ErrDimensionMismatch struct {
+	Utterance string // Utterance is the index utterance with the mismatched embedding.
+	Expected  int    // Expected is the dimension of the query embedding.
+	Actual    int    // Actual is the dimension of the index embedding.
+}
+
+// Error returns a message naming the utterance and both dimensions.
+func (e ErrDimensionMismatch) Error() string {
+	return fmt.Sprintf(
+		"embedding of utterance %q has dimension %d, expected %d",
+		e.Utterance,
+		e.Actual,
+		e.Expected,
+	)
+}
+
+// ErrZeroEmbedding is the error returned when the encoder returns an
+// embedding whose components are all zero for a query, on which similarity
+// functions are meaningless.
+type ErrZeroEmbedding struct {
+	Utterance string // Utterance is the query utterance.
+}
+
+// Error returns a message naming the query utterance.
+func (e ErrZeroEmbedding) Error() string {
+	return fmt.Sprintf("embedding of utterance %q is all zeros", e.Utterance)
+}
+
+// ErrDuplicateUtterance is the error returned when the routes of a router
+// have the same utterance more than once, see WithOnDuplicate.
+type ErrDuplicateUtterance struct {
+	Utterance string   // Utterance is the duplicated utterance.
+	Routes    []string // Routes are the names of the routes having the utterance, once per occurrence.
+}
+
+// Error returns a message naming the utterance and the routes having it.
+func (e ErrDuplicateUtterance) Error() string {
+	return fmt.Sprintf(
+		"utterance %q is duplicated in routes %q",
+		e.Utterance,
+		e.Routes,
+	)
+}
+
+// ErrInvalidScore is the error returned when a similarity function returns a
+// NaN or infinite score, see WithNaNPolicy.
+type ErrInvalidScore struct {
+	Function  string  // Function is the name of the similarity function.
+	Utterance string  // Utterance is the index utterance that was scored.
+	Score     float64 // Score is the NaN or infinite score.
+}
+
+// Error returns a message naming the function, the score and the utterance.
+func (e ErrInvalidScore) Error() string {
+	return fmt.Sprintf(
+		"similarity function %q returned %v for utterance %q",
+		e.Function,
+		e.Score,
+		e.Utterance,
+	)
+}
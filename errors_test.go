@@ -0,0 +1,187 @@
+package semanticrouter
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/conneroisu/go-semantic-router/domain"
+	"github.com/conneroisu/go-semantic-router/stores/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// errEncoder is an encoder failing with a fixed error.
+type errEncoder struct {
+	Encoder
+	err error
+}
+
+// Encode returns the encoder's error if it is set.
+func (e *errEncoder) Encode(
+	ctx context.Context,
+	utterance string,
+) ([]float64, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+	return e.Encoder.Encode(ctx, utterance)
+}
+
+// errStore is a store whose Get fails with a fixed error.
+type errStore struct {
+	store Store
+	err   error
+}
+
+// Get returns the store's error if it is set.
+func (s *errStore) Get(ctx context.Context, utterance string) ([]float64, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return s.store.Get(ctx, utterance)
+}
+
+// Store stores the utterance in the underlying store.
+func (s *errStore) Store(ctx context.Context, utterance domain.Utterance) error {
+	return s.store.Store(ctx, utterance)
+}
+
+// TestErrEncoding tests that encoder errors are preserved by ErrEncoding.
+func TestErrEncoding(t *testing.T) {
+	ctx := context.Background()
+	sentinel := errors.New("unauthorized")
+	encoder := &errEncoder{Encoder: newTestEncoder()}
+	router, err := NewRouter(newTestRoutes(), encoder, memory.NewStore())
+	require.NoError(t, err)
+
+	encoder.err = sentinel
+	_, _, err = router.Match(ctx, "is it raining outside?")
+	assert.ErrorIs(t, err, sentinel)
+	var encErr ErrEncoding
+	require.ErrorAs(t, err, &encErr)
+	assert.Equal(t, sentinel, encErr.Err)
+	assert.Equal(t, "error encoding utterance: unauthorized", err.Error())
+
+	_, err = NewRouter(newTestRoutes(), encoder, memory.NewStore())
+	assert.ErrorIs(t, err, sentinel)
+	assert.ErrorAs(t, err, &encErr)
+}
+
+// TestErrGetEmbedding tests that store errors are preserved by
+// ErrGetEmbedding.
+func TestErrGetEmbedding(t *testing.T) {
+	ctx := context.Background()
+	sentinel := errors.New("connection refused")
+	store := &errStore{store: memory.NewStore()}
+	router, err := NewRouter(newTestRoutes(), newTestEncoder(), store)
+	require.NoError(t, err)
+
+	store.err = sentinel
+	_, _, err = router.Match(ctx, "is it raining outside?")
+	assert.ErrorIs(t, err, sentinel)
+	var getErr ErrGetEmbedding
+	require.ErrorAs(t, err, &getErr)
+	assert.Equal(t, sentinel, getErr.Err)
+}
+
+// TestErrEmptyUtterance tests that empty and whitespace-only queries are
+// rejected without being encoded, unless an expander produces content.
+func TestErrEmptyUtterance(t *testing.T) {
+	ctx := context.Background()
+	encoder := &countingEncoder{Encoder: newTestEncoder()}
+	router, err := NewRouter(newTestRoutes(), encoder, memory.NewStore())
+	require.NoError(t, err)
+	built := encoder.calls.Load()
+
+	for _, utterance := range []string{"", "   ", "\t\n"} {
+		_, _, err = router.Match(ctx, utterance)
+		var empty ErrEmptyUtterance
+		assert.ErrorAs(t, err, &empty)
+	}
+	assert.Equal(t, built, encoder.calls.Load())
+
+	router, err = NewRouter(
+		newTestRoutes(),
+		newTestEncoder(),
+		memory.NewStore(),
+		WithQueryExpansion(func(context.Context, string) ([]string, error) {
+			return []string{"is it raining outside?"}, nil
+		}),
+	)
+	require.NoError(t, err)
+	route, _, err := router.Match(ctx, " ")
+	require.NoError(t, err)
+	assert.Equal(t, "chitchat", route)
+
+	routes := newTestRoutes()
+	routes[0].Utterances = append(routes[0].Utterances, domain.Utterance{})
+	_, err = NewRouter(routes, newTestEncoder(), memory.NewStore())
+	var empty ErrEmptyUtterance
+	require.ErrorAs(t, err, &empty)
+	assert.Equal(t, "chitchat", empty.Route)
+}
+
+// TestErrDimensionMismatch tests that mismatched index embeddings are
+// skipped by default and reported with WithStrictDimensions.
+func TestErrDimensionMismatch(t *testing.T) {
+	ctx := context.Background()
+	newEncoder := func() *mockEncoder {
+		encoder := newTestEncoder()
+		encoder.embeddings["i love the president"] = []float64{0.1, 0.9}
+		return encoder
+	}
+
+	router, err := NewRouter(newTestRoutes(), newEncoder(), memory.NewStore())
+	require.NoError(t, err)
+	route, _, err := router.Match(ctx, "what about the election?")
+	require.NoError(t, err)
+	assert.Equal(t, "politics", route)
+
+	router, err = NewRouter(
+		newTestRoutes(),
+		newEncoder(),
+		memory.NewStore(),
+		WithStrictDimensions(true),
+	)
+	require.NoError(t, err)
+	_, _, err = router.Match(ctx, "what about the election?")
+	var mismatch ErrDimensionMismatch
+	require.ErrorAs(t, err, &mismatch)
+	assert.Equal(t, ErrDimensionMismatch{
+		Utterance: "i love the president",
+		Expected:  3,
+		Actual:    2,
+	}, mismatch)
+	_, err = router.ScoreAll(ctx, "what about the election?")
+	assert.ErrorAs(t, err, &mismatch)
+
+	// Mismatched embeddings are reported before the reranker drops them.
+	router, err = NewRouter(
+		newTestRoutes(),
+		newEncoder(),
+		memory.NewStore(),
+		WithStrictDimensions(true),
+		WithReranker(&stubReranker{}, 1),
+	)
+	require.NoError(t, err)
+	_, _, err = router.Match(ctx, "what about the election?")
+	assert.ErrorAs(t, err, &mismatch)
+	_, err = router.MatchN(ctx, "what about the election?", 1)
+	assert.ErrorAs(t, err, &mismatch)
+}
+
+// TestErrZeroEmbedding tests that a zero query embedding is reported instead
+// of being matched.
+func TestErrZeroEmbedding(t *testing.T) {
+	ctx := context.Background()
+	encoder := newTestEncoder()
+	encoder.embeddings["degenerate"] = []float64{0.0, 0.0, 0.0}
+	router, err := NewRouter(newTestRoutes(), encoder, memory.NewStore())
+	require.NoError(t, err)
+
+	_, _, err = router.Match(ctx, "degenerate")
+	var zero ErrZeroEmbedding
+	require.ErrorAs(t, err, &zero)
+	assert.Equal(t, "degenerate", zero.Utterance)
+}
@@ -0,0 +1,40 @@
+package semanticrouter
+
+import (
+	"slices"
+	"strings"
+)
+
+// exactText returns the normalized form of the given text compared by the
+// exact match shortcut: its NormalizeKey form with runs of whitespace
+// collapsed into single spaces and leading and trailing whitespace removed.
+func exactText(text string) string {
+	return strings.Join(strings.Fields(NormalizeKey(text)), " ")
+}
+
+// matchExactText returns the route of the first utterance whose text, or
+// the text of one of its variants, equals the given utterance once
+// normalized, see WithExactMatchShortcut, and false if there is none.
+//
+// The score of an exact match is the best possible one: 1.0, or 0.0 with
+// LowerIsBetter.
+func (r *Router) matchExactText(utterance string) (string, float64, bool) {
+	text, words := exactText(utterance), gateWords(utterance)
+	for _, route := range r.Routes {
+		if len(route.Utterances) < r.minUtterances || !route.passesKeywordGate(words) {
+			continue
+		}
+		for _, ut := range route.Utterances {
+			if exactText(ut.Utterance) != text && !slices.ContainsFunc(ut.Variants, func(variant string) bool {
+				return exactText(variant) == text
+			}) {
+				continue
+			}
+			if r.scoreDirection == LowerIsBetter {
+				return route.Name, 0.0, true
+			}
+			return route.Name, 1.0, true
+		}
+	}
+	return "", 0.0, false
+}
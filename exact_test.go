@@ -0,0 +1,44 @@
+package semanticrouter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/conneroisu/go-semantic-router/stores/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestWithExactMatchShortcut tests that queries equal to an utterance are
+// matched without calling the encoder.
+func TestWithExactMatchShortcut(t *testing.T) {
+	ctx := context.Background()
+	encoder := &countingEncoder{Encoder: newTestEncoder()}
+	router, err := NewRouter(newTestRoutes(), encoder, memory.NewStore(), WithExactMatchShortcut(true))
+	require.NoError(t, err)
+	built := encoder.calls.Load()
+
+	for _, query := range []string{"who will win the vote?", "  Who will WIN  the vote? "} {
+		route, score, err := router.Match(ctx, query)
+		require.NoError(t, err)
+		assert.Equal(t, "politics", route)
+		assert.Equal(t, 1.0, score)
+	}
+	assert.Equal(t, built, encoder.calls.Load())
+
+	route, score, err := router.Match(ctx, "is it raining outside?")
+	require.NoError(t, err)
+	assert.Equal(t, "chitchat", route)
+	assert.Less(t, score, 1.0)
+	assert.Equal(t, built+1, encoder.calls.Load())
+
+	t.Run("disabled", func(t *testing.T) {
+		encoder := &countingEncoder{Encoder: newTestEncoder()}
+		router, err := NewRouter(newTestRoutes(), encoder, memory.NewStore())
+		require.NoError(t, err)
+		built := encoder.calls.Load()
+		_, _, err = router.Match(ctx, "who will win the vote?")
+		require.NoError(t, err)
+		assert.Equal(t, built+1, encoder.calls.Load())
+	})
+}
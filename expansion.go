@@ -0,0 +1,106 @@
+package semanticrouter
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// QueryExpander expands a query utterance into variants, such as synonyms or
+// paraphrases, that are matched alongside the original utterance.
+type QueryExpander func(ctx context.Context, utterance string) ([]string, error)
+
+// ExpansionMode is how the scores of the variants of an expanded query are
+// combined into the score of a route.
+type ExpansionMode int
+
+const (
+	// ExpansionMax takes the best score among the variants.
+	ExpansionMax ExpansionMode = iota
+	// ExpansionMean takes the mean score of the variants.
+	ExpansionMean
+)
+
+// encodeQueries encodes the given utterance, along with its expansions if a
+// query expander is configured and its n-grams if n-gram matching is
+// configured, into queries.
+//
+// Empty and whitespace-only utterances are never encoded; if no utterance
+// is left, ErrEmptyUtterance is returned.
+func (r *Router) encodeQueries(
+	ctx context.Context,
+	utterance string,
+) ([]query, error) {
+	utterances := []string{utterance}
+	if r.expander != nil {
+		variants, err := r.expander(ctx, utterance)
+		if err != nil {
+			return nil, fmt.Errorf("error expanding query: %w", err)
+		}
+		utterances = append(utterances, variants...)
+	}
+	utterances = append(utterances, queryNGrams(utterance, r.ngramSizes)...)
+	qs := make([]query, 0, len(utterances))
+	for _, u := range utterances {
+		if strings.TrimSpace(u) == "" {
+			continue
+		}
+		q, err := r.encodeQuery(ctx, u)
+		if err != nil {
+			return nil, err
+		}
+		qs = append(qs, q)
+	}
+	if len(qs) == 0 {
+		return nil, ErrEmptyUtterance{}
+	}
+	return qs, nil
+}
+
+// scoreQueries computes the aggregated score of each route of the index
+// against the given queries, combining the scores of the queries according
+// to the configured expansion mode.
+func (r *Router) scoreQueries(
+	qs []query,
+	index []indexEntry,
+) (scores []routeScore, err error) {
+	defer func() {
+		r.applyPriors(scores)
+		r.applyRecency(scores)
+	}()
+	if len(qs) == 1 {
+		return r.scoreIndex(qs[0], index)
+	}
+	positions := make(map[string]int)
+	counts := make([]int, 0)
+	for _, q := range qs {
+		queryScores, err := r.scoreIndex(q, index)
+		if err != nil {
+			return nil, err
+		}
+		for _, rs := range queryScores {
+			pos, ok := positions[rs.route]
+			if !ok {
+				positions[rs.route] = len(scores)
+				scores = append(scores, rs)
+				counts = append(counts, 1)
+				continue
+			}
+			counts[pos]++
+			switch r.expansionMode {
+			case ExpansionMean:
+				scores[pos].score += rs.score
+			default:
+				if r.better(rs.score, scores[pos].score) {
+					scores[pos].score = rs.score
+				}
+			}
+		}
+	}
+	if r.expansionMode == ExpansionMean {
+		for i := range scores {
+			scores[i].score /= float64(counts[i])
+		}
+	}
+	return scores, nil
+}
@@ -0,0 +1,62 @@
+package semanticrouter
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/conneroisu/go-semantic-router/stores/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestWithQueryExpansion tests that the variants of an expanded query are
+// matched alongside the original utterance.
+func TestWithQueryExpansion(t *testing.T) {
+	ctx := context.Background()
+	encoder := newTestEncoder()
+	encoder.embeddings["drizzle?"] = []float64{0.0, 0.1, 1.0}
+	expander := func(_ context.Context, utterance string) ([]string, error) {
+		if utterance != "drizzle?" {
+			return nil, nil
+		}
+		return []string{"is it raining outside?", "lovely weather today"}, nil
+	}
+
+	router, err := NewRouter(newTestRoutes(), encoder, memory.NewStore())
+	require.NoError(t, err)
+	route, _, err := router.Match(ctx, "drizzle?")
+	require.NoError(t, err)
+	assert.Equal(t, "politics", route)
+
+	for _, mode := range []ExpansionMode{ExpansionMax, ExpansionMean} {
+		router, err = NewRouter(
+			newTestRoutes(),
+			encoder,
+			memory.NewStore(),
+			WithQueryExpansion(expander),
+			WithQueryExpansionMode(mode),
+		)
+		require.NoError(t, err)
+		route, _, err = router.Match(ctx, "drizzle?")
+		require.NoError(t, err)
+		assert.Equal(t, "chitchat", route)
+
+		route, _, err = router.Match(ctx, "what about the election?")
+		require.NoError(t, err)
+		assert.Equal(t, "politics", route)
+	}
+
+	sentinel := errors.New("expansion failed")
+	router, err = NewRouter(
+		newTestRoutes(),
+		encoder,
+		memory.NewStore(),
+		WithQueryExpansion(func(context.Context, string) ([]string, error) {
+			return nil, sentinel
+		}),
+	)
+	require.NoError(t, err)
+	_, _, err = router.Match(ctx, "drizzle?")
+	assert.ErrorIs(t, err, sentinel)
+}
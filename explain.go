@@ -0,0 +1,117 @@
+package semanticrouter
+
+import (
+	"context"
+	"fmt"
+	"slices"
+)
+
+// Explanation compares the route expected for an utterance to the route
+// winning it, see ExplainAgainst.
+//
+// Route scores are aggregated as in ScoreAll, while the best utterances and
+// the gaps are those of the best scoring pair of query and utterance of each
+// route. Gaps are the scores of the winning route minus those of the
+// expected route, positive where the winning route scores higher.
+type Explanation struct {
+	Utterance         string             `json:"utterance"          yaml:"utterance"          toml:"utterance"`          // Utterance is the explained utterance.
+	ExpectedRoute     string             `json:"expected_route"     yaml:"expected_route"     toml:"expected_route"`     // ExpectedRoute is the name of the expected route.
+	ExpectedScore     float64            `json:"expected_score"     yaml:"expected_score"     toml:"expected_score"`     // ExpectedScore is the aggregated score of the expected route.
+	ExpectedUtterance string             `json:"expected_utterance" yaml:"expected_utterance" toml:"expected_utterance"` // ExpectedUtterance is the best scoring utterance of the expected route.
+	WinningRoute      string             `json:"winning_route"      yaml:"winning_route"      toml:"winning_route"`      // WinningRoute is the name of the best scoring route.
+	WinningScore      float64            `json:"winning_score"      yaml:"winning_score"      toml:"winning_score"`      // WinningScore is the aggregated score of the winning route.
+	WinningUtterance  string             `json:"winning_utterance"  yaml:"winning_utterance"  toml:"winning_utterance"`  // WinningUtterance is the best scoring utterance of the winning route.
+	Threshold         float64            `json:"threshold"          yaml:"threshold"          toml:"threshold"`          // Threshold is the minimum score of a match.
+	Gap               float64            `json:"gap"                yaml:"gap"                toml:"gap"`                // Gap is the winning score minus the expected score.
+	FunctionGaps      map[string]float64 `json:"function_gaps"      yaml:"function_gaps"      toml:"function_gaps"`      // FunctionGaps are the gaps of the unweighted score of each similarity function.
+}
+
+// ExplainAgainst explains why the expected route did not win the given
+// utterance, comparing its score, best utterance and per-function scores to
+// those of the winning route.
+//
+// The winning route is the best scoring one regardless of the threshold, so
+// that an expected route losing only to the threshold is explained as
+// winning with a score below Threshold. If the expected route wins, the gaps
+// are zero. It returns an error if the expected route does not exist or has
+// no utterance scored against the utterance.
+func (r *Router) ExplainAgainst(
+	ctx context.Context,
+	utterance string,
+	expectedRoute string,
+) (Explanation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if !slices.ContainsFunc(r.Routes, func(route Route) bool {
+		return route.Name == expectedRoute
+	}) {
+		return Explanation{}, fmt.Errorf("route not found: %q", expectedRoute)
+	}
+	ranked, err := r.scoreUtterance(ctx, utterance, nil)
+	if err != nil {
+		return Explanation{}, err
+	}
+	exp := Explanation{
+		Utterance:     utterance,
+		ExpectedRoute: expectedRoute,
+		Threshold:     r.Threshold(),
+		WinningScore:  r.worstScore(),
+	}
+	var expectedFound bool
+	for _, rs := range ranked.scores {
+		if rs.route == expectedRoute {
+			exp.ExpectedScore, expectedFound = rs.score, true
+		}
+		if r.better(rs.score, exp.WinningScore) {
+			exp.WinningRoute, exp.WinningScore = rs.route, rs.score
+		}
+	}
+	if !expectedFound {
+		return Explanation{}, fmt.Errorf("route %q has no utterance scored against the utterance", expectedRoute)
+	}
+	exp.Gap = exp.WinningScore - exp.ExpectedScore
+	expected, err := r.explainRoute(ranked.qs, ranked.index, expectedRoute)
+	if err != nil {
+		return Explanation{}, err
+	}
+	winning, err := r.explainRoute(ranked.qs, ranked.index, exp.WinningRoute)
+	if err != nil {
+		return Explanation{}, err
+	}
+	exp.ExpectedUtterance, exp.WinningUtterance = expected.utterance, winning.utterance
+	exp.FunctionGaps = make(map[string]float64)
+	for name, score := range winning.breakdown {
+		exp.FunctionGaps[name] = score - expected.breakdown[name]
+	}
+	for name, score := range expected.breakdown {
+		if _, ok := winning.breakdown[name]; !ok {
+			exp.FunctionGaps[name] = -score
+		}
+	}
+	return exp, nil
+}
+
+// routeExplanation is the best scoring utterance of a route along with the
+// unweighted score of each similarity function against it.
+type routeExplanation struct {
+	utterance string
+	breakdown map[string]float64
+}
+
+// explainRoute returns the best scoring utterance of the given route against
+// the given queries, along with its breakdown.
+func (r *Router) explainRoute(
+	qs []query,
+	index []indexEntry,
+	route string,
+) (routeExplanation, error) {
+	q, entry, found, err := r.bestPair(qs, index, route)
+	if err != nil || !found {
+		return routeExplanation{}, err
+	}
+	breakdown, err := r.scoreBreakdown(q, entry, r.similarities(route))
+	if err != nil {
+		return routeExplanation{}, err
+	}
+	return routeExplanation{utterance: entry.utterance, breakdown: breakdown}, nil
+}
@@ -0,0 +1,53 @@
+package semanticrouter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/conneroisu/go-semantic-router/stores/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestExplainAgainst tests that explaining a losing expected route reports
+// its best utterance and the gaps to the winning route.
+func TestExplainAgainst(t *testing.T) {
+	ctx := context.Background()
+	router, err := NewRouter(
+		newTestRoutes(),
+		newTestEncoder(),
+		memory.NewStore(),
+		WithCosineSimilarity(1.0),
+		WithDotProduct(0.5),
+	)
+	require.NoError(t, err)
+	scores, err := router.ScoreAll(ctx, "is it raining outside?")
+	require.NoError(t, err)
+
+	exp, err := router.ExplainAgainst(ctx, "is it raining outside?", "politics")
+	require.NoError(t, err)
+	assert.Equal(t, "politics", exp.ExpectedRoute)
+	assert.Equal(t, scores["politics"], exp.ExpectedScore)
+	assert.Equal(t, "i love the president", exp.ExpectedUtterance)
+	assert.Equal(t, "chitchat", exp.WinningRoute)
+	assert.Equal(t, scores["chitchat"], exp.WinningScore)
+	assert.Equal(t, "how's the weather today?", exp.WinningUtterance)
+	assert.InDelta(t, exp.WinningScore-exp.ExpectedScore, exp.Gap, 1e-12)
+	assert.Positive(t, exp.Gap)
+
+	require.Len(t, exp.FunctionGaps, 2)
+	assert.InDelta(t, 0.8-0.08, exp.FunctionGaps[SimilarityDotProduct], 1e-9)
+	assert.Greater(t, exp.FunctionGaps[SimilarityCosine], 0.8)
+
+	// The winning route has no gap to itself.
+	exp, err = router.ExplainAgainst(ctx, "is it raining outside?", "chitchat")
+	require.NoError(t, err)
+	assert.Equal(t, "chitchat", exp.WinningRoute)
+	assert.Zero(t, exp.Gap)
+	for _, gap := range exp.FunctionGaps {
+		assert.Zero(t, gap)
+	}
+
+	_, err = router.ExplainAgainst(ctx, "is it raining outside?", "missing")
+	assert.Error(t, err)
+}
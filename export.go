@@ -0,0 +1,50 @@
+package semanticrouter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrNotSupported is the error returned when an operation is not supported by
+// the router's configuration or by its store.
+var ErrNotSupported = errors.New("not supported")
+
+// RouteEmbedding is the stored embedding of an utterance of a route.
+type RouteEmbedding struct {
+	Route     string    // Route is the name of the route.
+	Utterance string    // Utterance is the utterance.
+	Embedding []float64 // Embedding is the embedding of the utterance.
+}
+
+// ExportEmbeddings returns the stored embedding of every utterance of every
+// route, in the order of the routes and their utterances, for offline
+// analysis such as clustering or visualization.
+//
+// Only dense embeddings can be exported; other kinds of embeddings fail with
+// an error wrapping ErrNotSupported.
+func (r *Router) ExportEmbeddings(ctx context.Context) ([]RouteEmbedding, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.embeddings != denseEmbeddings {
+		return nil, fmt.Errorf("error exporting embeddings: only dense embeddings can be exported: %w", ErrNotSupported)
+	}
+	var embeddings []RouteEmbedding
+	for _, route := range r.Routes {
+		for _, utter := range route.Utterances {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			em, err := r.get(ctx, utter.Utterance)
+			if err != nil {
+				return nil, ErrGetEmbedding{Message: "error getting embedding", Err: err}
+			}
+			embeddings = append(embeddings, RouteEmbedding{
+				Route:     route.Name,
+				Utterance: utter.Utterance,
+				Embedding: em,
+			})
+		}
+	}
+	return embeddings, nil
+}
@@ -0,0 +1,31 @@
+package semanticrouter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/conneroisu/go-semantic-router/stores/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestExportEmbeddings tests that the embeddings of every utterance are
+// exported with their routes.
+func TestExportEmbeddings(t *testing.T) {
+	ctx := context.Background()
+	router, err := NewRouter(newTestRoutes(), newTestEncoder(), memory.NewStore())
+	require.NoError(t, err)
+	embeddings, err := router.ExportEmbeddings(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []RouteEmbedding{
+		{Route: "chitchat", Utterance: "how's the weather today?", Embedding: []float64{1.0, 0.1, 0.0}},
+		{Route: "chitchat", Utterance: "lovely weather today", Embedding: []float64{0.9, 0.2, 0.0}},
+		{Route: "politics", Utterance: "who will win the vote?", Embedding: []float64{0.0, 1.0, 0.1}},
+		{Route: "politics", Utterance: "i love the president", Embedding: []float64{0.1, 0.9, 0.0}},
+	}, embeddings)
+
+	router, err = NewRouter(newTestRoutes(), sparseEncoder{newTestEncoder()}, memory.NewStore(), WithSparseEmbeddings())
+	require.NoError(t, err)
+	_, err = router.ExportEmbeddings(ctx)
+	assert.ErrorIs(t, err, ErrNotSupported)
+}
@@ -0,0 +1,68 @@
+package semanticrouter
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+)
+
+// keywordTokens returns the set of lowercase words of the given text.
+func keywordTokens(text string) map[string]bool {
+	tokens := make(map[string]bool)
+	for _, word := range strings.FieldsFunc(text, func(c rune) bool {
+		return !unicode.IsLetter(c) && !unicode.IsNumber(c)
+	}) {
+		tokens[strings.ToLower(word)] = true
+	}
+	return tokens
+}
+
+// keywordScore returns the jaccard similarity of the given sets of words.
+func keywordScore(a, b map[string]bool) float64 {
+	var shared int
+	for token := range a {
+		if b[token] {
+			shared++
+		}
+	}
+	union := len(a) + len(b) - shared
+	if union == 0 {
+		return 0
+	}
+	return float64(shared) / float64(union)
+}
+
+// shouldFallback reports whether a match failing with the given error should
+// fall back to keyword matching, see WithEncoderFallback.
+func (r *Router) shouldFallback(err error) bool {
+	return r.encoderFallback && errors.As(err, &ErrEncoding{})
+}
+
+// matchKeywords returns the route whose utterances share the most words with
+// the given utterance, ignoring embeddings.
+//
+// The score of a route is the best jaccard similarity between the words of
+// the utterance and the words of one of its utterances. Routes with fewer
+// utterances than the configured minimum, or whose required keywords the
+// utterance lacks, are skipped, and the threshold of the router applies as
+// when matching embeddings.
+func (r *Router) matchKeywords(
+	utterance string,
+) (bestRouteName string, bestScore float64, err error) {
+	query, words := keywordTokens(utterance), gateWords(utterance)
+	for _, route := range r.Routes {
+		if len(route.Utterances) < r.minUtterances || !route.passesKeywordGate(words) {
+			continue
+		}
+		for _, ut := range route.Utterances {
+			score := keywordScore(query, keywordTokens(ut.Utterance))
+			if score > bestScore {
+				bestRouteName, bestScore = route.Name, score
+			}
+		}
+	}
+	if bestRouteName == "" || bestScore < r.Threshold() {
+		return "", 0.0, ErrNoRouteFound
+	}
+	return bestRouteName, bestScore, nil
+}
@@ -0,0 +1,46 @@
+package semanticrouter
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/conneroisu/go-semantic-router/stores/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestWithEncoderFallback tests that Match falls back to keyword matching
+// while the encoder fails.
+func TestWithEncoderFallback(t *testing.T) {
+	ctx := context.Background()
+	encoder := &errEncoder{Encoder: newTestEncoder()}
+	router, err := NewRouter(
+		newTestRoutes(),
+		encoder,
+		memory.NewStore(),
+		WithEncoderFallback(true),
+	)
+	require.NoError(t, err)
+	encoder.err = errors.New("service unavailable")
+
+	route, score, err := router.Match(ctx, "Who will win the election?")
+	require.NoError(t, err)
+	assert.Equal(t, "politics", route)
+	assert.InDelta(t, 4.0/6.0, score, 1e-12)
+
+	_, _, err = router.Match(ctx, "no shared words")
+	assert.ErrorContains(t, err, "no route found")
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	encoder.err = canceled.Err()
+	_, _, err = router.Match(canceled, "who will win the vote?")
+	assert.ErrorIs(t, err, context.Canceled)
+
+	router, err = NewRouter(newTestRoutes(), newTestEncoder(), memory.NewStore())
+	require.NoError(t, err)
+	router.Encoder = &errEncoder{err: errors.New("service unavailable")}
+	_, _, err = router.Match(ctx, "who will win the vote?")
+	assert.ErrorAs(t, err, &ErrEncoding{})
+}
@@ -0,0 +1,78 @@
+package semanticrouter
+
+import (
+	"context"
+	"errors"
+)
+
+// MatchHierarchical matches the given utterance level by level through the
+// route hierarchy defined by Route.Parent.
+//
+// The utterance is first matched among the routes without a parent, then
+// among the children of the matched route, and so on until a route without
+// children is reached or none of the children match. The returned path holds
+// the names of the matched routes from the top-level route down, and score is
+// the score of the last one. Errors other than ErrNoRouteFound at any level
+// are returned.
+//
+// Each route is scored against its own utterances only, so a parent route
+// should have utterances covering its children. Each level is scored as by
+// ScoreAll, the reranker, if any, reranking the utterances of the level.
+func (r *Router) MatchHierarchical(
+	ctx context.Context,
+	utterance string,
+) (path []string, score float64, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	qs, err := r.encodeQueries(ctx, utterance)
+	if err != nil {
+		return nil, 0.0, err
+	}
+	index, err := r.loadIndex(ctx)
+	if err != nil {
+		return nil, 0.0, err
+	}
+	parent := ""
+	for {
+		level := r.childIndex(index, parent)
+		if len(level) == 0 {
+			break
+		}
+		ranked, err := r.rankIndex(ctx, utterance, qs, level, false)
+		if err != nil {
+			return nil, 0.0, err
+		}
+		route, s, err := r.bestRoute(ranked.scores)
+		if errors.Is(err, ErrNoRouteFound) && parent != "" {
+			break
+		}
+		if err != nil {
+			return nil, 0.0, err
+		}
+		path = append(path, route)
+		score = s
+		parent = route
+	}
+	if len(path) == 0 {
+		return nil, 0.0, ErrNoRouteFound
+	}
+	return path, score, nil
+}
+
+// childIndex returns the entries of the index belonging to the children of
+// the given parent route, or to top-level routes if parent is empty.
+func (r *Router) childIndex(index []indexEntry, parent string) []indexEntry {
+	children := make(map[string]bool)
+	for _, route := range r.Routes {
+		if route.Parent == parent {
+			children[route.Name] = true
+		}
+	}
+	var level []indexEntry
+	for _, entry := range index {
+		if children[entry.route] {
+			level = append(level, entry)
+		}
+	}
+	return level
+}
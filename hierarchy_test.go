@@ -0,0 +1,84 @@
+package semanticrouter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/conneroisu/go-semantic-router/domain"
+	"github.com/conneroisu/go-semantic-router/stores/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMatchHierarchical tests matching through a two-level route hierarchy.
+func TestMatchHierarchical(t *testing.T) {
+	ctx := context.Background()
+	encoder := &mockEncoder{embeddings: map[string][]float64{
+		"i have a billing question": {1.0, 0.0, 0.0, 0.0},
+		"refund my order":           {0.7, 0.7, 0.0, 0.0},
+		"send me the invoice":       {0.7, 0.0, 0.7, 0.0},
+		"hello there":               {0.0, 0.0, 0.0, 1.0},
+		"i want my money back":      {0.6, 0.8, 0.0, 0.0},
+		"hi":                        {0.1, 0.0, 0.0, 1.0},
+	}}
+	routes := []Route{
+		{
+			Name:       "billing",
+			Utterances: []domain.Utterance{{Utterance: "i have a billing question"}},
+		},
+		{
+			Name:       "billing.refund",
+			Parent:     "billing",
+			Utterances: []domain.Utterance{{Utterance: "refund my order"}},
+		},
+		{
+			Name:       "billing.invoice",
+			Parent:     "billing",
+			Utterances: []domain.Utterance{{Utterance: "send me the invoice"}},
+		},
+		{
+			Name:       "chitchat",
+			Utterances: []domain.Utterance{{Utterance: "hello there"}},
+		},
+	}
+	router, err := NewRouter(routes, encoder, memory.NewStore())
+	require.NoError(t, err)
+
+	path, score, err := router.MatchHierarchical(ctx, "i want my money back")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"billing", "billing.refund"}, path)
+	assert.Greater(t, score, 0.9)
+
+	path, _, err = router.MatchHierarchical(ctx, "hi")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"chitchat"}, path)
+
+	// Errors at a child level are returned instead of truncating the path.
+	encoder.embeddings["send me the invoice"] = []float64{0.7, 0.0, 0.7}
+	router, err = NewRouter(routes, encoder, memory.NewStore(), WithStrictDimensions(true))
+	require.NoError(t, err)
+	_, _, err = router.MatchHierarchical(ctx, "i want my money back")
+	assert.ErrorAs(t, err, &ErrDimensionMismatch{})
+}
+
+// TestValidateParents tests that unknown parents and cycles are rejected.
+func TestValidateParents(t *testing.T) {
+	utterances := []domain.Utterance{{Utterance: "hello"}}
+	err := validateRoutes([]Route{
+		{Name: "a", Parent: "missing", Utterances: utterances},
+	})
+	assert.ErrorContains(t, err, `route "a" has unknown parent: "missing"`)
+
+	err = validateRoutes([]Route{
+		{Name: "a", Parent: "b", Utterances: utterances},
+		{Name: "b", Parent: "a", Utterances: utterances},
+	})
+	assert.ErrorContains(t, err, `route "a" is its own ancestor`)
+	assert.ErrorContains(t, err, `route "b" is its own ancestor`)
+
+	err = validateRoutes([]Route{
+		{Name: "a", Utterances: utterances},
+		{Name: "b", Parent: "a", Utterances: utterances},
+	})
+	assert.NoError(t, err)
+}
@@ -0,0 +1,70 @@
+package semanticrouter
+
+import (
+	"context"
+	"fmt"
+	"math"
+)
+
+// MatchWithHistory is like Match, but adds a recency bonus to the aggregated
+// scores of the routes found in recentRoutes before the best route is
+// selected, so that routes used recently in a conversation win near-ties.
+//
+// recentRoutes is ordered from the oldest to the most recent route. The
+// bonus of a route decays with its position in the history: the most recent
+// route gets recencyWeight, the one before recencyWeight/2, and so on, a
+// route appearing several times getting the bonus of its most recent
+// appearance. Names of routes the router does not have are ignored. The
+// recency weight must be finite and non-negative, zero being equivalent to
+// Match. Routes are scored with route priors, if any, before the bonus is
+// added. As with MatchExact, every utterance is scored: the index of
+// WithSpatialIndex or WithHNSW, WithMaxCandidates and WithTwoStageScoring
+// are not used.
+func (r *Router) MatchWithHistory(
+	ctx context.Context,
+	utterance string,
+	recentRoutes []string,
+	recencyWeight float64,
+) (bestRouteName string, bestScore float64, err error) {
+	if math.IsNaN(recencyWeight) || math.IsInf(recencyWeight, 0) || recencyWeight < 0 {
+		return "", 0.0, fmt.Errorf("recency weight must be finite and non-negative, got %v", recencyWeight)
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.scoreDirection == LowerIsBetter && recencyWeight > 0 && len(recentRoutes) > 0 {
+		return "", 0.0, fmt.Errorf("lower-is-better scores do not support recency bonuses")
+	}
+	call := *r
+	call.recency = recencyBonuses(recentRoutes, recencyWeight)
+	return call.match(ctx, utterance, false)
+}
+
+// recencyBonuses returns the recency bonus of each route of the given
+// history, ordered from the oldest to the most recent route, see
+// MatchWithHistory.
+func recencyBonuses(recentRoutes []string, weight float64) map[string]float64 {
+	if weight == 0 || len(recentRoutes) == 0 {
+		return nil
+	}
+	bonuses := make(map[string]float64, len(recentRoutes))
+	for i := len(recentRoutes) - 1; i >= 0; i-- {
+		name := recentRoutes[i]
+		if _, ok := bonuses[name]; ok {
+			continue
+		}
+		age := len(recentRoutes) - i
+		bonuses[name] = weight / float64(age)
+	}
+	return bonuses
+}
+
+// applyRecency adds the recency bonuses of the routes to the given
+// aggregated scores.
+func (r *Router) applyRecency(scores []routeScore) {
+	if len(r.recency) == 0 {
+		return
+	}
+	for i := range scores {
+		scores[i].score += r.recency[scores[i].route]
+	}
+}
@@ -0,0 +1,56 @@
+package semanticrouter
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/conneroisu/go-semantic-router/stores/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMatchWithHistory tests that a recency bonus flips the winner of a
+// near-tie, decaying with the position of the route in the history.
+func TestMatchWithHistory(t *testing.T) {
+	ctx := context.Background()
+	encoder := newTestEncoder()
+	encoder.embeddings["borderline"] = []float64{0.5, 0.5, 0.0}
+
+	router, err := NewRouter(newTestRoutes(), encoder, memory.NewStore())
+	require.NoError(t, err)
+	scores, err := router.ScoreAll(ctx, "borderline")
+	require.NoError(t, err)
+	gap := scores["chitchat"] - scores["politics"]
+	require.Greater(t, gap, 0.0)
+
+	route, _, err := router.MatchWithHistory(ctx, "borderline", nil, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "chitchat", route)
+
+	weight := 1.5 * gap
+	route, score, err := router.MatchWithHistory(ctx, "borderline", []string{"politics"}, weight)
+	require.NoError(t, err)
+	assert.Equal(t, "politics", route)
+	assert.InDelta(t, scores["politics"]+weight, score, 1e-12)
+
+	// One turn older, the bonus is halved and no longer bridges the gap.
+	route, score, err = router.MatchWithHistory(ctx, "borderline", []string{"politics", "unknown"}, weight)
+	require.NoError(t, err)
+	assert.Equal(t, "chitchat", route)
+	assert.InDelta(t, scores["chitchat"], score, 1e-12)
+
+	// The most recent appearance of a route counts.
+	route, _, err = router.MatchWithHistory(ctx, "borderline", []string{"politics", "unknown", "politics"}, weight)
+	require.NoError(t, err)
+	assert.Equal(t, "politics", route)
+
+	route, _, err = router.Match(ctx, "borderline")
+	require.NoError(t, err)
+	assert.Equal(t, "chitchat", route)
+
+	for _, weight := range []float64{-1, math.NaN(), math.Inf(1)} {
+		_, _, err = router.MatchWithHistory(ctx, "borderline", []string{"politics"}, weight)
+		assert.ErrorContains(t, err, "recency weight")
+	}
+}
@@ -0,0 +1,214 @@
+package semanticrouter
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// hnswParams are the parameters of an HNSW index, see WithHNSW.
+type hnswParams struct {
+	m              int // m is the number of neighbors of a node in the upper layers, twice as many in the bottom layer.
+	efConstruction int // efConstruction is the size of the candidate list when inserting a node.
+	efSearch       int // efSearch is the size of the candidate list when searching.
+}
+
+// hnswNode is a node of an HNSW graph: a normalized index embedding along
+// with its neighbors in each layer it belongs to.
+type hnswNode struct {
+	vec       []float64
+	pos       int     // pos is the position of the entry of the node in the index.
+	neighbors [][]int // neighbors are the nodes linked to the node, per layer.
+}
+
+// hnswCandidate is a node of an HNSW graph along with its distance to the
+// node being searched.
+type hnswCandidate struct {
+	node int
+	dist float64
+}
+
+// hnswIndex is an approximate nearest neighbor index of the normalized
+// embeddings of the utterances of a router, implementing hierarchical
+// navigable small world graphs.
+//
+// Nodes are compared by cosine distance, one minus their dot product.
+type hnswIndex struct {
+	params   hnswParams
+	dim      int
+	nodes    []hnswNode
+	entry    int     // entry is the node searches start from, -1 if the graph is empty.
+	maxLevel int     // maxLevel is the top layer of the graph.
+	levelMul float64 // levelMul normalizes the random layers of the nodes.
+	rnd      *rand.Rand
+	entries  []indexEntry // entries are the entries of the index the nodes refer to.
+}
+
+// newHNSWIndex creates an HNSW index over the dense embeddings of the given
+// index entries.
+//
+// Entries with a zero norm, which never match, are left out. It returns nil
+// if the entries do not all have the same dimension. Layers are drawn from a
+// fixed seed, so identical indexes build identical graphs.
+func newHNSWIndex(index []indexEntry, params hnswParams) *hnswIndex {
+	h := &hnswIndex{
+		params:   params,
+		entry:    -1,
+		levelMul: 1 / math.Log(float64(params.m)),
+		rnd:      rand.New(rand.NewSource(1)),
+		entries:  index,
+	}
+	for pos, entry := range index {
+		if pos == 0 {
+			h.dim = entry.vec.Len()
+		}
+		if entry.vec.Len() != h.dim {
+			return nil
+		}
+		if entry.norm == 0 {
+			continue
+		}
+		h.insert(unitVector(entry.vec, entry.norm), pos)
+	}
+	return h
+}
+
+// dimension returns the dimension of the indexed embeddings.
+func (h *hnswIndex) dimension() int {
+	return h.dim
+}
+
+// distance returns the cosine distance between the given unit vector and the
+// vector of the given node.
+func (h *hnswIndex) distance(vec []float64, node int) float64 {
+	var dot float64
+	for i, v := range h.nodes[node].vec {
+		dot += v * vec[i]
+	}
+	return 1 - dot
+}
+
+// maxNeighbors returns the maximum number of neighbors of a node in the given
+// layer.
+func (h *hnswIndex) maxNeighbors(layer int) int {
+	if layer == 0 {
+		return 2 * h.params.m
+	}
+	return h.params.m
+}
+
+// insert inserts the given unit vector of the entry at position pos into the
+// graph.
+func (h *hnswIndex) insert(vec []float64, pos int) {
+	level := int(-math.Log(1-h.rnd.Float64()) * h.levelMul)
+	node := len(h.nodes)
+	h.nodes = append(h.nodes, hnswNode{
+		vec:       vec,
+		pos:       pos,
+		neighbors: make([][]int, level+1),
+	})
+	if h.entry == -1 {
+		h.entry, h.maxLevel = node, level
+		return
+	}
+	eps := []hnswCandidate{{node: h.entry, dist: h.distance(vec, h.entry)}}
+	for layer := h.maxLevel; layer > level; layer-- {
+		eps = h.searchLayer(vec, eps, 1, layer)
+	}
+	for layer := min(level, h.maxLevel); layer >= 0; layer-- {
+		eps = h.searchLayer(vec, eps, h.params.efConstruction, layer)
+		for _, c := range eps[:min(h.params.m, len(eps))] {
+			h.nodes[node].neighbors[layer] = append(h.nodes[node].neighbors[layer], c.node)
+			h.link(c.node, node, layer)
+		}
+	}
+	if level > h.maxLevel {
+		h.entry, h.maxLevel = node, level
+	}
+}
+
+// link adds a link from the given node to the given neighbor in the given
+// layer, keeping only its nearest neighbors when it has too many.
+func (h *hnswIndex) link(node, neighbor, layer int) {
+	neighbors := append(h.nodes[node].neighbors[layer], neighbor)
+	if len(neighbors) > h.maxNeighbors(layer) {
+		vec := h.nodes[node].vec
+		sort.SliceStable(neighbors, func(i, j int) bool {
+			return h.distance(vec, neighbors[i]) < h.distance(vec, neighbors[j])
+		})
+		neighbors = neighbors[:h.maxNeighbors(layer)]
+	}
+	h.nodes[node].neighbors[layer] = neighbors
+}
+
+// searchLayer returns the ef nodes of the given layer nearest to vec found
+// by a greedy search from the given entry points, sorted by distance.
+func (h *hnswIndex) searchLayer(
+	vec []float64,
+	eps []hnswCandidate,
+	ef int,
+	layer int,
+) []hnswCandidate {
+	visited := make(map[int]bool, ef)
+	candidates := make([]hnswCandidate, 0, ef)
+	results := make([]hnswCandidate, 0, ef+1)
+	for _, ep := range eps {
+		visited[ep.node] = true
+		candidates = insertCandidate(candidates, ep)
+		results = insertCandidate(results, ep)
+	}
+	if len(results) > ef {
+		results = results[:ef]
+	}
+	for len(candidates) > 0 {
+		c := candidates[0]
+		candidates = candidates[1:]
+		if len(results) >= ef && c.dist > results[len(results)-1].dist {
+			break
+		}
+		for _, neighbor := range h.nodes[c.node].neighbors[layer] {
+			if visited[neighbor] {
+				continue
+			}
+			visited[neighbor] = true
+			d := h.distance(vec, neighbor)
+			if len(results) >= ef && d >= results[len(results)-1].dist {
+				continue
+			}
+			next := hnswCandidate{node: neighbor, dist: d}
+			candidates = insertCandidate(candidates, next)
+			results = insertCandidate(results, next)
+			if len(results) > ef {
+				results = results[:ef]
+			}
+		}
+	}
+	return results
+}
+
+// insertCandidate inserts the candidate into the given candidates, sorted by
+// distance.
+func insertCandidate(candidates []hnswCandidate, c hnswCandidate) []hnswCandidate {
+	i := sort.Search(len(candidates), func(i int) bool {
+		return candidates[i].dist > c.dist
+	})
+	candidates = append(candidates, hnswCandidate{})
+	copy(candidates[i+1:], candidates[i:])
+	candidates[i] = c
+	return candidates
+}
+
+// nearest returns the entry whose embedding is approximately the most
+// similar to the given query, and false if the index has none.
+func (h *hnswIndex) nearest(q query) (indexEntry, bool) {
+	if h.entry == -1 {
+		return indexEntry{}, false
+	}
+	vec := unitVector(q.vec, q.norm)
+	eps := []hnswCandidate{{node: h.entry, dist: h.distance(vec, h.entry)}}
+	for layer := h.maxLevel; layer > 0; layer-- {
+		eps = h.searchLayer(vec, eps, 1, layer)
+	}
+	eps = h.searchLayer(vec, eps, h.params.efSearch, 0)
+	return h.entries[h.nodes[eps[0].node].pos], true
+}
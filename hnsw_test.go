@@ -0,0 +1,77 @@
+package semanticrouter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/conneroisu/go-semantic-router/stores/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestWithHNSW tests the recall of the HNSW index against the exact nearest
+// utterances of a synthetic dataset.
+func TestWithHNSW(t *testing.T) {
+	ctx := context.Background()
+	routes, encoder := newRandomRoutes(40, 50, 32)
+	queries := addRandomQueries(encoder, 200, 32)
+	router, err := NewRouter(
+		routes,
+		encoder,
+		memory.NewStore(),
+		WithHNSW(16, 200, 50),
+	)
+	require.NoError(t, err)
+	require.IsType(t, &hnswIndex{}, router.spatial)
+	index, err := router.loadIndex(ctx)
+	require.NoError(t, err)
+	exact := newSpatialIndex(index)
+
+	var utteranceHits, routeHits int
+	for _, query := range queries {
+		qs, err := router.encodeQueries(ctx, query)
+		require.NoError(t, err)
+		want, ok := exact.nearest(qs[0])
+		require.True(t, ok)
+		got, ok := router.spatial.nearest(qs[0])
+		require.True(t, ok)
+		if got.utterance == want.utterance {
+			utteranceHits++
+		}
+
+		wantRoute, _, err := router.MatchExact(ctx, query)
+		require.NoError(t, err)
+		gotRoute, _, err := router.Match(ctx, query)
+		require.NoError(t, err)
+		if gotRoute == wantRoute {
+			routeHits++
+		}
+	}
+	recall := float64(utteranceHits) / float64(len(queries))
+	t.Logf("utterance recall: %.3f, route recall: %.3f",
+		recall, float64(routeHits)/float64(len(queries)))
+	assert.GreaterOrEqual(t, recall, 0.95)
+	assert.GreaterOrEqual(t, routeHits, utteranceHits)
+
+	_, err = NewRouter(routes, encoder, memory.NewStore(), WithHNSW(1, 200, 50))
+	assert.ErrorContains(t, err, "invalid HNSW parameters")
+}
+
+// BenchmarkHNSW compares matching by scanning the index and with the HNSW
+// index.
+func BenchmarkHNSW(b *testing.B) {
+	ctx := context.Background()
+	routes, encoder := newRandomRoutes(50, 100, 64)
+	router, err := NewRouter(routes, encoder, memory.NewStore(), WithHNSW(16, 200, 50))
+	require.NoError(b, err)
+	b.Run("exact", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_, _, _ = router.MatchExact(ctx, "query")
+		}
+	})
+	b.Run("hnsw", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_, _, _ = router.Match(ctx, "query")
+		}
+	})
+}
@@ -0,0 +1,62 @@
+package semanticrouter
+
+import (
+	"sync"
+	"time"
+)
+
+// indexCache caches the index entries loaded from the store, each until it
+// is older than a time to live, see WithCacheTTL.
+type indexCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]cachedEntry
+}
+
+// cachedEntry is an index entry of an indexCache along with when it was
+// loaded from the store.
+type cachedEntry struct {
+	entry    indexEntry
+	loadedAt time.Time
+}
+
+// newIndexCache creates a new indexCache keeping entries for the given time
+// to live.
+func newIndexCache(ttl time.Duration) *indexCache {
+	return &indexCache{ttl: ttl, entries: make(map[string]cachedEntry)}
+}
+
+// get returns the entry cached for the given key, unless it is missing or
+// older than the time to live at the given time.
+func (c *indexCache) get(key string, now time.Time) (indexEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cached, ok := c.entries[key]
+	if !ok || now.Sub(cached.loadedAt) >= c.ttl {
+		return indexEntry{}, false
+	}
+	return cached.entry, true
+}
+
+// put caches the given entry for the given key, loaded at the given time.
+func (c *indexCache) put(key string, entry indexEntry, loadedAt time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = cachedEntry{entry: entry, loadedAt: loadedAt}
+}
+
+// invalidate removes the entry cached for the given key, if any, so that it
+// is loaded again from the store.
+func (c *indexCache) invalidate(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, key)
+}
+
+// invalidateEntry removes the cached index entry of the given utterance, if
+// the router caches index entries, once its embedding is stored or deleted.
+func (r *Router) invalidateEntry(utterance string) {
+	if r.indexCache != nil {
+		r.indexCache.invalidate(r.key(utterance))
+	}
+}
@@ -0,0 +1,62 @@
+package semanticrouter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/conneroisu/go-semantic-router/domain"
+	"github.com/conneroisu/go-semantic-router/stores/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestWithCacheTTL tests that cached index embeddings are served without
+// fetching them from the store until they are older than the time to live,
+// and then refreshed from the store.
+func TestWithCacheTTL(t *testing.T) {
+	ctx := context.Background()
+	const ttl = 50 * time.Millisecond
+	inner := memory.NewStore()
+	store := &countingStore{store: inner}
+	router, err := NewRouter(newTestRoutes(), newTestEncoder(), store, WithCacheTTL(ttl))
+	require.NoError(t, err)
+
+	_, _, err = router.Match(ctx, "is it raining outside?")
+	require.NoError(t, err)
+	loaded := store.gets
+	assert.Equal(t, 4, loaded)
+	_, _, err = router.Match(ctx, "is it raining outside?")
+	require.NoError(t, err)
+	assert.Equal(t, loaded, store.gets)
+
+	// Another process re-encodes an utterance of the chitchat route so that
+	// the query now matches the politics route.
+	for _, utterance := range []string{"how's the weather today?", "lovely weather today"} {
+		utter := domain.Utterance{Utterance: utterance}
+		require.NoError(t, utter.SetEmbedding([]float64{0.0, 0.0, 1.0}))
+		require.NoError(t, inner.Store(ctx, utter))
+	}
+	route, _, err := router.Match(ctx, "is it raining outside?")
+	require.NoError(t, err)
+	assert.Equal(t, "chitchat", route, "stale embeddings were refreshed early")
+
+	time.Sleep(ttl)
+	route, _, err = router.Match(ctx, "is it raining outside?")
+	require.NoError(t, err)
+	assert.Equal(t, 2*loaded, store.gets)
+	assert.Equal(t, "chitchat", route)
+	scores, err := router.ScoreAll(ctx, "is it raining outside?")
+	require.NoError(t, err)
+	assert.InDelta(t, 0.1/0.806225774829855, scores["chitchat"], 1e-9)
+
+	// Embeddings stored through the router are refreshed immediately.
+	require.NoError(t, router.AddUtterances(ctx, "politics", domain.Utterance{Utterance: "is it raining outside?"}))
+	route, score, err := router.Match(ctx, "is it raining outside?")
+	require.NoError(t, err)
+	assert.Equal(t, "politics", route)
+	assert.InDelta(t, 1.0, score, 1e-9)
+
+	_, err = NewRouter(newTestRoutes(), newTestEncoder(), memory.NewStore(), WithCacheTTL(0))
+	assert.Error(t, err)
+}
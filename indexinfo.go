@@ -0,0 +1,104 @@
+package semanticrouter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// IndexInfoKey is the reserved key under which NewRouter stores the
+// IndexInfo of the router in a MetadataStore.
+const IndexInfoKey = "semanticrouter:index"
+
+// MetadataStore is a Store that can also store metadata records, such as
+// the IndexInfo of the routers built on it.
+//
+// Metadata is optional: the memory and valkey stores implement it. With
+// other stores, NewRouter does not record its IndexInfo and
+// Router.IndexInfo fails with an error wrapping ErrNotSupported.
+type MetadataStore interface {
+	Store
+	// StoreMetadata stores the given value under the given key.
+	StoreMetadata(ctx context.Context, key string, value []byte) error
+	// GetMetadata returns the value stored under the given key.
+	GetMetadata(ctx context.Context, key string) ([]byte, error)
+}
+
+// IndexInfo describes how the embeddings of a router's store were built, so
+// that an index built by another version or configuration of the router can
+// be detected, for instance by comparing it to the expected one on startup.
+type IndexInfo struct {
+	Encoder    string       `json:"encoder"`    // Encoder is the Go type of the encoder, such as "*openai.Encoder".
+	Embeddings string       `json:"embeddings"` // Embeddings is the kind of embeddings: "dense", "sparse" or "multi-vector".
+	Dimension  int          `json:"dimension"`  // Dimension is the dimension of the dense embeddings, zero if unknown.
+	Prefix     string       `json:"prefix"`     // Prefix is the document prefix of the utterances, see WithDocumentPrefix.
+	Config     RouterConfig `json:"config"`     // Config is the scoring configuration of the router.
+	BuiltAt    time.Time    `json:"built_at"`   // BuiltAt is when the router was built.
+}
+
+// newIndexInfo returns the IndexInfo of the router, built at the given time.
+func (r *Router) newIndexInfo(
+	ctx context.Context,
+	builtAt time.Time,
+) (IndexInfo, error) {
+	dimension, err := r.expectedDimension(ctx)
+	if err != nil {
+		return IndexInfo{}, err
+	}
+	return IndexInfo{
+		Encoder:    fmt.Sprintf("%T", r.Encoder),
+		Embeddings: r.embeddings.String(),
+		Dimension:  dimension,
+		Prefix:     r.documentPrefix,
+		Config:     r.ExportConfig(),
+		BuiltAt:    builtAt.UTC(),
+	}, nil
+}
+
+// storeIndexInfo stores the IndexInfo of the router under IndexInfoKey, if
+// the router's store is a MetadataStore.
+func (r *Router) storeIndexInfo(ctx context.Context) error {
+	store, ok := r.Storage.(MetadataStore)
+	if !ok {
+		return nil
+	}
+	info, err := r.newIndexInfo(ctx, time.Now())
+	if err != nil {
+		return fmt.Errorf("error storing index info: %w", err)
+	}
+	value, err := json.Marshal(info)
+	if err != nil {
+		return fmt.Errorf("error marshaling index info: %w", err)
+	}
+	callCtx, cancel := r.callContext(ctx)
+	defer cancel()
+	err = store.StoreMetadata(callCtx, r.key(IndexInfoKey), value)
+	if err != nil {
+		return fmt.Errorf("error storing index info: %w", callError(callCtx, err))
+	}
+	return nil
+}
+
+// IndexInfo returns the IndexInfo stored in the router's store when the
+// router, or the last router built on the same store, was built.
+//
+// The store must implement MetadataStore.
+func (r *Router) IndexInfo(ctx context.Context) (IndexInfo, error) {
+	store, ok := r.Storage.(MetadataStore)
+	if !ok {
+		return IndexInfo{}, fmt.Errorf("error getting index info: store %T does not implement MetadataStore: %w", r.Storage, ErrNotSupported)
+	}
+	callCtx, cancel := r.callContext(ctx)
+	defer cancel()
+	value, err := store.GetMetadata(callCtx, r.key(IndexInfoKey))
+	if err != nil {
+		return IndexInfo{}, fmt.Errorf("error getting index info: %w", callError(callCtx, err))
+	}
+	var info IndexInfo
+	err = json.Unmarshal(value, &info)
+	if err != nil {
+		return IndexInfo{}, fmt.Errorf("error unmarshaling index info: %w", err)
+	}
+	return info, nil
+}
@@ -0,0 +1,43 @@
+package semanticrouter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/conneroisu/go-semantic-router/stores/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestIndexInfo tests that the index info written by NewRouter round-trips
+// through the memory store and survives pruning the store.
+func TestIndexInfo(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewStore()
+	before := time.Now()
+	router, err := NewRouter(newTestRoutes(), newTestEncoder(), store, WithDotProduct(0.5))
+	require.NoError(t, err)
+
+	info, err := router.IndexInfo(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "*semanticrouter.mockEncoder", info.Encoder)
+	assert.Equal(t, "dense", info.Embeddings)
+	assert.Equal(t, 3, info.Dimension)
+	assert.Equal(t, RouterConfig{
+		Similarities: []SimilaritySpec{{Name: SimilarityDotProduct, Coefficient: 0.5}},
+	}, info.Config)
+	assert.WithinRange(t, info.BuiltAt, before.Add(-time.Second), time.Now())
+
+	_, err = router.PruneStore(ctx, func(string) bool { return true })
+	require.NoError(t, err)
+	again, err := router.IndexInfo(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, info, again)
+
+	// Stores which cannot store metadata are not supported.
+	router, err = NewRouter(newTestRoutes(), newTestEncoder(), &countingStore{store: memory.NewStore()})
+	require.NoError(t, err)
+	_, err = router.IndexInfo(ctx)
+	assert.ErrorIs(t, err, ErrNotSupported)
+}
@@ -0,0 +1,43 @@
+package semanticrouter
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// NormalizeKey returns the normalized form of the given utterance used as a
+// key when the router uses WithKeyNormalization.
+//
+// The utterance is decomposed into its NFKD compatibility form, stripped of
+// combining marks, such as accents, and lowercased.
+func NormalizeKey(utterance string) string {
+	var b strings.Builder
+	b.Grow(len(utterance))
+	for _, c := range norm.NFKD.String(utterance) {
+		if unicode.Is(unicode.Mn, c) {
+			continue
+		}
+		b.WriteRune(unicode.ToLower(c))
+	}
+	return b.String()
+}
+
+// key returns the key of the given utterance in the store and the query
+// cache.
+//
+// The keys of route centroids and IndexInfoKey are reserved keys, which are
+// never normalized, as route names are case sensitive.
+func (r *Router) key(utterance string) string {
+	if !r.normalizeKeys || isReservedKey(utterance) {
+		return utterance
+	}
+	return NormalizeKey(utterance)
+}
+
+// isReservedKey reports whether the given key is the key of a route
+// centroid or IndexInfoKey.
+func isReservedKey(key string) bool {
+	return key == IndexInfoKey || strings.HasPrefix(key, centroidKeyPrefix)
+}
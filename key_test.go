@@ -0,0 +1,117 @@
+package semanticrouter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/conneroisu/go-semantic-router/domain"
+	"github.com/conneroisu/go-semantic-router/stores/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNormalizeKey tests that utterances differing in case, diacritics or
+// compatibility forms have the same key.
+func TestNormalizeKey(t *testing.T) {
+	for _, utterance := range []string{"café", "Cafe", "CAFÉ", "café", "ｃａｆｅ"} {
+		assert.Equal(t, "cafe", NormalizeKey(utterance), utterance)
+	}
+	assert.NotEqual(t, NormalizeKey("cafe"), NormalizeKey("cafes"))
+}
+
+// TestWithKeyNormalization tests that normalized keys are used in the store
+// and the query cache, while utterances are reported unmodified.
+func TestWithKeyNormalization(t *testing.T) {
+	ctx := context.Background()
+	base := newTestEncoder()
+	base.embeddings["Café au lait"] = []float64{0.2, 0.0, 1.0}
+	base.embeddings["CAFE AU LAIT"] = []float64{0.2, 0.0, 1.0}
+	base.embeddings["café au lait"] = []float64{0.2, 0.0, 1.0}
+	encoder := &countingEncoder{Encoder: base}
+	store := memory.NewStore()
+	routes := append(newTestRoutes(), Route{
+		Name:       "coffee",
+		Utterances: []domain.Utterance{{Utterance: "Café au lait"}},
+	})
+	router, err := NewRouter(
+		routes,
+		encoder,
+		store,
+		WithKeyNormalization(true),
+		WithQueryCache(10),
+	)
+	require.NoError(t, err)
+
+	em, err := store.Get(ctx, "cafe au lait")
+	require.NoError(t, err)
+	assert.Equal(t, []float64{0.2, 0.0, 1.0}, em)
+	_, err = store.Get(ctx, "Café au lait")
+	assert.Error(t, err)
+
+	encoder.calls.Store(0)
+	match, err := router.MatchVerbose(ctx, "CAFE AU LAIT")
+	require.NoError(t, err)
+	assert.Equal(t, "coffee", match.Route)
+	assert.Equal(t, "Café au lait", match.TopUtterances[0].Utterance)
+	route, _, err := router.Match(ctx, "café au lait")
+	require.NoError(t, err)
+	assert.Equal(t, "coffee", route)
+	assert.EqualValues(t, 1, encoder.calls.Load())
+}
+
+// TestKeyNormalizationUpdateRoute tests that removing an utterance from a
+// route keeps the embedding it shares with a normalization-equal utterance
+// of another route.
+func TestKeyNormalizationUpdateRoute(t *testing.T) {
+	ctx := context.Background()
+	encoder := newTestEncoder()
+	encoder.embeddings["Café"] = []float64{0.2, 0.0, 1.0}
+	encoder.embeddings["cafe"] = []float64{0.2, 0.0, 1.0}
+	routes := append(newTestRoutes(), Route{
+		Name:       "coffee",
+		Utterances: []domain.Utterance{{Utterance: "cafe"}},
+	})
+	routes[0].Utterances = append(routes[0].Utterances, domain.Utterance{Utterance: "Café"})
+	router, err := NewRouter(routes, encoder, memory.NewStore(), WithKeyNormalization(true))
+	require.NoError(t, err)
+
+	_, err = router.UpdateRoute(ctx, "chitchat", routes[0].Utterances[:2])
+	require.NoError(t, err)
+	route, _, err := router.Match(ctx, "cafe")
+	require.NoError(t, err)
+	assert.Equal(t, "coffee", route)
+
+	_, err = router.SetRoutes(ctx, newTestRoutes()[:1])
+	require.NoError(t, err)
+	route, _, err = router.Match(ctx, "is it raining outside?")
+	require.NoError(t, err)
+	assert.Equal(t, "chitchat", route)
+}
+
+// TestKeyNormalizationCentroids tests that the centroids of routes whose
+// names only differ in case are stored apart.
+func TestKeyNormalizationCentroids(t *testing.T) {
+	ctx := context.Background()
+	routes := newTestRoutes()
+	routes[0].Name, routes[1].Name = "Billing", "billing"
+	store := memory.NewStore()
+	router, err := NewRouter(
+		routes,
+		newTestEncoder(),
+		store,
+		WithKeyNormalization(true),
+		WithRouteCentroids(true),
+	)
+	require.NoError(t, err)
+	_, err = store.Get(ctx, CentroidKey("Billing"))
+	require.NoError(t, err)
+	_, err = store.Get(ctx, CentroidKey("billing"))
+	require.NoError(t, err)
+
+	route, _, err := router.Match(ctx, "is it raining outside?")
+	require.NoError(t, err)
+	assert.Equal(t, "Billing", route)
+	route, _, err = router.Match(ctx, "what about the election?")
+	require.NoError(t, err)
+	assert.Equal(t, "billing", route)
+}
@@ -0,0 +1,72 @@
+package semanticrouter
+
+import (
+	"strings"
+	"unicode"
+)
+
+// gateWords returns the lowercase words of the given text separated and
+// surrounded by single spaces, so that keywords of one or more words can be
+// searched for as whole words.
+func gateWords(text string) string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(c rune) bool {
+		return !unicode.IsLetter(c) && !unicode.IsNumber(c)
+	})
+	return " " + strings.Join(words, " ") + " "
+}
+
+// passesKeywordGate reports whether a query whose words, see gateWords, are
+// given contains the required keywords of the route: one of them, or all of
+// them if the route sets RequireAllKeywords. Routes without required
+// keywords always pass.
+func (route Route) passesKeywordGate(words string) bool {
+	if len(route.RequiredKeywords) == 0 {
+		return true
+	}
+	for _, keyword := range route.RequiredKeywords {
+		found := strings.Contains(words, gateWords(keyword))
+		if found && !route.RequireAllKeywords {
+			return true
+		}
+		if !found && route.RequireAllKeywords {
+			return false
+		}
+	}
+	return route.RequireAllKeywords
+}
+
+// hasKeywordGates reports whether any route of the router has required
+// keywords.
+func (r *Router) hasKeywordGates() bool {
+	for _, route := range r.Routes {
+		if len(route.RequiredKeywords) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// gateIndex returns the entries of the index whose route passes its keyword
+// gate for the given query utterance.
+func (r *Router) gateIndex(utterance string, index []indexEntry) []indexEntry {
+	if !r.hasKeywordGates() {
+		return index
+	}
+	words := gateWords(utterance)
+	blocked := make(map[string]bool)
+	for _, route := range r.Routes {
+		if !route.passesKeywordGate(words) {
+			blocked[route.Name] = true
+		}
+	}
+	if len(blocked) == 0 {
+		return index
+	}
+	gated := make([]indexEntry, 0, len(index))
+	for _, entry := range index {
+		if !blocked[entry.route] {
+			gated = append(gated, entry)
+		}
+	}
+	return gated
+}
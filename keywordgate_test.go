@@ -0,0 +1,80 @@
+package semanticrouter
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/conneroisu/go-semantic-router/stores/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRequiredKeywords tests that routes with required keywords only match
+// queries containing them.
+func TestRequiredKeywords(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name     string
+		keywords []string
+		all      bool
+		query    string
+		expected string
+	}{
+		{name: "passing", keywords: []string{"rain", "raining"}, query: "is it raining outside?", expected: "chitchat"},
+		{name: "blocking", keywords: []string{"weather"}, query: "is it raining outside?", expected: "politics"},
+		{name: "whole words", keywords: []string{"rain"}, query: "is it raining outside?", expected: "politics"},
+		{name: "phrase", keywords: []string{"RAINING outside"}, query: "is it raining outside?", expected: "chitchat"},
+		{name: "all passing", keywords: []string{"raining", "outside"}, all: true, query: "is it raining outside?", expected: "chitchat"},
+		{name: "all blocking", keywords: []string{"raining", "weather"}, all: true, query: "is it raining outside?", expected: "politics"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			routes := newTestRoutes()
+			routes[0].RequiredKeywords = tt.keywords
+			routes[0].RequireAllKeywords = tt.all
+			router, err := NewRouter(routes, newTestEncoder(), memory.NewStore())
+			require.NoError(t, err)
+			route, _, err := router.Match(ctx, tt.query)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, route)
+
+			results, err := router.MatchN(ctx, tt.query, 2)
+			require.NoError(t, err)
+			require.NotEmpty(t, results)
+			assert.Equal(t, tt.expected, results[0].Route)
+			scores, err := router.ScoreAll(ctx, tt.query)
+			require.NoError(t, err)
+			for name, score := range scores {
+				assert.LessOrEqual(t, score, scores[tt.expected], name)
+			}
+			exp, err := router.ExplainAgainst(ctx, tt.query, "politics")
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, exp.WinningRoute)
+		})
+	}
+
+	t.Run("no route", func(t *testing.T) {
+		routes := newTestRoutes()
+		for i := range routes {
+			routes[i].RequiredKeywords = []string{"refund"}
+		}
+		router, err := NewRouter(routes, newTestEncoder(), memory.NewStore(), WithSpatialIndex(true))
+		require.NoError(t, err)
+		_, _, err = router.Match(ctx, "is it raining outside?")
+		assert.ErrorIs(t, err, ErrNoRouteFound)
+	})
+
+	t.Run("encoder fallback", func(t *testing.T) {
+		routes := newTestRoutes()
+		for i := range routes {
+			routes[i].RequiredKeywords = []string{"refund"}
+		}
+		encoder := &errEncoder{Encoder: newTestEncoder()}
+		router, err := NewRouter(routes, encoder, memory.NewStore(), WithEncoderFallback(true))
+		require.NoError(t, err)
+		encoder.err = errors.New("service unavailable")
+		_, _, err = router.Match(ctx, "Who will win the election?")
+		assert.ErrorIs(t, err, ErrNoRouteFound)
+	})
+}
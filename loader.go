@@ -0,0 +1,99 @@
+package semanticrouter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LoadRoutesFromDir reads the routes defined by the .yaml, .yml and .json
+// files of the given directory, such as one file per intent.
+//
+// Each file holds either a single route or a list of routes. Files are read
+// in the lexical order of their names, and the routes of each file are kept
+// in order. Other files and subdirectories are ignored. It returns an error
+// if a file cannot be parsed or if two routes have the same name; the
+// routes are otherwise validated by NewRouter.
+func LoadRoutesFromDir(dir string) ([]Route, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("error reading routes directory: %w", err)
+	}
+	var routes []Route
+	files := make(map[string]string)
+	for _, entry := range entries {
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		if entry.IsDir() || ext != ".yaml" && ext != ".yml" && ext != ".json" {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		fileRoutes, err := loadRoutesFile(path, ext == ".json")
+		if err != nil {
+			return nil, err
+		}
+		for _, route := range fileRoutes {
+			if file, ok := files[route.Name]; ok {
+				return nil, fmt.Errorf("duplicate route name: %q in %s and %s", route.Name, file, path)
+			}
+			files[route.Name] = path
+		}
+		routes = append(routes, fileRoutes...)
+	}
+	return routes, nil
+}
+
+// loadRoutesFile reads the route or the list of routes of the given YAML or
+// JSON file.
+func loadRoutesFile(path string, isJSON bool) ([]Route, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading routes file: %w", err)
+	}
+	var routes []Route
+	if isJSON {
+		routes, err = decodeJSONRoutes(data)
+	} else {
+		routes, err = decodeYAMLRoutes(data)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error parsing routes file %s: %w", path, err)
+	}
+	return routes, nil
+}
+
+// decodeJSONRoutes decodes the JSON route or array of routes of data.
+func decodeJSONRoutes(data []byte) ([]Route, error) {
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		var routes []Route
+		err := json.Unmarshal(data, &routes)
+		return routes, err
+	}
+	var route Route
+	err := json.Unmarshal(data, &route)
+	return []Route{route}, err
+}
+
+// decodeYAMLRoutes decodes the YAML route or sequence of routes of data.
+func decodeYAMLRoutes(data []byte) ([]Route, error) {
+	var doc yaml.Node
+	err := yaml.Unmarshal(data, &doc)
+	if err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 {
+		return nil, fmt.Errorf("no route defined")
+	}
+	if doc.Content[0].Kind == yaml.SequenceNode {
+		var routes []Route
+		err = doc.Content[0].Decode(&routes)
+		return routes, err
+	}
+	var route Route
+	err = doc.Content[0].Decode(&route)
+	return []Route{route}, err
+}
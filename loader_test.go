@@ -0,0 +1,97 @@
+package semanticrouter
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/conneroisu/go-semantic-router/stores/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeRouteFiles writes the given files into a temporary directory and
+// returns it.
+func writeRouteFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+	}
+	return dir
+}
+
+// TestLoadRoutesFromDir tests that routes are read from a directory holding
+// single routes and lists of routes in YAML and JSON.
+func TestLoadRoutesFromDir(t *testing.T) {
+	dir := writeRouteFiles(t, map[string]string{
+		"chitchat.yaml": `name: chitchat
+utterances:
+  - utterance: how's the weather today?
+  - utterance: lovely weather today
+required_keywords: [weather]
+`,
+		"politics.json": `{
+  "name": "politics",
+  "utterances": [
+    {"utterance": "who will win the vote?", "tags": ["news"]},
+    {"utterance": "i love the president"}
+  ]
+}`,
+		"more.yml": `- name: billing
+  utterances:
+    - utterance: pay bill
+- name: refunds
+  parent: billing
+  utterances:
+    - utterance: refund my order
+`,
+		"README.md": "not a route",
+	})
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "nested.yaml"), 0o700))
+
+	routes, err := LoadRoutesFromDir(dir)
+	require.NoError(t, err)
+	require.Len(t, routes, 4)
+	names := make([]string, 0, len(routes))
+	for _, route := range routes {
+		names = append(names, route.Name)
+	}
+	assert.Equal(t, []string{"chitchat", "billing", "refunds", "politics"}, names)
+	assert.Equal(t, "lovely weather today", routes[0].Utterances[1].Utterance)
+	assert.Equal(t, []string{"weather"}, routes[0].RequiredKeywords)
+	assert.Equal(t, "billing", routes[2].Parent)
+	assert.Equal(t, []string{"news"}, routes[3].Utterances[0].Tags)
+
+	router, err := NewRouter([]Route{routes[0], routes[3]}, newTestEncoder(), memory.NewStore())
+	require.NoError(t, err)
+	route, _, err := router.Match(context.Background(), "what about the election?")
+	require.NoError(t, err)
+	assert.Equal(t, "politics", route)
+
+	t.Run("duplicate", func(t *testing.T) {
+		dir := writeRouteFiles(t, map[string]string{
+			"a.yaml": "name: billing\n",
+			"b.json": `[{"name": "billing"}]`,
+		})
+		_, err := LoadRoutesFromDir(dir)
+		assert.ErrorContains(t, err, `duplicate route name: "billing"`)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		for name, content := range map[string]string{
+			"bad.yaml":   "name: [",
+			"empty.yaml": "",
+			"bad.json":   `{"name":`,
+		} {
+			_, err := LoadRoutesFromDir(writeRouteFiles(t, map[string]string{name: content}))
+			assert.ErrorContains(t, err, name)
+		}
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		_, err := LoadRoutesFromDir(filepath.Join(t.TempDir(), "missing"))
+		assert.Error(t, err)
+	})
+}
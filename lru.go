@@ -0,0 +1,59 @@
+package semanticrouter
+
+import (
+	"container/list"
+	"sync"
+)
+
+// lru is a concurrency-safe least recently used cache.
+type lru[K comparable, V any] struct {
+	mu    sync.Mutex
+	size  int
+	order *list.List
+	items map[K]*list.Element
+}
+
+// lruItem is an item of a lru cache.
+type lruItem[K comparable, V any] struct {
+	key   K
+	value V
+}
+
+// newLRU creates a new lru cache holding at most size items.
+func newLRU[K comparable, V any](size int) *lru[K, V] {
+	return &lru[K, V]{
+		size:  size,
+		order: list.New(),
+		items: make(map[K]*list.Element),
+	}
+}
+
+// get returns the value cached for the given key, if any.
+func (c *lru[K, V]) get(key K) (value V, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.items[key]
+	if !ok {
+		return value, false
+	}
+	c.order.MoveToFront(el)
+	return el.Value.(*lruItem[K, V]).value, true
+}
+
+// put caches the given value for the given key, evicting the least recently
+// used item if the cache is full.
+func (c *lru[K, V]) put(key K, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[key]; ok {
+		el.Value.(*lruItem[K, V]).value = value
+		c.order.MoveToFront(el)
+		return
+	}
+	c.items[key] = c.order.PushFront(&lruItem[K, V]{key: key, value: value})
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*lruItem[K, V]).key)
+	}
+}
@@ -0,0 +1,81 @@
+package semanticrouter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// MatchOption is a function overriding the configuration of a router for a
+// single MatchWith call.
+type MatchOption func(*matchOverrides)
+
+// matchOverrides are the overrides of the configuration of a router for a
+// single MatchWith call.
+type matchOverrides struct {
+	similarities []biFuncCoefficient
+	threshold    *float64
+	errs         []error
+}
+
+// WithMatchSimilarity scores the call with the similarity function of the
+// given name, weighted by the given coefficient, instead of the router's
+// similarity functions and the ones overridden by its routes.
+//
+// The function is resolved by name like in a RouterConfig. Multiple
+// functions can be given, their weighted scores being summed.
+func WithMatchSimilarity(name string, coefficient float64) MatchOption {
+	return func(o *matchOverrides) {
+		bf, err := resolveSimilarity(SimilaritySpec{
+			Name:        name,
+			Coefficient: coefficient,
+		})
+		if err != nil {
+			o.errs = append(o.errs, err)
+			return
+		}
+		o.similarities = append(o.similarities, bf)
+	}
+}
+
+// WithMatchThreshold sets the score below which no route is matched by the
+// call, instead of the router's threshold.
+func WithMatchThreshold(threshold float64) MatchOption {
+	return func(o *matchOverrides) {
+		o.threshold = &threshold
+	}
+}
+
+// MatchWith is like Match, but with the configuration of the router
+// overridden by the given options for this call only.
+//
+// The router itself is not modified, so MatchWith can be used concurrently
+// with other calls, for instance to compare similarity functions on live
+// traffic without building several routers. Feedback cannot be recorded for
+// the threshold of a call.
+func (r *Router) MatchWith(
+	ctx context.Context,
+	utterance string,
+	opts ...MatchOption,
+) (bestRouteName string, bestScore float64, err error) {
+	var o matchOverrides
+	for _, opt := range opts {
+		opt(&o)
+	}
+	err = errors.Join(o.errs...)
+	if err != nil {
+		return "", 0.0, fmt.Errorf("error applying match options: %w", err)
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	call := *r
+	if len(o.similarities) > 0 {
+		call.biFuncCoefficients = o.similarities
+		call.routeFuncs = nil
+		call.spatial = nil
+	}
+	if o.threshold != nil {
+		call.threshold = &adaptiveThreshold{value: *o.threshold}
+	}
+	return call.match(ctx, utterance, true)
+}
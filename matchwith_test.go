@@ -0,0 +1,38 @@
+package semanticrouter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/conneroisu/go-semantic-router/stores/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMatchWith tests that the overrides of a call apply to that call only.
+func TestMatchWith(t *testing.T) {
+	ctx := context.Background()
+	router, err := NewRouter(newTestRoutes(), newTestEncoder(), memory.NewStore())
+	require.NoError(t, err)
+	const utterance = "what about the election?"
+	wantRoute, wantScore, err := router.Match(ctx, utterance)
+	require.NoError(t, err)
+
+	route, score, err := router.MatchWith(ctx, utterance, WithMatchSimilarity(SimilarityDotProduct, 2))
+	require.NoError(t, err)
+	assert.Equal(t, "politics", route)
+	assert.InDelta(t, 2*0.82, score, 1e-12)
+
+	_, _, err = router.MatchWith(ctx, utterance, WithMatchThreshold(0.99))
+	assert.ErrorContains(t, err, "no route found")
+
+	_, _, err = router.MatchWith(ctx, utterance, WithMatchSimilarity("unknown", 1))
+	assert.ErrorContains(t, err, "unknown similarity function")
+
+	route, score, err = router.Match(ctx, utterance)
+	require.NoError(t, err)
+	assert.Equal(t, wantRoute, route)
+	assert.Equal(t, wantScore, score)
+	assert.Empty(t, router.SimilarityConfig())
+	assert.Zero(t, router.Threshold())
+}
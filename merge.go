@@ -0,0 +1,186 @@
+package semanticrouter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"sync"
+
+	"github.com/conneroisu/go-semantic-router/domain"
+)
+
+// MergeRouters merges the given routers into a single router matching across
+// the routes of all of them.
+//
+// Route names must be unique across the routers, and the routers must use
+// the same kind of embeddings, of the same dimension for dense embeddings.
+// The merged router uses the encoder and options of the first router, but
+// with caches, statistics and an adaptive threshold of its own, starting
+// from the current threshold of the first router. If all routers share the
+// same store, the merged router uses it as well; otherwise embeddings are
+// looked up in each router's store in turn.
+func MergeRouters(routers ...*Router) (*Router, error) {
+	if len(routers) == 0 {
+		return nil, errors.New("no routers to merge")
+	}
+	ctx := context.Background()
+	first := routers[0]
+	var routes []Route
+	var stores []Store
+	dimension := -1
+	for i, router := range routers {
+		routes = append(routes, router.Routes...)
+		if !containsStore(stores, router.Storage) {
+			stores = append(stores, router.Storage)
+		}
+		if router.embeddings != first.embeddings {
+			return nil, fmt.Errorf(
+				"router %d uses %s embeddings, expected %s",
+				i,
+				router.embeddings,
+				first.embeddings,
+			)
+		}
+		if router.embeddings != denseEmbeddings {
+			continue
+		}
+		dim, err := router.dimension(ctx)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"error getting dimension of router %d: %w",
+				i,
+				err,
+			)
+		}
+		if dim == 0 {
+			continue
+		}
+		if dimension != -1 && dim != dimension {
+			return nil, fmt.Errorf(
+				"router %d has dimension %d, expected %d",
+				i,
+				dim,
+				dimension,
+			)
+		}
+		dimension = dim
+	}
+	err := validateRoutes(routes)
+	if err != nil {
+		return nil, fmt.Errorf("error validating routes: %w", err)
+	}
+	var store Store = mergedStore(stores)
+	if len(stores) == 1 {
+		store = stores[0]
+	}
+	merged := &Router{
+		Routes:  routes,
+		Encoder: first.Encoder,
+		Storage: store,
+		config:  first.config.fresh(),
+	}
+	err = merged.resolveRouteSimilarities()
+	if err != nil {
+		return nil, fmt.Errorf("error resolving route similarities: %w", err)
+	}
+	err = merged.checkEmbeddings()
+	if err != nil {
+		return nil, err
+	}
+	err = merged.buildSpatialIndex(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return merged, nil
+}
+
+// fresh returns a copy of the configuration with state of its own: the
+// copy shares no cache, centroid, statistics or adaptive threshold with the
+// configuration, so that routers built from it do not interfere.
+func (c config) fresh() config {
+	c.mu = &sync.RWMutex{}
+	c.centroidsMu = &sync.Mutex{}
+	c.centroidState = nil
+	c.stats = newRouteStats()
+	c.biFuncCoefficients = slices.Clone(c.biFuncCoefficients)
+	for i, bf := range c.biFuncCoefficients {
+		if bf.cache != nil {
+			c.biFuncCoefficients[i].cache = newLRU[scoreKey, float64](bf.cache.size)
+		}
+	}
+	c.routeFuncs = nil
+	if c.queryCache != nil {
+		c.queryCache = newLRU[string, cachedQuery](c.queryCache.size)
+	}
+	if c.normCache != nil {
+		c.normCache = newLRU[string, float64](c.normCache.size)
+	}
+	if c.indexCache != nil {
+		c.indexCache = newIndexCache(c.indexCache.ttl)
+	}
+	if c.threshold != nil {
+		c.threshold = &adaptiveThreshold{value: c.threshold.get()}
+	}
+	c.prefetched = nil
+	c.spatial = nil
+	return c
+}
+
+// dimension returns the dimension of the router's embeddings, or zero if the
+// router has no utterances.
+func (r *Router) dimension(ctx context.Context) (int, error) {
+	for _, route := range r.Routes {
+		for _, ut := range route.Utterances {
+			em, err := r.get(ctx, ut.Utterance)
+			if err != nil {
+				return 0, ErrGetEmbedding{Message: "error getting embedding", Err: err}
+			}
+			return len(em), nil
+		}
+	}
+	return 0, nil
+}
+
+// containsStore reports whether the given store is one of stores.
+func containsStore(stores []Store, store Store) bool {
+	if !reflect.TypeOf(store).Comparable() {
+		return false
+	}
+	for _, s := range stores {
+		if reflect.TypeOf(s) == reflect.TypeOf(store) && s == store {
+			return true
+		}
+	}
+	return false
+}
+
+// mergedStore is a Store looking up embeddings in each of its stores in turn.
+//
+// New utterances are stored in the first store.
+type mergedStore []Store
+
+// Get gets the embedding of the utterance from the first store containing it.
+func (m mergedStore) Get(
+	ctx context.Context,
+	utterance string,
+) ([]float64, error) {
+	var errs []error
+	for _, store := range m {
+		em, err := store.Get(ctx, utterance)
+		if err == nil {
+			return em, nil
+		}
+		errs = append(errs, err)
+	}
+	return nil, errors.Join(errs...)
+}
+
+// Store stores the utterance in the first store.
+func (m mergedStore) Store(
+	ctx context.Context,
+	utterance domain.Utterance,
+) error {
+	return m[0].Store(ctx, utterance)
+}
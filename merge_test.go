@@ -0,0 +1,83 @@
+package semanticrouter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/conneroisu/go-semantic-router/domain"
+	"github.com/conneroisu/go-semantic-router/stores/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMergeRouters tests that a merged router matches queries belonging to
+// the routes of each of the merged routers.
+func TestMergeRouters(t *testing.T) {
+	ctx := context.Background()
+	encoder := newTestEncoder()
+	routes := newTestRoutes()
+	chitchat, err := NewRouter(routes[:1], encoder, memory.NewStore())
+	require.NoError(t, err)
+	politics, err := NewRouter(routes[1:], encoder, memory.NewStore())
+	require.NoError(t, err)
+
+	merged, err := MergeRouters(chitchat, politics)
+	require.NoError(t, err)
+	assert.Len(t, merged.Routes, 2)
+
+	route, _, err := merged.Match(ctx, "is it raining outside?")
+	require.NoError(t, err)
+	assert.Equal(t, "chitchat", route)
+	route, _, err = merged.Match(ctx, "what about the election?")
+	require.NoError(t, err)
+	assert.Equal(t, "politics", route)
+}
+
+// TestMergeRoutersErrors tests that routers with duplicate route names or
+// different dimensions cannot be merged.
+func TestMergeRoutersErrors(t *testing.T) {
+	encoder := newTestEncoder()
+	encoder.embeddings["short"] = []float64{1.0, 0.0}
+	store := memory.NewStore()
+	a, err := NewRouter(newTestRoutes(), encoder, store)
+	require.NoError(t, err)
+	b, err := NewRouter(newTestRoutes()[:1], encoder, store)
+	require.NoError(t, err)
+	c, err := NewRouter([]Route{{
+		Name:       "short",
+		Utterances: []domain.Utterance{{Utterance: "short"}},
+	}}, encoder, memory.NewStore())
+	require.NoError(t, err)
+
+	_, err = MergeRouters()
+	assert.Error(t, err)
+	_, err = MergeRouters(a, b)
+	assert.ErrorContains(t, err, `duplicate route name: "chitchat"`)
+	_, err = MergeRouters(a, c)
+	assert.ErrorContains(t, err, "router 1 has dimension 2, expected 3")
+}
+
+// TestMergeRoutersState tests that a merged router does not share caches or
+// its adaptive threshold with the first merged router.
+func TestMergeRoutersState(t *testing.T) {
+	ctx := context.Background()
+	encoder := &countingEncoder{Encoder: newTestEncoder()}
+	routes := newTestRoutes()
+	chitchat, err := NewRouter(routes[:1], encoder, memory.NewStore(), WithAdaptiveThreshold(0.5), WithQueryCache(8))
+	require.NoError(t, err)
+	politics, err := NewRouter(routes[1:], encoder, memory.NewStore())
+	require.NoError(t, err)
+	merged, err := MergeRouters(chitchat, politics)
+	require.NoError(t, err)
+
+	merged.RecordFeedback("what about the election?", "politics", false)
+	assert.Greater(t, merged.Threshold(), 0.5)
+	assert.Equal(t, 0.5, chitchat.Threshold())
+
+	_, _, err = chitchat.Match(ctx, "is it raining outside?")
+	require.NoError(t, err)
+	calls := encoder.calls.Load()
+	_, _, err = merged.Match(ctx, "is it raining outside?")
+	require.NoError(t, err)
+	assert.Equal(t, calls+1, encoder.calls.Load())
+}
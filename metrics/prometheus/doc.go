@@ -0,0 +1,3 @@
+// Package prometheus provides a semantic router Observer exporting
+// prometheus metrics.
+package prometheus
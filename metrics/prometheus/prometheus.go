@@ -0,0 +1,170 @@
+package prometheus
+
+import (
+	"errors"
+	"fmt"
+
+	semanticrouter "github.com/conneroisu/go-semantic-router"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// DefaultNamespace is the default namespace of the exported metrics.
+const DefaultNamespace = "semantic_router"
+
+// Observer is a semanticrouter.Observer recording the activity of a router
+// as prometheus metrics.
+type Observer struct {
+	matches     *prometheus.CounterVec
+	noMatches   prometheus.Counter
+	errors      prometheus.Counter
+	duration    prometheus.Histogram
+	scores      *prometheus.HistogramVec
+	cacheLookup *prometheus.CounterVec
+	concurrency prometheus.Gauge
+}
+
+// options are the options of an Observer.
+type options struct {
+	namespace       string
+	durationBuckets []float64
+	scoreBuckets    []float64
+}
+
+// Option is a function that configures an Observer.
+type Option func(*options)
+
+// WithNamespace sets the namespace of the exported metrics, see
+// DefaultNamespace.
+func WithNamespace(namespace string) Option {
+	return func(o *options) {
+		o.namespace = namespace
+	}
+}
+
+// WithDurationBuckets sets the buckets, in seconds, of the match latency
+// histogram.
+func WithDurationBuckets(buckets []float64) Option {
+	return func(o *options) {
+		o.durationBuckets = buckets
+	}
+}
+
+// WithScoreBuckets sets the buckets of the match score histogram.
+func WithScoreBuckets(buckets []float64) Option {
+	return func(o *options) {
+		o.scoreBuckets = buckets
+	}
+}
+
+// NewObserver creates a new Observer and registers its collectors on the
+// given registerer.
+//
+// The following metrics are exported, prefixed by the namespace:
+//
+//   - matches_total: the number of matches, by route.
+//   - no_matches_total: the number of Match calls that matched no route.
+//   - match_errors_total: the number of Match calls that failed otherwise.
+//   - match_duration_seconds: the latency of Match calls.
+//   - match_score: the score of matches, by route.
+//   - query_cache_lookups_total: the query cache lookups, by result.
+//   - build_concurrency: the concurrency chosen to encode utterances when
+//     the router was built, see semanticrouter.WithAutoConcurrency.
+func NewObserver(
+	reg prometheus.Registerer,
+	opts ...Option,
+) (*Observer, error) {
+	o := options{
+		namespace:       DefaultNamespace,
+		durationBuckets: prometheus.DefBuckets,
+		scoreBuckets:    prometheus.LinearBuckets(0, 0.1, 11),
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	obs := &Observer{
+		matches: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: o.namespace,
+			Name:      "matches_total",
+			Help:      "Number of utterances matched, by route.",
+		}, []string{"route"}),
+		noMatches: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: o.namespace,
+			Name:      "no_matches_total",
+			Help:      "Number of utterances that matched no route.",
+		}),
+		errors: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: o.namespace,
+			Name:      "match_errors_total",
+			Help:      "Number of utterances whose matching failed.",
+		}),
+		duration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: o.namespace,
+			Name:      "match_duration_seconds",
+			Help:      "Latency of matching an utterance.",
+			Buckets:   o.durationBuckets,
+		}),
+		scores: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: o.namespace,
+			Name:      "match_score",
+			Help:      "Score of matched utterances, by route.",
+			Buckets:   o.scoreBuckets,
+		}, []string{"route"}),
+		cacheLookup: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: o.namespace,
+			Name:      "query_cache_lookups_total",
+			Help:      "Number of query cache lookups, by result.",
+		}, []string{"result"}),
+		concurrency: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: o.namespace,
+			Name:      "build_concurrency",
+			Help:      "Concurrency chosen to encode utterances when building the router.",
+		}),
+	}
+	for _, c := range []prometheus.Collector{
+		obs.matches,
+		obs.noMatches,
+		obs.errors,
+		obs.duration,
+		obs.scores,
+		obs.cacheLookup,
+		obs.concurrency,
+	} {
+		err := reg.Register(c)
+		if err != nil {
+			return nil, fmt.Errorf("error registering collector: %w", err)
+		}
+	}
+	return obs, nil
+}
+
+// ObserveMatch records the outcome, latency and score of a match.
+//
+// Only matches failing with semanticrouter.ErrNoRouteFound count as matching
+// no route; the other failures, such as encoder errors, count as errors.
+func (o *Observer) ObserveMatch(event semanticrouter.MatchEvent) {
+	o.duration.Observe(event.Duration.Seconds())
+	switch {
+	case event.Err != nil && !errors.Is(event.Err, semanticrouter.ErrNoRouteFound):
+		o.errors.Inc()
+		return
+	case event.Err != nil || event.Route == "":
+		o.noMatches.Inc()
+		return
+	}
+	o.matches.WithLabelValues(event.Route).Inc()
+	o.scores.WithLabelValues(event.Route).Observe(event.Score)
+}
+
+// ObserveQueryCache records a query cache hit or miss.
+func (o *Observer) ObserveQueryCache(hit bool) {
+	if hit {
+		o.cacheLookup.WithLabelValues("hit").Inc()
+		return
+	}
+	o.cacheLookup.WithLabelValues("miss").Inc()
+}
+
+// ObserveConcurrency records the concurrency chosen to build the router.
+func (o *Observer) ObserveConcurrency(level int) {
+	o.concurrency.Set(float64(level))
+}
@@ -0,0 +1,100 @@
+package prometheus
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	semanticrouter "github.com/conneroisu/go-semantic-router"
+	"github.com/conneroisu/go-semantic-router/domain"
+	"github.com/conneroisu/go-semantic-router/stores/memory"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockEncoder is an encoder that returns a fixed embedding for each known
+// utterance.
+type mockEncoder map[string][]float64
+
+// Encode returns the fixed embedding of the given utterance.
+func (m mockEncoder) Encode(
+	_ context.Context,
+	utterance string,
+) ([]float64, error) {
+	em, ok := m[utterance]
+	if !ok {
+		return nil, fmt.Errorf("unknown utterance: %s", utterance)
+	}
+	return em, nil
+}
+
+// TestObserver tests that matches are exported as prometheus metrics.
+func TestObserver(t *testing.T) {
+	ctx := context.Background()
+	reg := prometheus.NewRegistry()
+	observer, err := NewObserver(reg)
+	require.NoError(t, err)
+	router, err := semanticrouter.NewRouter(
+		[]semanticrouter.Route{
+			{
+				Name:       "chitchat",
+				Utterances: []domain.Utterance{{Utterance: "lovely weather today"}},
+			},
+			{
+				Name:       "politics",
+				Utterances: []domain.Utterance{{Utterance: "who will win the vote?"}},
+			},
+		},
+		mockEncoder{
+			"lovely weather today":     {1.0, 0.0},
+			"who will win the vote?":   {0.0, 1.0},
+			"is it raining outside?":   {0.9, 0.1},
+			"what about the election?": {0.1, 0.9},
+			"something else entirely":  {-1.0, -1.0},
+		},
+		memory.NewStore(),
+		semanticrouter.WithObserver(observer),
+		semanticrouter.WithAutoConcurrency(4),
+	)
+	require.NoError(t, err)
+
+	for _, utterance := range []string{
+		"is it raining outside?",
+		"is it raining outside?",
+		"what about the election?",
+		"something else entirely",
+		"unknown",
+	} {
+		_, _, _ = router.Match(ctx, utterance)
+	}
+
+	expected := `
+# HELP semantic_router_matches_total Number of utterances matched, by route.
+# TYPE semantic_router_matches_total counter
+semantic_router_matches_total{route="chitchat"} 2
+semantic_router_matches_total{route="politics"} 1
+# HELP semantic_router_no_matches_total Number of utterances that matched no route.
+# TYPE semantic_router_no_matches_total counter
+semantic_router_no_matches_total 1
+# HELP semantic_router_match_errors_total Number of utterances whose matching failed.
+# TYPE semantic_router_match_errors_total counter
+semantic_router_match_errors_total 1
+`
+	err = testutil.GatherAndCompare(
+		reg,
+		strings.NewReader(expected),
+		"semantic_router_matches_total",
+		"semantic_router_no_matches_total",
+		"semantic_router_match_errors_total",
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, testutil.CollectAndCount(observer.duration))
+	assert.Equal(t, 2, testutil.CollectAndCount(observer.scores))
+	assert.Positive(t, testutil.ToFloat64(observer.concurrency))
+
+	_, err = NewObserver(reg)
+	assert.Error(t, err)
+}
@@ -0,0 +1,91 @@
+package semanticrouter
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/conneroisu/go-semantic-router/domain"
+)
+
+// MultiVectorEncoder is an Encoder that can also encode utterances into
+// multi-vector embeddings, one vector per token.
+type MultiVectorEncoder interface {
+	Encoder
+	EncodeMulti(ctx context.Context, utterance string) (domain.MultiVectorEmbedding, error)
+}
+
+// MultiVectorStore is a Store that can also store multi-vector embeddings.
+type MultiVectorStore interface {
+	Store
+	StoreMulti(ctx context.Context, utterance domain.MultiVectorUtterance) error
+	GetMulti(ctx context.Context, utterance string) (domain.MultiVectorEmbedding, error)
+}
+
+// storeMultiVectorUtterance encodes the given utterance into a multi-vector
+// embedding and stores it.
+func (r *Router) storeMultiVectorUtterance(
+	ctx context.Context,
+	utter domain.Utterance,
+) error {
+	en, err := r.encodeMultiVector(ctx, r.documentInput(utter.Utterance))
+	if err != nil {
+		return ErrEncoding{Message: "error encoding utterance", Err: err}
+	}
+	err = r.storeMultiVector(ctx, domain.MultiVectorUtterance{
+		Utterance: utter.Utterance,
+		Embedding: en,
+	})
+	if err != nil {
+		return fmt.Errorf(
+			"error storing utterance: %s: %w",
+			utter.Utterance,
+			err,
+		)
+	}
+	return nil
+}
+
+// MaxSim computes the late interaction score of two multi-vector embeddings
+// as introduced by ColBERT.
+//
+// For each vector of the query, the maximum cosine similarity with any vector
+// of the index is taken, multiplied by the query vector's weight and summed.
+// Index vectors are weighted as well before taking the maximum. Both
+// embeddings must be valid, see domain.MultiVectorEmbedding.Validate.
+func MaxSim(query, index domain.MultiVectorEmbedding) float64 {
+	var score float64
+	for i, q := range query.Vectors {
+		best := math.Inf(-1)
+		for j, d := range index.Vectors {
+			sim := cosine(q, d) * index.Weight(j)
+			if sim > best {
+				best = sim
+			}
+		}
+		if math.IsInf(best, -1) {
+			continue
+		}
+		score += best * query.Weight(i)
+	}
+	return score
+}
+
+// cosine computes the cosine similarity of two vectors of the same length.
+//
+// Zero vectors have a similarity of zero with any vector.
+func cosine(a, b []float64) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += a[i] * b[i]
+		na += a[i] * a[i]
+		nb += b[i] * b[i]
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
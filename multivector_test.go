@@ -0,0 +1,123 @@
+package semanticrouter
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/conneroisu/go-semantic-router/domain"
+	"github.com/conneroisu/go-semantic-router/stores/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// tokenEncoder is a mock multi-vector encoder embedding each word of an
+// utterance with a fixed token vector.
+type tokenEncoder struct {
+	tokens  map[string][]float64
+	weights []float64
+}
+
+// Encode returns the fixed vector of the first word of the given utterance.
+func (e tokenEncoder) Encode(
+	ctx context.Context,
+	utterance string,
+) ([]float64, error) {
+	em, err := e.EncodeMulti(ctx, utterance)
+	if err != nil {
+		return nil, err
+	}
+	return em.Vectors[0], nil
+}
+
+// EncodeMulti returns the fixed vectors of the words of the given utterance.
+func (e tokenEncoder) EncodeMulti(
+	_ context.Context,
+	utterance string,
+) (domain.MultiVectorEmbedding, error) {
+	var em domain.MultiVectorEmbedding
+	for _, word := range strings.Fields(utterance) {
+		vec, ok := e.tokens[word]
+		if !ok {
+			return em, fmt.Errorf("no embedding for token: %s", word)
+		}
+		em.Vectors = append(em.Vectors, vec)
+	}
+	em.Weights = e.weights
+	return em, nil
+}
+
+// TestMaxSim tests the late interaction score of multi-vector embeddings.
+func TestMaxSim(t *testing.T) {
+	index := domain.MultiVectorEmbedding{
+		Vectors: [][]float64{{1, 0}, {0, 1}},
+	}
+	query := domain.MultiVectorEmbedding{
+		Vectors: [][]float64{{1, 0}, {1, 1}},
+	}
+	// The first query token matches exactly, the second is at 45 degrees of
+	// both index tokens.
+	assert.InDelta(t, 1+1/1.4142135623730951, MaxSim(query, index), 1e-12)
+
+	query.Weights = []float64{2, 0}
+	assert.InDelta(t, 2, MaxSim(query, index), 1e-12)
+
+	assert.Zero(t, MaxSim(query, domain.MultiVectorEmbedding{}))
+}
+
+// TestMultiVectorMatch tests that a router using multi-vector embeddings
+// matches queries with MaxSim.
+func TestMultiVectorMatch(t *testing.T) {
+	ctx := context.Background()
+	encoder := tokenEncoder{tokens: map[string][]float64{
+		"sunny":    {1, 0, 0},
+		"rain":     {0.9, 0.1, 0},
+		"weather":  {0.8, 0, 0.2},
+		"vote":     {0, 1, 0},
+		"election": {0.1, 0.9, 0},
+		"today":    {0.3, 0.3, 0.9},
+	}}
+	routes := []Route{
+		{
+			Name: "chitchat",
+			Utterances: []domain.Utterance{
+				{Utterance: "sunny today"},
+				{Utterance: "weather"},
+			},
+		},
+		{
+			Name: "politics",
+			Utterances: []domain.Utterance{
+				{Utterance: "vote today"},
+			},
+		},
+	}
+	router, err := NewRouter(
+		routes,
+		encoder,
+		memory.NewStore(),
+		WithMultiVectorEmbeddings(),
+	)
+	require.NoError(t, err)
+
+	route, _, err := router.Match(ctx, "rain today")
+	require.NoError(t, err)
+	assert.Equal(t, "chitchat", route)
+	route, _, err = router.Match(ctx, "election today")
+	require.NoError(t, err)
+	assert.Equal(t, "politics", route)
+
+	_, err = NewRouter(
+		newTestRoutes(),
+		newTestEncoder(),
+		memory.NewStore(),
+		WithMultiVectorEmbeddings(),
+	)
+	assert.Error(t, err)
+
+	// Embeddings without as many weights as vectors are rejected.
+	encoder.weights = make([]float64, 7)
+	_, err = NewRouter(routes, encoder, memory.NewStore(), WithMultiVectorEmbeddings())
+	assert.ErrorContains(t, err, "7 weights")
+}
@@ -0,0 +1,44 @@
+package semanticrouter
+
+import "math"
+
+// NaNPolicy is how NaN and infinite scores returned by similarity functions
+// are handled, see WithNaNPolicy.
+type NaNPolicy int
+
+const (
+	// NaNError fails the match with an ErrInvalidScore identifying the
+	// function and the utterance.
+	NaNError NaNPolicy = iota
+	// NaNZero treats the score as zero.
+	NaNZero
+	// NaNSkip ignores the function for the utterance; an utterance for which
+	// every function is ignored is not scored at all.
+	NaNSkip
+)
+
+// checkScore checks the given score of the named similarity function
+// against the index entry, applying the router's NaN policy.
+//
+// valid is false if the function must be ignored for the entry.
+func (r *Router) checkScore(
+	name string,
+	entry indexEntry,
+	score float64,
+) (s float64, valid bool, err error) {
+	if !math.IsNaN(score) && !math.IsInf(score, 0) {
+		return score, true, nil
+	}
+	switch r.nanPolicy {
+	case NaNZero:
+		return 0, true, nil
+	case NaNSkip:
+		return 0, false, nil
+	default:
+		return 0, false, ErrInvalidScore{
+			Function:  name,
+			Utterance: entry.utterance,
+			Score:     score,
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package semanticrouter
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/conneroisu/go-semantic-router/stores/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gonum.org/v1/gonum/mat"
+)
+
+// nanSimilarity is a similarity function returning NaN for the embedding of
+// "who will win the vote?" and the dot product otherwise.
+func nanSimilarity(q, idx *mat.VecDense) float64 {
+	if idx.AtVec(0) == 0.0 && idx.AtVec(1) == 1.0 {
+		return math.NaN()
+	}
+	return DotProduct(q, idx)
+}
+
+// TestWithNaNPolicy tests each way of handling a similarity function
+// returning NaN.
+func TestWithNaNPolicy(t *testing.T) {
+	ctx := context.Background()
+	routes := newTestRoutes()
+	routes[1].Utterances = routes[1].Utterances[:1]
+	newRouter := func(opts ...Option) *Router {
+		router, err := NewRouter(
+			routes,
+			newTestEncoder(),
+			memory.NewStore(),
+			append([]Option{
+				WithCustomSimilarity("nan", nanSimilarity, 1),
+				WithDotProduct(1),
+			}, opts...)...,
+		)
+		require.NoError(t, err)
+		return router
+	}
+
+	_, _, err := newRouter().Match(ctx, "what about the election?")
+	var invalid ErrInvalidScore
+	require.ErrorAs(t, err, &invalid)
+	assert.Equal(t, "nan", invalid.Function)
+	assert.Equal(t, "who will win the vote?", invalid.Utterance)
+	assert.True(t, math.IsNaN(invalid.Score))
+	assert.EqualError(t, err, `similarity function "nan" returned NaN for utterance "who will win the vote?"`)
+
+	// Only the dot product of "who will win the vote?" is counted.
+	for _, policy := range []NaNPolicy{NaNZero, NaNSkip} {
+		scores, err := newRouter(WithNaNPolicy(policy)).ScoreAll(ctx, "what about the election?")
+		require.NoError(t, err)
+		assert.InDelta(t, 0.82, scores["politics"], 1e-12)
+	}
+}
+
+// TestWithNaNPolicySkip tests that utterances whose every score is NaN are
+// skipped rather than scored zero.
+func TestWithNaNPolicySkip(t *testing.T) {
+	ctx := context.Background()
+	encoder := newTestEncoder()
+	encoder.embeddings["opposite"] = []float64{-0.1, -0.8, -0.2}
+	routes := newTestRoutes()
+	routes[1].Utterances = routes[1].Utterances[:1]
+
+	scoresFor := func(policy NaNPolicy) map[string]float64 {
+		router, err := NewRouter(
+			routes,
+			encoder,
+			memory.NewStore(),
+			WithCustomSimilarity("nan", nanSimilarity, 1),
+			WithNaNPolicy(policy),
+		)
+		require.NoError(t, err)
+		scores, err := router.ScoreAll(ctx, "opposite")
+		require.NoError(t, err)
+		return scores
+	}
+	assert.Equal(t, 0.0, scoresFor(NaNZero)["politics"])
+	assert.NotContains(t, scoresFor(NaNSkip), "politics")
+}
@@ -0,0 +1,29 @@
+package semanticrouter
+
+import "strings"
+
+// queryNGrams returns the sliding windows of the given sizes, in words, of
+// the utterance.
+//
+// Sizes that are not smaller than the number of words of the utterance are
+// skipped, as their only window would be the utterance itself, and each
+// window is returned once.
+func queryNGrams(utterance string, sizes []int) []string {
+	words := strings.Fields(utterance)
+	seen := make(map[string]bool)
+	var ngrams []string
+	for _, size := range sizes {
+		if size <= 0 || size >= len(words) {
+			continue
+		}
+		for i := 0; i+size <= len(words); i++ {
+			ngram := strings.Join(words[i:i+size], " ")
+			if seen[ngram] {
+				continue
+			}
+			seen[ngram] = true
+			ngrams = append(ngrams, ngram)
+		}
+	}
+	return ngrams
+}
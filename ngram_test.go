@@ -0,0 +1,55 @@
+package semanticrouter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/conneroisu/go-semantic-router/stores/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestQueryNGrams tests the windows of a query, including queries shorter
+// than the windows.
+func TestQueryNGrams(t *testing.T) {
+	assert.Equal(t,
+		[]string{"a b", "b c", "c d", "a b c", "b c d"},
+		queryNGrams("a b  c d", []int{2, 3, 4, 0}),
+	)
+	assert.Equal(t, []string{"a a"}, queryNGrams("a a a", []int{2}))
+	assert.Empty(t, queryNGrams("short", []int{1, 2}))
+	assert.Empty(t, queryNGrams("", []int{1}))
+}
+
+// TestWithQueryNGramMatching tests that a sub-phrase of a verbose query
+// matches the route the full query misses.
+func TestWithQueryNGramMatching(t *testing.T) {
+	ctx := context.Background()
+	encoder := newTestEncoder()
+	encoder.embeddings["tell me: raining outside?"] = []float64{0.1, 0.9, 0.3}
+	encoder.embeddings["tell me:"] = []float64{0.0, 0.5, 0.5}
+	encoder.embeddings["me: raining"] = []float64{0.2, 0.5, 0.5}
+	encoder.embeddings["raining outside?"] = []float64{0.9, 0.1, 0.0}
+	encoder.embeddings["drizzle?"] = []float64{0.9, 0.0, 0.1}
+
+	router, err := NewRouter(newTestRoutes(), encoder, memory.NewStore())
+	require.NoError(t, err)
+	route, _, err := router.Match(ctx, "tell me: raining outside?")
+	require.NoError(t, err)
+	assert.Equal(t, "politics", route)
+
+	router, err = NewRouter(
+		newTestRoutes(),
+		encoder,
+		memory.NewStore(),
+		WithQueryNGramMatching([]int{2}),
+	)
+	require.NoError(t, err)
+	route, _, err = router.Match(ctx, "tell me: raining outside?")
+	require.NoError(t, err)
+	assert.Equal(t, "chitchat", route)
+
+	route, _, err = router.Match(ctx, "drizzle?")
+	require.NoError(t, err)
+	assert.Equal(t, "chitchat", route)
+}
@@ -1,6 +1,9 @@
 package semanticrouter
 
-import "gonum.org/v1/gonum/floats"
+import (
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+)
 
 // NormalizeScores normalizes the similarity scores to a 0-1 range.
 //
@@ -20,3 +23,17 @@ func NormalizeScores(sim []float64) []float64 {
 	}
 	return normalized
 }
+
+// indexNorm returns the L2 norm of the embedding of the given index
+// utterance, looking it up in the norm cache first if one is configured.
+func (r *Router) indexNorm(utterance string, vec *mat.VecDense) float64 {
+	if r.normCache == nil {
+		return Norm(vec)
+	}
+	if norm, ok := r.normCache.get(utterance); ok {
+		return norm
+	}
+	norm := Norm(vec)
+	r.normCache.put(utterance, norm)
+	return norm
+}
@@ -0,0 +1,115 @@
+package semanticrouter
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+// npyMagic is the magic string starting every .npy file, followed by the
+// major and minor version of the format.
+const npyMagic = "\x93NUMPY\x01\x00"
+
+// npyAlignment is the alignment of the header of a .npy file, including its
+// magic string, version and length.
+const npyAlignment = 64
+
+// NPYRow maps a row of the array written by ExportNPY to its route and
+// utterance.
+type NPYRow struct {
+	Row       int    `json:"row"`       // Row is the index of the row in the array.
+	Route     string `json:"route"`     // Route is the name of the route of the utterance.
+	Utterance string `json:"utterance"` // Utterance is the utterance whose embedding is stored in the row.
+}
+
+// ExportNPY writes the dense embedding of every utterance of every route to
+// w as a 2D array of little-endian float64 in the .npy format of numpy, one
+// row per utterance, so that it can be loaded with numpy.load.
+//
+// Rows are in the order of ExportEmbeddings; ExportNPYRows writes the
+// sidecar mapping each row to its route and utterance. Every embedding must
+// have the same dimension. Only dense embeddings can be exported; other
+// kinds of embeddings fail with an error wrapping ErrNotSupported.
+func (r *Router) ExportNPY(ctx context.Context, w io.Writer) error {
+	embeddings, err := r.ExportEmbeddings(ctx)
+	if err != nil {
+		return err
+	}
+	var dimension int
+	if len(embeddings) > 0 {
+		dimension = len(embeddings[0].Embedding)
+	}
+	for _, em := range embeddings {
+		if len(em.Embedding) != dimension {
+			return fmt.Errorf(
+				"error exporting embeddings: utterance %q has dimension %d, expected %d",
+				em.Utterance,
+				len(em.Embedding),
+				dimension,
+			)
+		}
+	}
+	bw := bufio.NewWriter(w)
+	_, err = bw.WriteString(npyHeader(len(embeddings), dimension))
+	if err != nil {
+		return fmt.Errorf("error writing npy header: %w", err)
+	}
+	var buf [8]byte
+	for _, em := range embeddings {
+		for _, v := range em.Embedding {
+			binary.LittleEndian.PutUint64(buf[:], math.Float64bits(v))
+			_, err = bw.Write(buf[:])
+			if err != nil {
+				return fmt.Errorf("error writing npy data: %w", err)
+			}
+		}
+	}
+	err = bw.Flush()
+	if err != nil {
+		return fmt.Errorf("error writing npy data: %w", err)
+	}
+	return nil
+}
+
+// ExportNPYRows writes to w the JSON array of the NPYRow of every row of
+// the array written by ExportNPY.
+//
+// The routes must not change between the two calls for the rows to match.
+func (r *Router) ExportNPYRows(ctx context.Context, w io.Writer) error {
+	embeddings, err := r.ExportEmbeddings(ctx)
+	if err != nil {
+		return err
+	}
+	rows := make([]NPYRow, 0, len(embeddings))
+	for i, em := range embeddings {
+		rows = append(rows, NPYRow{Row: i, Route: em.Route, Utterance: em.Utterance})
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	err = enc.Encode(rows)
+	if err != nil {
+		return fmt.Errorf("error encoding npy rows: %w", err)
+	}
+	return nil
+}
+
+// npyHeader returns the magic string, version, header length and header of
+// a .npy file storing a C-ordered 2D float64 array of the given shape,
+// padded with spaces so that the data is aligned.
+func npyHeader(rows, columns int) string {
+	header := fmt.Sprintf("{'descr': '<f8', 'fortran_order': False, 'shape': (%d, %d), }", rows, columns)
+	prefix := len(npyMagic) + 2
+	padding := npyAlignment - (prefix+len(header)+1)%npyAlignment
+	if padding == npyAlignment {
+		padding = 0
+	}
+	header += strings.Repeat(" ", padding) + "\n"
+	var length [2]byte
+	binary.LittleEndian.PutUint16(length[:], uint16(len(header)))
+	return npyMagic + string(length[:]) + header
+}
@@ -0,0 +1,65 @@
+package semanticrouter
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/conneroisu/go-semantic-router/stores/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestExportNPY tests that ExportNPY writes a valid .npy header followed by
+// the embeddings, and that ExportNPYRows maps its rows to utterances.
+func TestExportNPY(t *testing.T) {
+	ctx := context.Background()
+	router, err := NewRouter(newTestRoutes(), newTestEncoder(), memory.NewStore())
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, router.ExportNPY(ctx, &buf))
+	data := buf.Bytes()
+	require.Greater(t, len(data), 10)
+	assert.Equal(t, "\x93NUMPY", string(data[:6]))
+	assert.Equal(t, []byte{1, 0}, data[6:8])
+	headerLen := int(binary.LittleEndian.Uint16(data[8:10]))
+	assert.Zero(t, (10+headerLen)%64)
+	header := string(data[10 : 10+headerLen])
+	assert.Regexp(t, `^\{'descr': '<f8', 'fortran_order': False, 'shape': \(4, 3\), \} *\n$`, header)
+
+	values := data[10+headerLen:]
+	require.Len(t, values, 4*3*8)
+	embeddings, err := router.ExportEmbeddings(ctx)
+	require.NoError(t, err)
+	for i, em := range embeddings {
+		for j, v := range em.Embedding {
+			bits := binary.LittleEndian.Uint64(values[(i*3+j)*8:])
+			assert.Equal(t, v, math.Float64frombits(bits))
+		}
+	}
+
+	buf.Reset()
+	require.NoError(t, router.ExportNPYRows(ctx, &buf))
+	var rows []NPYRow
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &rows))
+	assert.Equal(t, []NPYRow{
+		{Row: 0, Route: "chitchat", Utterance: "how's the weather today?"},
+		{Row: 1, Route: "chitchat", Utterance: "lovely weather today"},
+		{Row: 2, Route: "politics", Utterance: "who will win the vote?"},
+		{Row: 3, Route: "politics", Utterance: "i love the president"},
+	}, rows)
+}
+
+// TestNPYHeader tests that .npy headers are aligned for any shape.
+func TestNPYHeader(t *testing.T) {
+	for _, shape := range [][2]int{{0, 0}, {1, 1536}, {123456, 3072}} {
+		header := npyHeader(shape[0], shape[1])
+		assert.Zero(t, len(header)%64)
+		assert.Equal(t, byte('\n'), header[len(header)-1])
+		assert.Equal(t, len(header)-10, int(binary.LittleEndian.Uint16([]byte(header[8:10]))))
+	}
+}
@@ -0,0 +1,48 @@
+package semanticrouter
+
+import "time"
+
+// Observer is notified of the activity of a Router.
+//
+// Implementations must be safe for concurrent use. Embed NopObserver to only
+// implement the methods of interest.
+type Observer interface {
+	// ObserveMatch is called once each Match call completes.
+	ObserveMatch(event MatchEvent)
+	// ObserveQueryCache is called on each lookup in the query cache, see
+	// WithQueryCache.
+	ObserveQueryCache(hit bool)
+	// ObserveConcurrency is called once NewRouter has chosen the number of
+	// concurrent encoder calls used to build the router, see
+	// WithAutoConcurrency.
+	ObserveConcurrency(level int)
+}
+
+// MatchEvent describes a completed Match call.
+type MatchEvent struct {
+	Utterance string        // Utterance is the matched utterance.
+	Route     string        // Route is the name of the matched route, empty if none matched.
+	Score     float64       // Score is the score of the matched route.
+	Duration  time.Duration // Duration is how long the match took.
+	Err       error         // Err is the error returned by Match, if any.
+}
+
+// NopObserver is an Observer ignoring all events.
+type NopObserver struct{}
+
+// ObserveMatch does nothing.
+func (NopObserver) ObserveMatch(MatchEvent) {}
+
+// ObserveQueryCache does nothing.
+func (NopObserver) ObserveQueryCache(bool) {}
+
+// ObserveConcurrency does nothing.
+func (NopObserver) ObserveConcurrency(int) {}
+
+// notify returns the router's observer, or a NopObserver if none is set.
+func (r *Router) notify() Observer {
+	if r.observer == nil {
+		return NopObserver{}
+	}
+	return r.observer
+}
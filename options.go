@@ -0,0 +1,739 @@
+package semanticrouter
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Option is a function that configures a Router.
+type Option func(*Router)
+
+// WithCallTimeout sets a timeout applied to each individual call made to the
+// encoder and the store.
+//
+// Every Encoder.Encode, Store.Store and Store.Get call receives a context
+// derived from the caller's context with the given timeout, so a slow encoder
+// or store cannot hang the router when the caller's context has no deadline.
+// When a call times out, the returned error wraps context.DeadlineExceeded.
+//
+// A non-positive duration disables the per-call timeout, which is the
+// default.
+func WithCallTimeout(d time.Duration) Option {
+	return func(r *Router) {
+		r.callTimeout = d
+	}
+}
+
+// SimilarityOption is a function that configures a similarity function
+// registered with WithCustomSimilarity.
+type SimilarityOption func(*biFuncCoefficient)
+
+// Cacheable declares a similarity function as cacheable.
+//
+// The scores of a cacheable function are memoized across Match calls, keyed
+// by the hashes of the query vector and the index vector, keeping at most
+// size scores. This is only worthwhile for expensive functions whose result
+// depends solely on the two vectors, such as rerank-style models.
+func Cacheable(size int) SimilarityOption {
+	return func(bf *biFuncCoefficient) {
+		bf.cache = newLRU[scoreKey, float64](size)
+	}
+}
+
+// WithCustomSimilarity adds a custom similarity function, identified by the
+// given name, to the router.
+//
+// The score of a query against an index vector is the sum of the scores of
+// all configured similarity functions, each multiplied by its coefficient.
+// When no similarity function is configured, the cosine similarity is used.
+//
+// A negative coefficient subtracts the score of the function, see
+// WithPenalty. Coefficients must be finite, and unless scores are
+// lower-is-better at least one coefficient must be positive. Names must be
+// unique among the similarity functions of the router.
+func WithCustomSimilarity(
+	name string,
+	fn SimilarityFunc,
+	coefficient float64,
+	opts ...SimilarityOption,
+) Option {
+	return func(r *Router) {
+		bf := biFuncCoefficient{
+			name:        name,
+			fn:          fn,
+			coefficient: coefficient,
+		}
+		for _, opt := range opts {
+			opt(&bf)
+		}
+		r.biFuncCoefficients = append(r.biFuncCoefficients, bf)
+	}
+}
+
+// WithSparseEmbeddings makes the router use sparse embeddings, such as the
+// ones produced by SPLADE models, instead of dense ones.
+//
+// The router's encoder must implement SparseEncoder and its store must
+// implement SparseStore. Queries are scored against the index with
+// SparseDotProduct; custom similarity functions are not used.
+func WithSparseEmbeddings() Option {
+	return func(r *Router) {
+		r.embeddings = sparseEmbeddings
+	}
+}
+
+// WithMinUtterancesPerRoute excludes routes having fewer than n utterances
+// from matching.
+//
+// Such routes are still encoded and stored when the router is built, so they
+// become eligible as soon as enough utterances are collected.
+func WithMinUtterancesPerRoute(n int) Option {
+	return func(r *Router) {
+		r.minUtterances = n
+	}
+}
+
+// WithDotProduct adds the dot product similarity, see DotProduct, weighted by
+// the given coefficient.
+func WithDotProduct(coefficient float64) Option {
+	return withSimilarityName(SimilarityDotProduct, coefficient)
+}
+
+// WithCosineSimilarity adds the cosine similarity, see SimilarityMatrix,
+// weighted by the given coefficient.
+func WithCosineSimilarity(coefficient float64) Option {
+	return withSimilarityName(SimilarityCosine, coefficient)
+}
+
+// WithEuclideanDistance adds a similarity derived from the euclidean
+// distance, see EuclideanDistance, weighted by the given coefficient.
+//
+// The distance d is converted into the similarity 1 / (1 + d).
+func WithEuclideanDistance(coefficient float64) Option {
+	return withSimilarityName(SimilarityEuclidean, coefficient)
+}
+
+// WithManhattanDistance adds a similarity derived from the manhattan
+// distance, see ManhattanDistance, weighted by the given coefficient.
+//
+// The distance d is converted into the similarity 1 / (1 + d).
+func WithManhattanDistance(coefficient float64) Option {
+	return withSimilarityName(SimilarityManhattan, coefficient)
+}
+
+// WithJaccardSimilarity adds the weighted jaccard similarity, see
+// JaccardSimilarity, weighted by the given coefficient.
+func WithJaccardSimilarity(coefficient float64) Option {
+	return withSimilarityName(SimilarityJaccard, coefficient)
+}
+
+// WithPearsonCorrelation adds the pearson correlation, see
+// PearsonCorrelation, weighted by the given coefficient.
+func WithPearsonCorrelation(coefficient float64) Option {
+	return withSimilarityName(SimilarityPearson, coefficient)
+}
+
+// WithTextJaccard adds the jaccard similarity of the words of the query and
+// of the utterance, see TextJaccard, weighted by the given coefficient.
+//
+// Combined with a semantic similarity, it favors utterances sharing
+// keywords with the query, such as product names the embeddings do not tell
+// apart. It only applies to dense embeddings, and compares nothing
+// meaningful with WithRouteCentroids, whose entries have no text.
+func WithTextJaccard(coefficient float64) Option {
+	return withSimilarityName(SimilarityTextJaccard, coefficient)
+}
+
+// WithPenalty adds the similarity function with the given name, such as
+// SimilarityEuclidean or a function registered with RegisterSimilarity,
+// weighted by the negation of the given coefficient, so that utterances
+// similar to the query according to that function are demoted.
+//
+// The penalty is subtracted from the score of each utterance before the
+// scores of a route are aggregated, so with AggregationMax the best
+// utterance of a route is the best one after the penalty, not the one
+// scoring best before it. The coefficient must be positive, and the router
+// needs at least one similarity function with a positive coefficient,
+// such as WithCosineSimilarity, since penalties alone cannot make a score
+// positive.
+func WithPenalty(name string, coefficient float64) Option {
+	return func(r *Router) {
+		if !(coefficient > 0) || math.IsInf(coefficient, 1) {
+			r.errs = append(r.errs, fmt.Errorf("penalty coefficient must be positive and finite, got %v", coefficient))
+			return
+		}
+		withSimilarityName(name, -coefficient)(r)
+	}
+}
+
+// WithQueryExpansion sets a query expander whose variants are encoded and
+// scored alongside the original query utterance.
+//
+// The scores of the variants are combined per route according to the
+// expansion mode, see WithQueryExpansionMode. When the expander returns no
+// variants, only the original utterance is matched.
+func WithQueryExpansion(fn QueryExpander) Option {
+	return func(r *Router) {
+		r.expander = fn
+	}
+}
+
+// WithQueryExpansionMode sets how the scores of the variants of an expanded
+// query are combined. The default is ExpansionMax.
+func WithQueryExpansionMode(mode ExpansionMode) Option {
+	return func(r *Router) {
+		r.expansionMode = mode
+	}
+}
+
+// WithQueryNGramMatching makes the router also match the sliding windows of
+// the given sizes, in words, of each query utterance.
+//
+// This helps when the intent of a verbose query is expressed in one of its
+// sub-phrases, whose similarity to the routes is diluted in the full query.
+// The n-grams are scored like the variants of an expanded query, see
+// WithQueryExpansionMode, so by default the best score among the full query
+// and its n-grams is kept. Sizes not smaller than the number of words of a
+// query are skipped for that query.
+func WithQueryNGramMatching(sizes []int) Option {
+	return func(r *Router) {
+		r.ngramSizes = sizes
+	}
+}
+
+// WithKNNVoting makes the router select routes by k-nearest-neighbor voting.
+//
+// Instead of taking the best scoring utterance of each route, the k best
+// scoring utterances across all routes are collected, and each of them votes
+// for its route with its score. The route with the highest summed score wins.
+func WithKNNVoting(k int) Option {
+	return func(r *Router) {
+		r.knn = k
+	}
+}
+
+// WithQueryCache caches the embeddings of up to size query utterances, so
+// that repeated queries skip the encoder.
+//
+// The cache evicts the least recently used embeddings first and is safe for
+// concurrent use. Each lookup is reported to the observer, see WithObserver.
+func WithQueryCache(size int) Option {
+	return func(r *Router) {
+		r.queryCache = newLRU[string, cachedQuery](size)
+	}
+}
+
+// WithQueryCacheTTL makes cached query embeddings expire after the given
+// time to live, for instance to comply with a data retention policy.
+//
+// Query embeddings cached with WithQueryCache are evicted once expired. If
+// the router's store implements TTLStore, query embeddings are also cached
+// in the store under QueryCacheKey with the given time to live, so that
+// routers sharing the store share their cached queries; without
+// WithQueryCache, the store is then the only cache. PruneStore deletes them
+// as utterances no route has. The embeddings of the utterances of the
+// routes are never expired.
+func WithQueryCacheTTL(ttl time.Duration) Option {
+	return func(r *Router) {
+		if ttl <= 0 {
+			r.errs = append(r.errs, fmt.Errorf("query cache time to live %v is not positive", ttl))
+			return
+		}
+		r.queryCacheTTL = ttl
+	}
+}
+
+// WithCacheTTL caches the embeddings of the utterances of the routes in
+// process, so that matches do not fetch them from the store every time,
+// refreshing each one from the store on the first access once it is older
+// than d.
+//
+// Embeddings stored or deleted through the router, as by AddRoute or
+// UpdateRoute, are refreshed on their next access regardless of their age.
+// A short d bounds how long embeddings re-encoded by another process, for
+// instance after an encoder update detected with DetectDrift, are ignored.
+func WithCacheTTL(d time.Duration) Option {
+	return func(r *Router) {
+		if d <= 0 {
+			r.errs = append(r.errs, fmt.Errorf("cache time to live %v is not positive", d))
+			return
+		}
+		r.indexCache = newIndexCache(d)
+	}
+}
+
+// WithObserver sets the observer notified of the router's activity.
+func WithObserver(observer Observer) Option {
+	return func(r *Router) {
+		r.observer = observer
+	}
+}
+
+// WithMultiVectorEmbeddings makes the router use multi-vector embeddings,
+// such as the token-level embeddings of ColBERT models, instead of dense
+// ones.
+//
+// The router's encoder must implement MultiVectorEncoder and its store must
+// implement MultiVectorStore. Queries are scored against the index with
+// MaxSim; custom similarity functions are not used.
+func WithMultiVectorEmbeddings() Option {
+	return func(r *Router) {
+		r.embeddings = multiVectorEmbeddings
+	}
+}
+
+// WithAdaptiveThreshold sets a score threshold below which no route is
+// matched, starting at initial and adjusted by the feedback recorded with
+// RecordFeedback.
+//
+// Each false positive raises the threshold and each false negative lowers
+// it. The current threshold is returned by Threshold. With LowerIsBetter,
+// the threshold is a maximum score instead, see WithScoreDirection.
+func WithAdaptiveThreshold(initial float64) Option {
+	return func(r *Router) {
+		r.threshold = &adaptiveThreshold{value: initial}
+	}
+}
+
+// WithStrictTags excludes untagged utterances from the matches of
+// MatchWithTags.
+//
+// By default, untagged utterances are considered whatever the requested
+// tags.
+func WithStrictTags() Option {
+	return func(r *Router) {
+		r.strictTags = true
+	}
+}
+
+// WithStrictDimensions sets whether an index embedding whose dimension
+// differs from the query's is an error.
+//
+// When strict, Match returns an ErrDimensionMismatch on the first mismatched
+// embedding instead of skipping it, surfacing inconsistencies between the
+// encoder and the store. The default is lenient.
+func WithStrictDimensions(strict bool) Option {
+	return func(r *Router) {
+		r.strictDimensions = strict
+	}
+}
+
+// WithNormCache caches the L2 norms of at most size index embeddings, keyed
+// by utterance.
+//
+// Norms are computed when utterances are stored, or the first time they are
+// loaded, so the cosine similarity of a query only needs the dot product.
+// The cache assumes the embeddings of stored utterances are not modified
+// outside of the router.
+func WithNormCache(size int) Option {
+	return func(r *Router) {
+		r.normCache = newLRU[string, float64](size)
+	}
+}
+
+// WithReranker adds a rerank pass to matching.
+//
+// The utterances best matching the query by embedding, see
+// WithRerankCandidates, are scored by the given reranker; only these
+// candidates are considered, each with its score increased by the
+// reranker's score multiplied by the given coefficient.
+func WithReranker(reranker Reranker, coefficient float64) Option {
+	return func(r *Router) {
+		r.reranker = reranker
+		r.rerankCoefficient = coefficient
+	}
+}
+
+// WithRerankCandidates sets the number of candidates passed to the reranker
+// set with WithReranker. The default is 10.
+func WithRerankCandidates(n int) Option {
+	return func(r *Router) {
+		r.rerankCandidates = n
+	}
+}
+
+// WithDimension sets the dimension of the router's dense embeddings up
+// front.
+//
+// Utterances whose embedding has another dimension are rejected with an
+// ErrDimensionMismatch, both when the router is built and when routes are
+// added with AddRoute. This lets an empty router validate its first routes.
+func WithDimension(n int) Option {
+	return func(r *Router) {
+		r.fixedDimension = n
+	}
+}
+
+// WithOutputDimension truncates the dense embeddings returned by the encoder
+// to their first n components, for models trained with Matryoshka
+// representation learning, such as text-embedding-3 and the Gemini embedding
+// models, whose embedding prefixes are embeddings themselves.
+//
+// Both the embeddings of the utterances, as stored, and the embeddings of
+// the queries are truncated, trading accuracy for storage and scoring
+// speed. As with WithDimension, embeddings shorter than n are rejected with
+// an ErrDimensionMismatch. Encoders able to request reduced embeddings from
+// their API, such as the one of the gemini package, save bandwidth as well.
+func WithOutputDimension(n int) Option {
+	return func(r *Router) {
+		if n <= 0 {
+			r.errs = append(r.errs, fmt.Errorf("output dimension %d is not positive", n))
+			return
+		}
+		r.outputDimension = n
+		r.fixedDimension = n
+	}
+}
+
+// WithAutoConcurrency makes NewRouter encode the utterances of the routes
+// with up to maxInFlight concurrent encoder calls, tuning the concurrency
+// as it goes.
+//
+// The concurrency starts at one and is increased by one for as long as it
+// improves the encoding throughput, so that a rate-limited or saturated
+// encoder is not flooded with calls; maxInFlight caps it, for instance at
+// the rate limit of an embedding API. The chosen concurrency is reported to
+// the observer, see Observer.ObserveConcurrency. It only applies to dense
+// embeddings; other kinds of embeddings are encoded serially.
+func WithAutoConcurrency(maxInFlight int) Option {
+	return func(r *Router) {
+		if maxInFlight <= 0 {
+			r.errs = append(r.errs, fmt.Errorf("maximum in-flight calls %d is not positive", maxInFlight))
+			return
+		}
+		r.autoConcurrency = maxInFlight
+	}
+}
+
+// WithSoftmaxTemperature sets the temperature of the softmax turning the
+// scores of the routes into the probabilities of MatchProbabilities. The
+// default is DefaultSoftmaxTemperature.
+//
+// Lower temperatures concentrate the probability on the best routes, higher
+// ones spread it evenly. As cosine similarities lie within [-1, 1], a
+// temperature well below one is usually needed for the best route to stand
+// out.
+func WithSoftmaxTemperature(temperature float64) Option {
+	return func(r *Router) {
+		if temperature <= 0 || math.IsInf(temperature, 0) || math.IsNaN(temperature) {
+			r.errs = append(r.errs, fmt.Errorf("softmax temperature %v is not positive and finite", temperature))
+			return
+		}
+		r.temperature = temperature
+	}
+}
+
+// WithConfidenceBands sets the confidence bands of matches reported by
+// MatchConfidence: matches scoring at least high are ConfidenceHigh, those
+// scoring at least low are ConfidenceLow, and routes scoring below low do not
+// match at all, with Match as with MatchConfidence.
+//
+// The bands apply on top of the threshold of WithAdaptiveThreshold, if any.
+// low must not be greater than high, and the bands do not support
+// LowerIsBetter.
+func WithConfidenceBands(low, high float64) Option {
+	return func(r *Router) {
+		if math.IsNaN(low) || math.IsNaN(high) || math.IsInf(low, 0) || math.IsInf(high, 0) || low > high {
+			r.errs = append(r.errs, fmt.Errorf("confidence bands [%v, %v] are not finite and ordered", low, high))
+			return
+		}
+		r.bands = &confidenceBands{low: low, high: high}
+	}
+}
+
+// WithScoreDirection sets whether higher or lower scores are better. The
+// default is HigherIsBetter.
+//
+// With LowerIsBetter, raw distances can be used as similarity functions,
+// such as EuclideanDistance added with WithCustomSimilarity, without
+// converting them into similarities: the route whose best utterance has the
+// lowest score wins, and the threshold of WithAdaptiveThreshold, if any, is
+// the highest score of a match. The spatial index is not used, and KNN
+// voting, rerankers, encoder fallback and route priors are not supported.
+func WithScoreDirection(direction ScoreDirection) Option {
+	return func(r *Router) {
+		if direction != HigherIsBetter && direction != LowerIsBetter {
+			r.errs = append(r.errs, fmt.Errorf("unknown score direction: %d", direction))
+			return
+		}
+		r.scoreDirection = direction
+	}
+}
+
+// WithQueryPrefix makes the router prepend the given prefix to query
+// utterances before encoding them when matching, such as "query: " for e5
+// models or "search_query: " for nomic-embed-text.
+//
+// Instruction-tuned embedding models are trained with distinct prefixes for
+// queries and for the documents they are compared to; see
+// WithDocumentPrefix for the utterances of the routes. The prefix is not
+// part of the keys of the query cache.
+func WithQueryPrefix(prefix string) Option {
+	return func(r *Router) {
+		r.queryPrefix = prefix
+	}
+}
+
+// WithDocumentPrefix makes the router prepend the given prefix to the
+// utterances of its routes before encoding them when they are stored, such
+// as "passage: " for e5 models or "search_document: " for nomic-embed-text.
+//
+// Utterances are still stored under their own text, without the prefix.
+// The document prefix is recorded in the IndexInfo of the router, as
+// changing it requires encoding the utterances again.
+func WithDocumentPrefix(prefix string) Option {
+	return func(r *Router) {
+		r.documentPrefix = prefix
+	}
+}
+
+// WithTopUtterances sets the number of best scoring utterances of the matched
+// route returned by MatchVerbose. The default is 3.
+func WithTopUtterances(m int) Option {
+	return func(r *Router) {
+		r.topUtteranceCount = m
+	}
+}
+
+// WithRouteCentroids sets whether each route is represented by the centroid
+// of the embeddings of its utterances.
+//
+// Centroids are computed when routes are stored and kept in the store under
+// a key derived from the route name, see CentroidKey. Queries are then
+// compared against one vector per route instead of every utterance, which is
+// faster and less sensitive to outlier utterances. Route centroids require
+// dense embeddings; features relying on individual utterances, such as tags
+// and reranking, see each centroid as a single untagged utterance.
+func WithRouteCentroids(enabled bool) Option {
+	return func(r *Router) {
+		r.centroids = enabled
+	}
+}
+
+// WithParallelRoutes scores the utterances of up to n routes concurrently,
+// each route in its own goroutine.
+//
+// Routes share no mutable state and the index embeddings are loaded from the
+// store before scoring, so scoring does not contend on the store. Results
+// are identical to serial scoring, including how ties between routes are
+// broken. This pays off for expensive similarity functions or routes with
+// many utterances; a value of one or less scores routes serially, which is
+// the default.
+func WithParallelRoutes(n int) Option {
+	return func(r *Router) {
+		r.parallelRoutes = n
+	}
+}
+
+// WithOnDuplicate sets how utterances appearing more than once across the
+// routes are handled when the router is built. The default is
+// DuplicateOverwrite.
+func WithOnDuplicate(mode DuplicateMode) Option {
+	return func(r *Router) {
+		r.onDuplicate = mode
+	}
+}
+
+// WithSpatialIndex sets whether Match finds the best matching utterance with
+// an in-process spatial index instead of scanning every embedding.
+//
+// The index is built from the store's embeddings when the router is built,
+// and rebuilt when routes are added or updated; embeddings modified in the
+// store outside of the router are not seen. Low-dimensional embeddings are
+// indexed in a KD-tree, which beats the linear scan for a few thousand
+// utterances; high-dimensional ones are scanned from memory. Results are
+// identical to the linear scan. The index is only used when routes are
+// scored by the cosine similarity of their best utterance: with custom
+// similarity functions, KNN voting, reranking or query expansion, Match
+// scans the embeddings linearly.
+func WithSpatialIndex(enabled bool) Option {
+	return func(r *Router) {
+		r.spatialEnabled = enabled
+	}
+}
+
+// WithHNSW makes Match find the best matching utterance with an in-process
+// HNSW (hierarchical navigable small world) graph instead of scanning every
+// embedding.
+//
+// Each utterance is linked to its m nearest neighbors, 2*m in the bottom
+// layer of the graph; efConstruction and efSearch are the number of
+// candidates kept when inserting utterances and when searching the graph.
+// The graph is built like the index of WithSpatialIndex, which it replaces,
+// and is used under the same conditions.
+//
+// The search is approximate: the utterance found is not always the most
+// similar one, so Match may return another route than the linear scan.
+// Larger values of m and efSearch trade speed for recall, which typically
+// exceeds 95% with m=16, efConstruction=200 and efSearch=50. MatchExact
+// always scans every embedding.
+func WithHNSW(m, efConstruction, efSearch int) Option {
+	return func(r *Router) {
+		if m < 2 || efConstruction < 1 || efSearch < 1 {
+			r.errs = append(r.errs, fmt.Errorf(
+				"invalid HNSW parameters: m=%d, efConstruction=%d, efSearch=%d",
+				m,
+				efConstruction,
+				efSearch,
+			))
+			return
+		}
+		r.hnsw = &hnswParams{
+			m:              m,
+			efConstruction: efConstruction,
+			efSearch:       efSearch,
+		}
+	}
+}
+
+// WithNaNPolicy sets how NaN and infinite scores returned by similarity
+// functions are handled.
+//
+// Such scores compare false to any other, so a route scored NaN silently
+// never wins. The default, NaNError, surfaces them as an ErrInvalidScore.
+func WithNaNPolicy(policy NaNPolicy) Option {
+	return func(r *Router) {
+		r.nanPolicy = policy
+	}
+}
+
+// WithKeyNormalization sets whether the keys of utterances in the store and
+// the query cache are normalized, see NormalizeKey.
+//
+// Utterances differing only in case or diacritics, such as "Café" and
+// "cafe", then share a single stored embedding and cached query embedding:
+// the embedding of the last one stored is used for all of them. Utterances
+// are otherwise unmodified; they are still encoded and reported as given.
+func WithKeyNormalization(enabled bool) Option {
+	return func(r *Router) {
+		r.normalizeKeys = enabled
+	}
+}
+
+// WithEncoderFallback sets whether Match falls back to keyword matching when
+// the encoder fails to encode the query, such as during an outage of an
+// embedding API.
+//
+// The fallback scores each route by the best jaccard similarity between the
+// words of the query and the words of one of its utterances, so the router
+// stays partially functional without its encoder. Scores of the fallback are
+// not comparable to the scores of embeddings. Errors other than encoding
+// errors, and encoding errors caused by the cancellation of the caller's
+// context, are returned as is.
+func WithEncoderFallback(enabled bool) Option {
+	return func(r *Router) {
+		r.encoderFallback = enabled
+	}
+}
+
+// WithExactMatchShortcut sets whether Match, before encoding the query,
+// checks whether it equals the text of an utterance and, if so, returns the
+// route of that utterance with the best possible score, 1.0 or 0.0 with
+// LowerIsBetter, without calling the encoder.
+//
+// Texts are compared in their NormalizeKey form with whitespace collapsed,
+// so known commands are routed with certainty and without an encoder call.
+// The first matching utterance in the order of the routes wins, and the
+// threshold does not apply to exact matches.
+func WithExactMatchShortcut(enabled bool) Option {
+	return func(r *Router) {
+		r.exactShortcut = enabled
+	}
+}
+
+// WithPriorMode sets how the priors of routes, see Route.Prior, are applied
+// to their aggregated scores. The default is PriorAdditive.
+func WithPriorMode(mode PriorMode) Option {
+	return func(r *Router) {
+		r.priorMode = mode
+	}
+}
+
+// WithAggregation sets how the scores of the utterances of a route are
+// aggregated into the score of the route. The default is AggregationMax.
+//
+// The best score is sensitive to a single lucky utterance, and the mean
+// score to many poor ones; see WithTrimmedMeanAggregation for a middle
+// ground. KNN voting, see WithKNNVoting, takes precedence.
+func WithAggregation(mode AggregationMode) Option {
+	return func(r *Router) {
+		if mode < AggregationMax || mode > AggregationTrimmedMean {
+			r.errs = append(r.errs, fmt.Errorf(
+				"aggregation mode %d is not a valid AggregationMode",
+				mode,
+			))
+			return
+		}
+		r.aggregation = mode
+	}
+}
+
+// WithTrimmedMeanAggregation aggregates the scores of the utterances of each
+// route with AggregationTrimmedMean, dropping the given fraction of the best
+// scores and the same fraction of the worst scores before averaging them.
+//
+// The fraction must be in [0, 0.5); a fraction of zero is the plain mean.
+func WithTrimmedMeanAggregation(fraction float64) Option {
+	return func(r *Router) {
+		if fraction < 0 || fraction >= 0.5 {
+			r.errs = append(r.errs, fmt.Errorf(
+				"trim fraction %v is not in [0, 0.5)",
+				fraction,
+			))
+			return
+		}
+		r.aggregation = AggregationTrimmedMean
+		r.trimFraction = fraction
+	}
+}
+
+// WithMaxCandidates caps the number of utterances whose embeddings Match
+// fetches from the store and scores to n, as a guard on its latency with
+// huge indexes.
+//
+// The candidates are the first utterances of each route, taken in turn
+// across the routes: the first utterance of every route, then the second
+// one, and so on until n utterances are taken, so that every route is
+// represented when n is at least the number of routes. Match returns the
+// best route among them, trading accuracy for latency: a route whose best
+// utterance is not among its first ones, or a route left out entirely when
+// n is less than the number of routes, can lose a match it would otherwise
+// win. Listing the most representative utterances of each route first
+// limits the loss, and routes that must always be scored can be pinned with
+// Route.Priority. The alternative embeddings of the utterances taken, if
+// any, are fetched along with them. MatchExact and the other matching methods
+// are not capped.
+func WithMaxCandidates(n int) Option {
+	return func(r *Router) {
+		if n <= 0 {
+			r.errs = append(r.errs, fmt.Errorf("max candidates must be positive, got %d", n))
+			return
+		}
+		r.maxCandidates = n
+	}
+}
+
+// WithTwoStageScoring makes Match score routes in two stages: every route is
+// first ranked by the cosine similarity of the leading prefixDims dimensions
+// of the embeddings, then only the candidateCount best routes are scored
+// with the full embeddings and the router's similarity functions.
+//
+// Comparing a short prefix is cheap on high-dimensional embeddings, and on
+// well-separated routes the best route is almost always among the first
+// candidates. The prefilter is approximate: MatchExact and the other match
+// methods always score every route. It is ignored when the spatial index
+// of WithSpatialIndex or WithHNSW is used.
+func WithTwoStageScoring(prefixDims, candidateCount int) Option {
+	return func(r *Router) {
+		if prefixDims <= 0 || candidateCount <= 0 {
+			r.errs = append(r.errs, fmt.Errorf(
+				"invalid two-stage scoring parameters: prefixDims %d and candidateCount %d must be positive",
+				prefixDims,
+				candidateCount,
+			))
+			return
+		}
+		r.twoStage = &twoStageParams{prefixDims: prefixDims, candidates: candidateCount}
+	}
+}
@@ -0,0 +1,52 @@
+package semanticrouter
+
+import "sync"
+
+// scoreRoutesParallel computes the score of the query against each entry of
+// the index, scoring the entries of at most parallelRoutes routes
+// concurrently.
+//
+// The scores are returned in the order of the index, like when scoring
+// serially, so the aggregated scores and the tie-breaking between routes do
+// not depend on scheduling.
+func (r *Router) scoreRoutesParallel(q query, index []indexEntry) ([]entryScore, error) {
+	scored := make([]entryScore, len(index))
+	valid := make([]bool, len(index))
+	errs := make([]error, len(index))
+	sem := make(chan struct{}, r.parallelRoutes)
+	var wg sync.WaitGroup
+	for start := 0; start < len(index); {
+		end := start + 1
+		for end < len(index) && index[end].route == index[start].route {
+			end++
+		}
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(start, end int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			for i := start; i < end; i++ {
+				entry := index[i]
+				if q.kind == denseEmbeddings && entry.vec.Len() != q.vec.Len() {
+					continue
+				}
+				scored[i], valid[i], errs[i] = r.scoreEntry(q, entry)
+				if errs[i] != nil {
+					return
+				}
+			}
+		}(start, end)
+		start = end
+	}
+	wg.Wait()
+	compacted := scored[:0]
+	for i, es := range scored {
+		if errs[i] != nil {
+			return nil, errs[i]
+		}
+		if valid[i] {
+			compacted = append(compacted, es)
+		}
+	}
+	return compacted, nil
+}
@@ -0,0 +1,104 @@
+package semanticrouter
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/conneroisu/go-semantic-router/domain"
+	"github.com/conneroisu/go-semantic-router/stores/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newRandomRoutes returns routes of random utterance embeddings of the given
+// dimension, along with an encoder knowing them and the "query" utterance.
+func newRandomRoutes(routes, utterances, dimension int) ([]Route, *mockEncoder) {
+	rnd := rand.New(rand.NewSource(1))
+	encoder := &mockEncoder{embeddings: make(map[string][]float64)}
+	randomVector := func() []float64 {
+		v := make([]float64, dimension)
+		for i := range v {
+			v[i] = rnd.Float64()
+		}
+		return v
+	}
+	var rs []Route
+	for i := 0; i < routes; i++ {
+		route := Route{Name: fmt.Sprintf("route-%d", i)}
+		for j := 0; j < utterances; j++ {
+			utterance := fmt.Sprintf("utterance %d of route %d", j, i)
+			encoder.embeddings[utterance] = randomVector()
+			route.Utterances = append(route.Utterances, domain.Utterance{Utterance: utterance})
+		}
+		rs = append(rs, route)
+	}
+	encoder.embeddings["query"] = randomVector()
+	return rs, encoder
+}
+
+// TestWithParallelRoutes tests that scoring routes concurrently gives the
+// same results as scoring them serially.
+func TestWithParallelRoutes(t *testing.T) {
+	ctx := context.Background()
+	routes, encoder := newRandomRoutes(16, 8, 32)
+	serial, err := NewRouter(routes, encoder, memory.NewStore())
+	require.NoError(t, err)
+	parallel, err := NewRouter(routes, encoder, memory.NewStore(), WithParallelRoutes(4))
+	require.NoError(t, err)
+
+	want, err := serial.ScoreAll(ctx, "query")
+	require.NoError(t, err)
+	got, err := parallel.ScoreAll(ctx, "query")
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+
+	wantRoute, wantScore, err := serial.Match(ctx, "query")
+	require.NoError(t, err)
+	gotRoute, gotScore, err := parallel.Match(ctx, "query")
+	require.NoError(t, err)
+	assert.Equal(t, wantRoute, gotRoute)
+	assert.Equal(t, wantScore, gotScore)
+
+	// Ties are broken in favor of the first route, as when scoring serially.
+	tied, err := NewRouter(
+		[]Route{
+			{Name: "first", Utterances: []domain.Utterance{{Utterance: "how's the weather today?"}}},
+			{Name: "second", Utterances: []domain.Utterance{{Utterance: "how's the weather today?"}}},
+		},
+		newTestEncoder(),
+		memory.NewStore(),
+		WithParallelRoutes(2),
+	)
+	require.NoError(t, err)
+	route, _, err := tied.Match(ctx, "is it raining outside?")
+	require.NoError(t, err)
+	assert.Equal(t, "first", route)
+}
+
+// BenchmarkParallelRoutes compares scoring routes serially and concurrently
+// against an already loaded index.
+func BenchmarkParallelRoutes(b *testing.B) {
+	ctx := context.Background()
+	routes, encoder := newRandomRoutes(32, 100, 512)
+	for _, n := range []int{1, 4, 8} {
+		router, err := NewRouter(
+			routes,
+			encoder,
+			memory.NewStore(),
+			WithPearsonCorrelation(1),
+			WithParallelRoutes(n),
+		)
+		require.NoError(b, err)
+		qs, err := router.encodeQueries(ctx, "query")
+		require.NoError(b, err)
+		index, err := router.loadIndex(ctx)
+		require.NoError(b, err)
+		b.Run(fmt.Sprintf("routes=%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				_, _ = router.rankIndex(ctx, "query", qs, index, false)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package semanticrouter
+
+import (
+	"fmt"
+	"math"
+)
+
+// checkCoefficients checks the coefficients of the similarity functions of
+// the router and of its routes.
+//
+// Coefficients must be finite. A negative coefficient subtracts the score of
+// its function, see WithPenalty, but when scores are higher-is-better a set
+// of functions without any positive coefficient is rejected, since no score
+// would then be positive and nothing could ever match. The names of the
+// functions of a set must be unique, as their scores are broken down by
+// name, see MatchN.
+func (r *Router) checkCoefficients() error {
+	err := r.checkFuncCoefficients(r.biFuncCoefficients)
+	if err != nil {
+		return err
+	}
+	for _, route := range r.Routes {
+		fns, ok := r.routeFuncs[route.Name]
+		if !ok {
+			continue
+		}
+		err = r.checkFuncCoefficients(fns)
+		if err != nil {
+			return fmt.Errorf("route %q: %w", route.Name, err)
+		}
+	}
+	return nil
+}
+
+// checkFuncCoefficients checks the coefficients of the given similarity
+// functions, see checkCoefficients.
+func (r *Router) checkFuncCoefficients(fns []biFuncCoefficient) error {
+	var positive bool
+	names := make(map[string]bool, len(fns))
+	for _, bf := range fns {
+		if names[bf.name] {
+			return fmt.Errorf("duplicate similarity function name: %q", bf.name)
+		}
+		names[bf.name] = true
+		if math.IsNaN(bf.coefficient) || math.IsInf(bf.coefficient, 0) {
+			return fmt.Errorf("coefficient of similarity function %q must be finite, got %v", bf.name, bf.coefficient)
+		}
+		positive = positive || bf.coefficient > 0
+	}
+	if len(fns) > 0 && !positive && r.scoreDirection == HigherIsBetter {
+		return fmt.Errorf("similarity functions have no positive coefficient, so no score can be positive")
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package semanticrouter
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/conneroisu/go-semantic-router/domain"
+	"github.com/conneroisu/go-semantic-router/stores/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestWithPenalty tests that a penalty demotes the route that would win
+// without it.
+func TestWithPenalty(t *testing.T) {
+	ctx := context.Background()
+	router, err := NewRouter(newTestRoutes(), newTestEncoder(), memory.NewStore(), WithCosineSimilarity(1.0))
+	require.NoError(t, err)
+	route, _, err := router.Match(ctx, "is it raining outside?")
+	require.NoError(t, err)
+	assert.Equal(t, "chitchat", route)
+
+	// test_first_component scores the weather dimension shared by the query
+	// and the chitchat utterances.
+	router, err = NewRouter(
+		newTestRoutes(),
+		newTestEncoder(),
+		memory.NewStore(),
+		WithCosineSimilarity(1.0),
+		WithPenalty("test_first_component", 2.0),
+	)
+	require.NoError(t, err)
+	route, score, err := router.Match(ctx, "is it raining outside?")
+	require.NoError(t, err)
+	assert.Equal(t, "politics", route)
+	cosine := 0.01 / (math.Sqrt(0.65) * math.Sqrt(1.01))
+	assert.InDelta(t, cosine, score, 1e-9)
+	assert.Equal(t, []SimilaritySpec{
+		{Name: SimilarityCosine, Coefficient: 1.0},
+		{Name: "test_first_component", Coefficient: -2.0},
+	}, router.ExportConfig().Similarities)
+}
+
+// TestCheckCoefficients tests that invalid coefficients are rejected.
+func TestCheckCoefficients(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []Option
+	}{
+		{name: "zero penalty", opts: []Option{WithCosineSimilarity(1.0), WithPenalty(SimilarityDotProduct, 0)}},
+		{name: "negative penalty", opts: []Option{WithCosineSimilarity(1.0), WithPenalty(SimilarityDotProduct, -1.0)}},
+		{name: "NaN penalty", opts: []Option{WithCosineSimilarity(1.0), WithPenalty(SimilarityDotProduct, math.NaN())}},
+		{name: "unknown penalty", opts: []Option{WithCosineSimilarity(1.0), WithPenalty("unknown", 1.0)}},
+		{name: "penalty only", opts: []Option{WithPenalty(SimilarityDotProduct, 1.0)}},
+		{name: "negative only", opts: []Option{WithCosineSimilarity(-1.0), WithDotProduct(0)}},
+		{name: "infinite", opts: []Option{WithCosineSimilarity(math.Inf(1))}},
+		{name: "duplicate name", opts: []Option{WithCosineSimilarity(1.0), WithCosineSimilarity(0.5)}},
+		{name: "penalized function", opts: []Option{WithCosineSimilarity(1.0), WithPenalty(SimilarityCosine, 0.5)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewRouter(newTestRoutes(), newTestEncoder(), memory.NewStore(), tt.opts...)
+			assert.Error(t, err)
+		})
+	}
+
+	t.Run("route", func(t *testing.T) {
+		routes := newTestRoutes()
+		routes[1].Similarities = []SimilaritySpec{{Name: SimilarityCosine, Coefficient: -1.0}}
+		_, err := NewRouter(routes, newTestEncoder(), memory.NewStore())
+		assert.ErrorContains(t, err, `route "politics"`)
+	})
+
+	t.Run("route duplicate name", func(t *testing.T) {
+		routes := newTestRoutes()
+		routes[1].Similarities = []SimilaritySpec{
+			{Name: SimilarityCosine, Coefficient: 1.0},
+			{Name: SimilarityCosine, Coefficient: 0.5},
+		}
+		_, err := NewRouter(routes, newTestEncoder(), memory.NewStore())
+		assert.ErrorContains(t, err, `route "politics": duplicate similarity function name: "cosine"`)
+	})
+
+	t.Run("lower is better", func(t *testing.T) {
+		routes := []Route{{Name: "weather", Utterances: []domain.Utterance{{Utterance: "lovely weather today"}}}}
+		_, err := NewRouter(routes, newTestEncoder(), memory.NewStore(), WithScoreDirection(LowerIsBetter), WithCosineSimilarity(-1.0))
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,14 @@
+package semanticrouter
+
+// queryInput returns the text encoded for the given query utterance, the
+// utterance preceded by the query prefix, see WithQueryPrefix.
+func (r *Router) queryInput(utterance string) string {
+	return r.queryPrefix + utterance
+}
+
+// documentInput returns the text encoded for the given utterance of a
+// route, the utterance preceded by the document prefix, see
+// WithDocumentPrefix.
+func (r *Router) documentInput(utterance string) string {
+	return r.documentPrefix + utterance
+}
@@ -0,0 +1,80 @@
+package semanticrouter
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/conneroisu/go-semantic-router/stores/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingEncoder is an encoder recording the texts it encodes, encoding
+// them as the test encoder once their prefix is removed.
+type recordingEncoder struct {
+	mu     sync.Mutex
+	inputs []string
+}
+
+// Encode records the text and encodes it without its prefix.
+func (e *recordingEncoder) Encode(ctx context.Context, text string) ([]float64, error) {
+	e.mu.Lock()
+	e.inputs = append(e.inputs, text)
+	e.mu.Unlock()
+	_, utterance, found := strings.Cut(text, ": ")
+	if !found {
+		utterance = text
+	}
+	return newTestEncoder().Encode(ctx, utterance)
+}
+
+// TestPrefixes tests that the document prefix is prepended to the
+// utterances of the routes and the query prefix to queries, independently.
+func TestPrefixes(t *testing.T) {
+	ctx := context.Background()
+	var utterances []string
+	for _, route := range newTestRoutes() {
+		for _, utter := range route.Utterances {
+			utterances = append(utterances, utter.Utterance)
+		}
+	}
+	prefixed := func(prefix string, texts ...string) []string {
+		var out []string
+		for _, text := range texts {
+			out = append(out, prefix+text)
+		}
+		return out
+	}
+	testCases := []struct {
+		name                        string
+		opts                        []Option
+		queryPrefix, documentPrefix string
+	}{
+		{name: "both", opts: []Option{WithQueryPrefix("query: "), WithDocumentPrefix("passage: ")}, queryPrefix: "query: ", documentPrefix: "passage: "},
+		{name: "query", opts: []Option{WithQueryPrefix("search_query: ")}, queryPrefix: "search_query: "},
+		{name: "document", opts: []Option{WithDocumentPrefix("search_document: ")}, documentPrefix: "search_document: "},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			encoder := &recordingEncoder{}
+			store := memory.NewStore()
+			router, err := NewRouter(newTestRoutes(), encoder, store, append(tc.opts, WithQueryCache(4))...)
+			require.NoError(t, err)
+			assert.Equal(t, prefixed(tc.documentPrefix, utterances...), encoder.inputs)
+
+			route, _, err := router.Match(ctx, "is it raining outside?")
+			require.NoError(t, err)
+			assert.Equal(t, "chitchat", route)
+			assert.Equal(t, tc.queryPrefix+"is it raining outside?", encoder.inputs[len(encoder.inputs)-1])
+
+			// Utterances are stored under their own text.
+			_, err = store.Get(ctx, "lovely weather today")
+			assert.NoError(t, err)
+			info, err := router.IndexInfo(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, tc.documentPrefix, info.Prefix)
+		})
+	}
+}
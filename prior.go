@@ -0,0 +1,46 @@
+package semanticrouter
+
+import "math"
+
+// PriorMode is how the log-priors of routes are applied to their aggregated
+// scores, see Route.Prior.
+type PriorMode int
+
+const (
+	// PriorAdditive adds the log-prior of a route to its score.
+	PriorAdditive PriorMode = iota
+	// PriorMultiplicative multiplies the score of a route by the exponential
+	// of its log-prior, so a log-prior of zero is a factor of one.
+	PriorMultiplicative
+)
+
+// hasPriors reports whether any route of the router has a non-zero prior.
+func (r *Router) hasPriors() bool {
+	for _, route := range r.Routes {
+		if route.Prior != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// applyPriors applies the priors of the routes to the given aggregated
+// scores, according to the router's prior mode.
+func (r *Router) applyPriors(scores []routeScore) {
+	if len(scores) == 0 || !r.hasPriors() {
+		return
+	}
+	priors := make(map[string]float64, len(r.Routes))
+	for _, route := range r.Routes {
+		priors[route.Name] = route.Prior
+	}
+	for i := range scores {
+		prior := priors[scores[i].route]
+		switch r.priorMode {
+		case PriorMultiplicative:
+			scores[i].score *= math.Exp(prior)
+		default:
+			scores[i].score += prior
+		}
+	}
+}
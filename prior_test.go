@@ -0,0 +1,45 @@
+package semanticrouter
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/conneroisu/go-semantic-router/stores/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRoutePrior tests that a route prior flips the winner of a near-tie, in
+// both prior modes.
+func TestRoutePrior(t *testing.T) {
+	ctx := context.Background()
+	encoder := newTestEncoder()
+	encoder.embeddings["borderline"] = []float64{0.5, 0.5, 0.0}
+
+	router, err := NewRouter(newTestRoutes(), encoder, memory.NewStore())
+	require.NoError(t, err)
+	want, err := router.ScoreAll(ctx, "borderline")
+	require.NoError(t, err)
+	route, _, err := router.Match(ctx, "borderline")
+	require.NoError(t, err)
+	assert.Equal(t, "chitchat", route)
+
+	routes := newTestRoutes()
+	routes[1].Prior = 0.1
+	for mode, prior := range map[PriorMode]float64{
+		PriorAdditive:       want["politics"] + 0.1,
+		PriorMultiplicative: want["politics"] * math.Exp(0.1),
+	} {
+		router, err := NewRouter(routes, encoder, memory.NewStore(), WithPriorMode(mode))
+		require.NoError(t, err)
+		route, score, err := router.Match(ctx, "borderline")
+		require.NoError(t, err)
+		assert.Equal(t, "politics", route)
+		assert.InDelta(t, prior, score, 1e-12)
+
+		scores, err := router.ScoreAll(ctx, "borderline")
+		require.NoError(t, err)
+		assert.Equal(t, want["chitchat"], scores["chitchat"])
+	}
+}
@@ -0,0 +1,70 @@
+package semanticrouter
+
+import (
+	"context"
+	"math"
+)
+
+// DefaultSoftmaxTemperature is the default temperature of the softmax of
+// MatchProbabilities, see WithSoftmaxTemperature.
+const DefaultSoftmaxTemperature = 1.0
+
+// MatchProbabilities returns the probability of every route for the given
+// utterance, the softmax of the aggregated scores of ScoreAll divided by the
+// temperature set with WithSoftmaxTemperature.
+//
+// The probabilities sum to one and are ordered as the scores, so the most
+// probable route is the best scoring one of ScoreAll, which is not always
+// the one Match returns, and the threshold is not applied. With
+// LowerIsBetter, the softmax is taken over the negated scores.
+// It returns an empty map if no route is scored.
+func (r *Router) MatchProbabilities(
+	ctx context.Context,
+	utterance string,
+) (map[string]float64, error) {
+	scores, err := r.ScoreAll(ctx, utterance)
+	if err != nil {
+		return nil, err
+	}
+	if r.scoreDirection == LowerIsBetter {
+		for route, score := range scores {
+			scores[route] = -score
+		}
+	}
+	return softmax(scores, r.softmaxTemperature()), nil
+}
+
+// softmaxTemperature returns the temperature of the softmax of
+// MatchProbabilities.
+func (r *Router) softmaxTemperature() float64 {
+	if r.temperature == 0 {
+		return DefaultSoftmaxTemperature
+	}
+	return r.temperature
+}
+
+// softmax returns the softmax of the given scores divided by the given
+// temperature.
+//
+// The highest score is subtracted from every score before exponentiating,
+// so that large scores or low temperatures do not overflow.
+func softmax(scores map[string]float64, temperature float64) map[string]float64 {
+	probabilities := make(map[string]float64, len(scores))
+	if len(scores) == 0 {
+		return probabilities
+	}
+	highest := math.Inf(-1)
+	for _, score := range scores {
+		highest = math.Max(highest, score)
+	}
+	var sum float64
+	for route, score := range scores {
+		p := math.Exp((score - highest) / temperature)
+		probabilities[route] = p
+		sum += p
+	}
+	for route, p := range probabilities {
+		probabilities[route] = p / sum
+	}
+	return probabilities
+}
@@ -0,0 +1,58 @@
+package semanticrouter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/conneroisu/go-semantic-router/stores/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMatchProbabilities tests that route probabilities sum to one, are
+// ordered as the raw scores and sharpen as the temperature decreases.
+func TestMatchProbabilities(t *testing.T) {
+	ctx := context.Background()
+	router, err := NewRouter(newTestRoutes(), newTestEncoder(), memory.NewStore())
+	require.NoError(t, err)
+	cold, err := NewRouter(newTestRoutes(), newTestEncoder(), memory.NewStore(), WithSoftmaxTemperature(0.1))
+	require.NoError(t, err)
+
+	for _, utterance := range []string{
+		"is it raining outside?",
+		"what about the election?",
+	} {
+		scores, err := router.ScoreAll(ctx, utterance)
+		require.NoError(t, err)
+		probabilities, err := router.MatchProbabilities(ctx, utterance)
+		require.NoError(t, err)
+		require.Len(t, probabilities, len(scores))
+
+		var sum float64
+		for route, p := range probabilities {
+			sum += p
+			for other := range probabilities {
+				if scores[route] > scores[other] {
+					assert.Greater(t, p, probabilities[other])
+				}
+			}
+		}
+		assert.InDelta(t, 1.0, sum, 1e-9)
+
+		best, _, err := router.Match(ctx, utterance)
+		require.NoError(t, err)
+		sharp, err := cold.MatchProbabilities(ctx, utterance)
+		require.NoError(t, err)
+		assert.Greater(t, sharp[best], probabilities[best])
+	}
+
+	_, err = NewRouter(newTestRoutes(), newTestEncoder(), memory.NewStore(), WithSoftmaxTemperature(0))
+	assert.Error(t, err)
+}
+
+// TestSoftmax tests that the softmax does not overflow on large scores.
+func TestSoftmax(t *testing.T) {
+	assert.Empty(t, softmax(nil, 1))
+	probabilities := softmax(map[string]float64{"a": 1000, "b": 1000}, 0.01)
+	assert.Equal(t, map[string]float64{"a": 0.5, "b": 0.5}, probabilities)
+}
@@ -0,0 +1,27 @@
+package semanticrouter
+
+import "context"
+
+// encodeCached encodes the given query utterance, looking it up in the query
+// cache first if one is configured, see WithQueryCache and
+// WithQueryCacheTTL.
+func (r *Router) encodeCached(
+	ctx context.Context,
+	utterance string,
+) ([]float64, error) {
+	if r.queryCache == nil && r.queryCacheTTL <= 0 {
+		return r.encode(ctx, r.queryInput(utterance))
+	}
+	key := r.key(utterance)
+	if em, ok := r.getCachedQuery(ctx, key); ok {
+		r.notify().ObserveQueryCache(true)
+		return em, nil
+	}
+	r.notify().ObserveQueryCache(false)
+	em, err := r.encode(ctx, r.queryInput(utterance))
+	if err != nil {
+		return nil, err
+	}
+	r.putCachedQuery(ctx, key, em)
+	return em, nil
+}
@@ -0,0 +1,130 @@
+package semanticrouter
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/conneroisu/go-semantic-router/stores/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingEncoder is an encoder counting the calls made to it.
+type countingEncoder struct {
+	Encoder
+	calls atomic.Int64
+}
+
+// Encode counts the call before delegating to the underlying encoder.
+func (c *countingEncoder) Encode(
+	ctx context.Context,
+	utterance string,
+) ([]float64, error) {
+	c.calls.Add(1)
+	return c.Encoder.Encode(ctx, utterance)
+}
+
+// cacheObserver is an observer counting query cache hits and misses.
+type cacheObserver struct {
+	NopObserver
+	mu           sync.Mutex
+	hits, misses int
+}
+
+// ObserveQueryCache counts the hit or miss.
+func (o *cacheObserver) ObserveQueryCache(hit bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if hit {
+		o.hits++
+	} else {
+		o.misses++
+	}
+}
+
+// TestWithQueryCache tests that a repeated query is only encoded once.
+func TestWithQueryCache(t *testing.T) {
+	ctx := context.Background()
+	encoder := &countingEncoder{Encoder: newTestEncoder()}
+	observer := &cacheObserver{}
+	router, err := NewRouter(
+		newTestRoutes(),
+		encoder,
+		memory.NewStore(),
+		WithQueryCache(1),
+		WithObserver(observer),
+	)
+	require.NoError(t, err)
+	built := encoder.calls.Load()
+
+	for i := 0; i < 3; i++ {
+		route, _, err := router.Match(ctx, "is it raining outside?")
+		require.NoError(t, err)
+		assert.Equal(t, "chitchat", route)
+	}
+	assert.Equal(t, built+1, encoder.calls.Load())
+	assert.Equal(t, 2, observer.hits)
+	assert.Equal(t, 1, observer.misses)
+
+	_, _, err = router.Match(ctx, "what about the election?")
+	require.NoError(t, err)
+	_, _, err = router.Match(ctx, "is it raining outside?")
+	require.NoError(t, err)
+	assert.Equal(t, built+3, encoder.calls.Load())
+	assert.Equal(t, 3, observer.misses)
+}
+
+// TestWithQueryCacheTTL tests that cached query embeddings are shared
+// through a TTLStore and encoded again once expired.
+func TestWithQueryCacheTTL(t *testing.T) {
+	ctx := context.Background()
+	const ttl = 50 * time.Millisecond
+	store := memory.NewStore()
+	t.Cleanup(func() { _ = store.Close() })
+	encoder := &countingEncoder{Encoder: newTestEncoder()}
+	router, err := NewRouter(
+		newTestRoutes(),
+		encoder,
+		store,
+		WithQueryCache(4),
+		WithQueryCacheTTL(ttl),
+	)
+	require.NoError(t, err)
+	built := encoder.calls.Load()
+
+	_, _, err = router.Match(ctx, "is it raining outside?")
+	require.NoError(t, err)
+	_, _, err = router.Match(ctx, "is it raining outside?")
+	require.NoError(t, err)
+	assert.Equal(t, built+1, encoder.calls.Load())
+	em, err := store.Get(ctx, QueryCacheKey("is it raining outside?"))
+	require.NoError(t, err)
+	assert.Equal(t, []float64{0.8, 0.0, 0.1}, em)
+
+	// A router sharing the store reuses the cached embedding.
+	other := &countingEncoder{Encoder: newTestEncoder()}
+	shared, err := NewRouter(newTestRoutes(), other, store, WithQueryCacheTTL(ttl))
+	require.NoError(t, err)
+	otherBuilt := other.calls.Load()
+	route, _, err := shared.Match(ctx, "is it raining outside?")
+	require.NoError(t, err)
+	assert.Equal(t, "chitchat", route)
+	assert.Equal(t, otherBuilt, other.calls.Load())
+
+	time.Sleep(2 * ttl)
+	_, err = store.Get(ctx, QueryCacheKey("is it raining outside?"))
+	assert.Error(t, err)
+	_, _, err = router.Match(ctx, "is it raining outside?")
+	require.NoError(t, err)
+	assert.Equal(t, built+2, encoder.calls.Load())
+
+	// The embeddings of the routes do not expire.
+	_, err = store.Get(ctx, "lovely weather today")
+	assert.NoError(t, err)
+
+	_, err = NewRouter(newTestRoutes(), newTestEncoder(), memory.NewStore(), WithQueryCacheTTL(0))
+	assert.Error(t, err)
+}
@@ -0,0 +1,86 @@
+package semanticrouter
+
+import (
+	"context"
+	"sort"
+)
+
+// RankRoutes returns the score of every route for the given utterance,
+// best first.
+//
+// The scores are the ones returned by ScoreAll; routes with the same score
+// are sorted by name. The Utterance of each result is the given utterance.
+func (r *Router) RankRoutes(
+	ctx context.Context,
+	utterance string,
+) ([]MatchResult, error) {
+	scores, err := r.ScoreAll(ctx, utterance)
+	if err != nil {
+		return nil, err
+	}
+	ranked := make([]MatchResult, 0, len(scores))
+	for route, score := range scores {
+		ranked = append(ranked, MatchResult{
+			Utterance: utterance,
+			Route:     route,
+			Score:     score,
+		})
+	}
+	r.sortResults(ranked)
+	return ranked, nil
+}
+
+// MatchN returns the n routes best matching the given utterance, best first,
+// each along with the breakdown of its score per similarity function.
+//
+// Only routes Match would accept are returned: routes whose score is
+// positive and reaches the threshold, unless the router uses LowerIsBetter,
+// and whose required keywords the utterance contains. Routes with the same
+// score are sorted by name. The Breakdown of each result maps the name of
+// each similarity function to its unweighted score against the best scoring
+// utterance of the route, so that, unless utterance boosts, route priors or
+// an aggregation other than AggregationMax are used, the sum of the scores
+// weighted by the coefficients of the functions is the score of the route.
+func (r *Router) MatchN(
+	ctx context.Context,
+	utterance string,
+	n int,
+) ([]MatchResult, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	scored, err := r.scoreUtterance(ctx, utterance, nil)
+	if err != nil {
+		return nil, err
+	}
+	var ranked []MatchResult
+	for _, rs := range scored.scores {
+		if !r.accepts(rs.score) {
+			continue
+		}
+		ranked = append(ranked, MatchResult{
+			Utterance: utterance,
+			Route:     rs.route,
+			Score:     rs.score,
+		})
+	}
+	r.sortResults(ranked)
+	ranked = ranked[:min(max(n, 0), len(ranked))]
+	for i := range ranked {
+		ranked[i].Breakdown, err = r.routeBreakdown(scored.qs, scored.index, ranked[i].Route)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return ranked, nil
+}
+
+// sortResults sorts the given results best first, breaking ties by route
+// name.
+func (r *Router) sortResults(results []MatchResult) {
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return r.better(results[i].Score, results[j].Score)
+		}
+		return results[i].Route < results[j].Route
+	})
+}
@@ -0,0 +1,76 @@
+package semanticrouter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/conneroisu/go-semantic-router/domain"
+	"github.com/conneroisu/go-semantic-router/stores/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRankRoutes tests that routes are ranked by score, then by name.
+func TestRankRoutes(t *testing.T) {
+	ctx := context.Background()
+	routes := append(newTestRoutes(),
+		Route{Name: "weather", Utterances: []domain.Utterance{{Utterance: "how's the weather today?"}}},
+		Route{Name: "forecast", Utterances: []domain.Utterance{{Utterance: "how's the weather today?"}}},
+	)
+	router, err := NewRouter(routes, newTestEncoder(), memory.NewStore())
+	require.NoError(t, err)
+
+	ranked, err := router.RankRoutes(ctx, "is it raining outside?")
+	require.NoError(t, err)
+	var names []string
+	for _, result := range ranked {
+		names = append(names, result.Route)
+		assert.Equal(t, "is it raining outside?", result.Utterance)
+	}
+	assert.Equal(t, []string{"chitchat", "forecast", "weather", "politics"}, names)
+	assert.Equal(t, ranked[0].Score, ranked[1].Score)
+	assert.Greater(t, ranked[2].Score, ranked[3].Score)
+}
+
+// TestMatchN tests that the best routes are returned with a breakdown of
+// their score which sums, weighted by the coefficients, to the score.
+func TestMatchN(t *testing.T) {
+	ctx := context.Background()
+	router, err := NewRouter(
+		newTestRoutes(),
+		newTestEncoder(),
+		memory.NewStore(),
+		WithCosineSimilarity(0.5),
+		WithDotProduct(0.25),
+	)
+	require.NoError(t, err)
+
+	results, err := router.MatchN(ctx, "is it raining outside?", 5)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, "chitchat", results[0].Route)
+	assert.Greater(t, results[0].Score, results[1].Score)
+	for _, result := range results {
+		assert.Equal(t, "is it raining outside?", result.Utterance)
+		require.Len(t, result.Breakdown, 2)
+		var total float64
+		for _, info := range router.SimilarityConfig() {
+			require.Contains(t, result.Breakdown, info.Name)
+			total += info.Coefficient * result.Breakdown[info.Name]
+		}
+		assert.InDelta(t, result.Score, total, 1e-9)
+	}
+
+	results, err = router.MatchN(ctx, "is it raining outside?", 1)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "chitchat", results[0].Route)
+
+	// Without similarity functions, the cosine similarity is the score.
+	router, err = NewRouter(newTestRoutes(), newTestEncoder(), memory.NewStore())
+	require.NoError(t, err)
+	results, err = router.MatchN(ctx, "is it raining outside?", 1)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, map[string]float64{SimilarityCosine: results[0].Score}, results[0].Breakdown)
+}
@@ -0,0 +1,94 @@
+package semanticrouter
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// defaultRerankCandidates is the default number of candidates passed to the
+// reranker, see WithRerankCandidates.
+const defaultRerankCandidates = 10
+
+// Reranker scores query-document pairs directly, such as the rerank
+// endpoints of some embedding APIs.
+//
+// Rerank returns one score per candidate, in the order of the candidates.
+type Reranker interface {
+	Rerank(ctx context.Context, query string, candidates []string) ([]float64, error)
+}
+
+// rerankIndex returns the index entries whose utterances best match the
+// given queries, boosted by the weighted scores of the reranker for the
+// given utterance.
+//
+// The index is returned unchanged if no reranker is configured.
+func (r *Router) rerankIndex(
+	ctx context.Context,
+	utterance string,
+	qs []query,
+	index []indexEntry,
+) ([]indexEntry, error) {
+	if r.reranker == nil || len(qs) == 0 {
+		return index, nil
+	}
+	q := qs[0]
+	scored := make([]entryScore, 0, len(index))
+	for _, entry := range index {
+		if q.kind == denseEmbeddings && entry.vec.Len() != q.vec.Len() {
+			continue
+		}
+		score, ok, err := r.computeScore(q, entry, r.similarities(entry.route))
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			scored = append(scored, entryScore{entry: entry, score: score})
+		}
+	}
+	sort.SliceStable(scored, func(i, j int) bool {
+		return scored[i].score > scored[j].score
+	})
+	n := r.rerankCandidates
+	if n <= 0 {
+		n = defaultRerankCandidates
+	}
+	scored = scored[:min(n, len(scored))]
+	candidates := make([]string, len(scored))
+	for i, es := range scored {
+		candidates[i] = es.entry.utterance
+	}
+	scores, err := r.rerank(ctx, utterance, candidates)
+	if err != nil {
+		return nil, fmt.Errorf("error reranking candidates: %w", err)
+	}
+	if len(scores) != len(candidates) {
+		return nil, fmt.Errorf(
+			"error reranking candidates: got %d scores for %d candidates",
+			len(scores),
+			len(candidates),
+		)
+	}
+	reranked := make([]indexEntry, len(scored))
+	for i, es := range scored {
+		reranked[i] = es.entry
+		reranked[i].boost += r.rerankCoefficient * scores[i]
+	}
+	return reranked, nil
+}
+
+// rerank scores the given candidates against the query with the router's
+// reranker.
+func (r *Router) rerank(
+	ctx context.Context,
+	query string,
+	candidates []string,
+) ([]float64, error) {
+	callCtx, cancel := r.callContext(ctx)
+	defer cancel()
+	scores, err := r.reranker.Rerank(callCtx, query, candidates)
+	if err != nil {
+		return nil, callError(callCtx, err)
+	}
+	return scores, nil
+}
@@ -0,0 +1,101 @@
+package semanticrouter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/conneroisu/go-semantic-router/stores/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubReranker is a reranker returning fixed scores per candidate and
+// recording the candidates it was given.
+type stubReranker struct {
+	scores     map[string]float64
+	candidates []string
+}
+
+// Rerank returns the fixed score of each candidate.
+func (s *stubReranker) Rerank(
+	_ context.Context,
+	_ string,
+	candidates []string,
+) ([]float64, error) {
+	s.candidates = candidates
+	scores := make([]float64, len(candidates))
+	for i, c := range candidates {
+		scores[i] = s.scores[c]
+	}
+	return scores, nil
+}
+
+// TestWithReranker tests that the reranker's scores change the matched
+// route and that only the best candidates are reranked.
+func TestWithReranker(t *testing.T) {
+	ctx := context.Background()
+	reranker := &stubReranker{scores: map[string]float64{
+		"i love the president": 1,
+	}}
+	router, err := NewRouter(
+		newTestRoutes(),
+		newTestEncoder(),
+		memory.NewStore(),
+		WithReranker(reranker, 2),
+	)
+	require.NoError(t, err)
+	route, score, err := router.Match(ctx, "is it raining outside?")
+	require.NoError(t, err)
+	assert.Equal(t, "politics", route)
+	assert.Greater(t, score, 2.0)
+	assert.Len(t, reranker.candidates, 4)
+
+	router, err = NewRouter(
+		newTestRoutes(),
+		newTestEncoder(),
+		memory.NewStore(),
+		WithReranker(reranker, 2),
+		WithRerankCandidates(1),
+	)
+	require.NoError(t, err)
+	route, _, err = router.Match(ctx, "is it raining outside?")
+	require.NoError(t, err)
+	assert.Equal(t, "chitchat", route)
+	assert.Equal(t, []string{"how's the weather today?"}, reranker.candidates)
+}
+
+// TestRerankerMatchingMethods tests that the reranker applies to every
+// matching method as it does to Match.
+func TestRerankerMatchingMethods(t *testing.T) {
+	ctx := context.Background()
+	reranker := &stubReranker{scores: map[string]float64{
+		"i love the president": 1,
+	}}
+	router, err := NewRouter(
+		newTestRoutes(),
+		newTestEncoder(),
+		memory.NewStore(),
+		WithReranker(reranker, 2),
+	)
+	require.NoError(t, err)
+	route, score, err := router.Match(ctx, "is it raining outside?")
+	require.NoError(t, err)
+
+	path, hierarchical, err := router.MatchHierarchical(ctx, "is it raining outside?")
+	require.NoError(t, err)
+	assert.Equal(t, []string{route}, path)
+	assert.Equal(t, score, hierarchical)
+
+	verbose, err := router.MatchVerbose(ctx, "is it raining outside?")
+	require.NoError(t, err)
+	assert.Equal(t, route, verbose.Route)
+	assert.Equal(t, score, verbose.Score)
+
+	scores, err := router.ScoreAll(ctx, "is it raining outside?")
+	require.NoError(t, err)
+	assert.Equal(t, score, scores[route])
+
+	hist, err := router.DebugScoreDistributionRange(ctx, []string{"is it raining outside?"}, 0, 4, 4)
+	require.NoError(t, err)
+	assert.Equal(t, []int{0, 0, 1, 0}, hist.Counts)
+}
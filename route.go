@@ -2,7 +2,10 @@ package semanticrouter
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync"
+	"time"
 
 	"github.com/conneroisu/go-semantic-router/domain"
 	"gonum.org/v1/gonum/mat"
@@ -13,26 +16,119 @@ import (
 // Router is a struct that contains a slice of Routes and an Encoder.
 //
 // Match can be called on a Router to find the best route for a given utterance.
+//
+// The methods of a Router are safe for concurrent use: matching methods share
+// a read lock on the routes, while AddRoute, AddUtterances and UpdateRoute
+// take it exclusively, so that matches never observe a partially updated
+// router. The Routes field must not be modified directly once the router is
+// in use.
 type Router struct {
 	Routes  []Route `json:"routes" yaml:"routes" toml:"routes"`    // Routes is a slice of Routes.
 	Encoder Encoder `json:"encoder" yaml:"encoder" toml:"encoder"` // Encoder is an Encoder that encodes utterances into vectors.
 	Storage Store   `json:"storage" yaml:"storage" toml:"storage"` // Storage is a Store that stores the utterances.
+
+	config
+}
+
+// config is the configuration of a Router set by its options.
+type config struct {
+	callTimeout        time.Duration                  // callTimeout is the timeout of each encoder and store call.
+	biFuncCoefficients []biFuncCoefficient            // biFuncCoefficients are the similarity functions used for scoring.
+	routeFuncs         map[string][]biFuncCoefficient // routeFuncs are the similarity functions overridden per route.
+	embeddings         embeddingKind                  // embeddings is the kind of embeddings the router uses.
+	minUtterances      int                            // minUtterances is the minimum number of utterances of a route to be matched.
+	knn                int                            // knn is the number of nearest utterances voting for their route, zero to disable.
+	aggregation        AggregationMode                // aggregation is how the scores of the utterances of a route are aggregated.
+	scoreDirection     ScoreDirection                 // scoreDirection is whether higher or lower scores are better.
+	trimFraction       float64                        // trimFraction is the fraction of best and worst scores dropped by AggregationTrimmedMean.
+	expander           QueryExpander                  // expander expands queries into variants, if set.
+	expansionMode      ExpansionMode                  // expansionMode is how the scores of query variants are combined.
+	priorMode          PriorMode                      // priorMode is how the priors of routes are applied to their scores.
+	recency            map[string]float64             // recency are the recency bonuses of routes added to their scores by MatchWithHistory.
+	ngramSizes         []int                          // ngramSizes are the sizes, in words, of the query n-grams matched alongside queries.
+	queryCache         *lru[string, cachedQuery]      // queryCache caches the embeddings of queries, if set.
+	queryCacheTTL      time.Duration                  // queryCacheTTL is the time to live of cached query embeddings, zero if they do not expire.
+	normCache          *lru[string, float64]          // normCache caches the norms of index embeddings, if set.
+	indexCache         *indexCache                    // indexCache caches the index entries loaded from the store, if set.
+	temperature        float64                        // temperature is the temperature of the softmax of MatchProbabilities, zero for the default.
+	topUtteranceCount  int                            // topUtteranceCount is the number of utterances returned by MatchVerbose.
+	parallelRoutes     int                            // parallelRoutes is the number of routes scored concurrently, one or less to score them serially.
+	centroids          bool                           // centroids is whether routes are scored against the centroid of their utterances.
+	mu                 *sync.RWMutex                  // mu guards the routes and the spatial index against concurrent changes, see AddRoute.
+	centroidsMu        *sync.Mutex                    // centroidsMu guards centroidState.
+	centroidState      map[string]routeCentroid       // centroidState are the centroids of the routes, keyed by route name.
+	fixedDimension     int                            // fixedDimension is the dimension of the router's embeddings, zero if unknown.
+	outputDimension    int                            // outputDimension is the dimension dense embeddings are truncated to, zero to keep them whole.
+	autoConcurrency    int                            // autoConcurrency is the maximum number of concurrent encoder calls when the router is built, zero to encode serially.
+	prefetched         map[string][]float64           // prefetched are the embeddings encoded ahead of storing while the router is built.
+	reranker           Reranker                       // reranker rescores the best candidates of a match, if set.
+	rerankCoefficient  float64                        // rerankCoefficient is the weight of the reranker's scores.
+	rerankCandidates   int                            // rerankCandidates is the number of candidates passed to the reranker.
+	maxCandidates      int                            // maxCandidates is the maximum number of utterances Match scores, zero for all of them.
+	twoStage           *twoStageParams                // twoStage are the parameters of two-stage scoring, if Match uses it.
+	queryPrefix        string                         // queryPrefix precedes query utterances when they are encoded.
+	documentPrefix     string                         // documentPrefix precedes the utterances of the routes when they are encoded.
+	normalizeKeys      bool                           // normalizeKeys is whether store and query cache keys are normalized with NormalizeKey.
+	encoderFallback    bool                           // encoderFallback is whether Match falls back to keyword matching when the encoder fails.
+	exactShortcut      bool                           // exactShortcut is whether Match returns the route of an utterance equal to the query without encoding it.
+	observer           Observer                       // observer is notified of the router's activity.
+	stats              *routeStats                    // stats are the statistics of the matches won by each route.
+	threshold          *adaptiveThreshold             // threshold is the minimum score of a match, if set.
+	bands              *confidenceBands               // bands are the confidence bands of matches, if set.
+	strictDimensions   bool                           // strictDimensions is whether mismatched embedding dimensions are an error.
+	strictTags         bool                           // strictTags is whether untagged utterances are excluded from tag-filtered matches.
+	nanPolicy          NaNPolicy                      // nanPolicy is how NaN and infinite scores are handled.
+	onDuplicate        DuplicateMode                  // onDuplicate is how duplicate utterances are handled when the router is built.
+	spatialEnabled     bool                           // spatialEnabled is whether Match uses a spatial index.
+	hnsw               *hnswParams                    // hnsw are the parameters of the HNSW index, if Match uses one.
+	spatial            nearestIndex                   // spatial is the spatial index of the router's embeddings, if built.
+	errs               []error                        // errs are the errors encountered while applying options.
 }
 
 // Route represents a route in the semantic router.
 //
 // It is a struct that contains a name and a slice of Utterances.
+//
+// If Similarities is non-empty, it replaces the similarity functions of the
+// router when scoring the route's utterances.
+//
+// If Parent is set, the route is a child of the route of that name, see
+// MatchHierarchical.
+//
+// Prior is the log-prior of the route, biasing its aggregated score toward
+// or away from the route before routes are compared, see WithPriorMode. The
+// default of zero leaves the score unchanged.
+//
+// If RequiredKeywords is non-empty, the route only matches queries
+// containing one of the keywords as whole words, ignoring case, or all of
+// them if RequireAllKeywords is set, however similar the query is to its
+// utterances. Keywords may have several words, which must then appear in
+// sequence.
+//
+// Priority orders the routes when Match takes candidates under
+// WithMaxCandidates: routes of higher priority are taken first, so that a
+// route pinned with a positive priority is scored however small the cap, as
+// long as it is at least the number of pinned routes. The default of zero
+// keeps the order of the routes.
 type Route struct {
-	Name       string             `json:"name"       yaml:"name"       toml:"name"`       // Name is the name of the route.
-	Utterances []domain.Utterance `json:"utterances" yaml:"utterances" toml:"utterances"` // Utterances is a slice of Utterances.
+	Name               string             `json:"name"                 yaml:"name"                 toml:"name"`                 // Name is the name of the route.
+	Utterances         []domain.Utterance `json:"utterances"           yaml:"utterances"           toml:"utterances"`           // Utterances is a slice of Utterances.
+	Similarities       []SimilaritySpec   `json:"similarities"         yaml:"similarities"         toml:"similarities"`         // Similarities are the similarity functions used to score the route.
+	Parent             string             `json:"parent"               yaml:"parent"               toml:"parent"`               // Parent is the name of the parent route, if any.
+	Prior              float64            `json:"prior"                yaml:"prior"                toml:"prior"`                // Prior is the log-prior of the route.
+	Priority           int                `json:"priority"             yaml:"priority"             toml:"priority"`             // Priority is the priority of the route when candidates are capped.
+	RequiredKeywords   []string           `json:"required_keywords"    yaml:"required_keywords"    toml:"required_keywords"`    // RequiredKeywords are the keywords a query must contain to match the route.
+	RequireAllKeywords bool               `json:"require_all_keywords" yaml:"require_all_keywords" toml:"require_all_keywords"` // RequireAllKeywords is whether a query must contain all the required keywords instead of one.
 }
 
 // Encoder represents a encoding driver in the semantic router.
 //
 // It is an interface that defines a single method, Encode, which takes a string
 // and returns a []float64 representing the embedding of the string.
+//
+// Implementations should return promptly once the given context is done.
 type Encoder interface {
-	Encode(string) ([]float64, error)
+	Encode(ctx context.Context, utterance string) ([]float64, error)
 }
 
 // Store is an interface that defines a method, Store, which takes a []float64
@@ -44,37 +140,236 @@ type Store interface {
 }
 
 // NewRouter creates a new semantic router.
-func NewRouter(routes []Route, encoder Encoder, store Store) (router *Router, err error) {
-	routesLen := len(routes)
-	ctx := context.Background()
-	for i := 0; i < routesLen; i++ {
-		route := routes[i]
-		utters := route.Utterances
-		for _, utter := range utters {
-			en, err := encoder.Encode(utter.Utterance)
-			if err != nil {
-				return nil, fmt.Errorf("error encoding utterance: %w", err)
-			}
-			err = utter.SetEmbedding(en)
-			if err != nil {
-				return nil, fmt.Errorf("error encoding utterance: %w", err)
-			}
-			err = store.Store(ctx, utter)
-			if err != nil {
-				return nil,
-					fmt.Errorf(
-						"error storing utterance: %s: %w",
-						utter.Utterance,
-						err,
-					)
-			}
-		}
+//
+// The given routes are validated before any utterance is encoded, see
+// Route.Validate.
+//
+// NewRouter is equivalent to NewRouterContext with context.Background().
+func NewRouter(
+	routes []Route,
+	encoder Encoder,
+	store Store,
+	opts ...Option,
+) (router *Router, err error) {
+	return NewRouterContext(context.Background(), routes, encoder, store, opts...)
+}
+
+// NewRouterContext creates a new semantic router, using the given context for
+// every encoder and store call made while building it.
+//
+// The build stops between utterances once the context is done, returning the
+// context's error.
+func NewRouterContext(
+	ctx context.Context,
+	routes []Route,
+	encoder Encoder,
+	store Store,
+	opts ...Option,
+) (router *Router, err error) {
+	err = validateRoutes(routes)
+	if err != nil {
+		return nil, fmt.Errorf("error validating routes: %w", err)
 	}
-	return &Router{
+	router = &Router{
 		Routes:  routes,
 		Encoder: encoder,
 		Storage: store,
-	}, nil
+	}
+	router.mu = &sync.RWMutex{}
+	router.centroidsMu = &sync.Mutex{}
+	router.stats = newRouteStats()
+	for _, opt := range opts {
+		opt(router)
+	}
+	err = errors.Join(router.errs...)
+	if err != nil {
+		return nil, fmt.Errorf("error applying options: %w", err)
+	}
+	err = router.resolveRouteSimilarities()
+	if err != nil {
+		return nil, fmt.Errorf("error resolving route similarities: %w", err)
+	}
+	err = router.checkCoefficients()
+	if err != nil {
+		return nil, fmt.Errorf("error checking coefficients: %w", err)
+	}
+	err = router.checkEmbeddings()
+	if err != nil {
+		return nil, err
+	}
+	err = router.checkScoreDirection()
+	if err != nil {
+		return nil, err
+	}
+	err = router.checkDuplicates(routes)
+	if err != nil {
+		return nil, err
+	}
+	if router.autoConcurrency > 0 && router.embeddings == denseEmbeddings {
+		router.prefetched, err = router.prefetchEmbeddings(ctx, routes)
+		if err != nil {
+			return nil, err
+		}
+	}
+	stored := make(map[string]bool)
+	for _, route := range routes {
+		err = router.storeRoute(ctx, route, router.fixedDimension, stored)
+		if err != nil {
+			return nil, err
+		}
+	}
+	router.prefetched = nil
+	err = router.storeIndexInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	err = router.buildSpatialIndex(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return router, nil
+}
+
+// storeRoute encodes and stores the utterances of the given route, along with
+// its centroid if the router uses route centroids.
+//
+// If stored is non-nil, it records the utterances already stored, see
+// storeUtterances.
+func (r *Router) storeRoute(
+	ctx context.Context,
+	route Route,
+	dimension int,
+	stored map[string]bool,
+) error {
+	centroid, err := r.storeUtterances(ctx, route, routeCentroid{}, route.Utterances, dimension, stored)
+	if err != nil {
+		return err
+	}
+	if r.centroids && len(route.Utterances) > 0 {
+		r.setCentroid(route.Name, centroid)
+	}
+	return nil
+}
+
+// storeUtterances encodes and stores the given utterances of a route, adding
+// them to the given centroid of the route if the router uses route
+// centroids. The updated centroid is stored and returned, but not kept, see
+// setCentroid.
+//
+// If stored is non-nil, the stored utterances are recorded in it, and those
+// already recorded are not encoded again when duplicates are skipped, see
+// WithOnDuplicate. It stops between utterances once the given context is
+// done, returning the context's error.
+func (r *Router) storeUtterances(
+	ctx context.Context,
+	route Route,
+	centroid routeCentroid,
+	utters []domain.Utterance,
+	dimension int,
+	stored map[string]bool,
+) (routeCentroid, error) {
+	for _, utter := range utters {
+		if ctx.Err() != nil {
+			return centroid, ctx.Err()
+		}
+		if stored[r.key(utter.Utterance)] && r.onDuplicate == DuplicateSkip {
+			if r.centroids {
+				en, err := r.get(ctx, utter.Utterance)
+				if err != nil {
+					return centroid, ErrGetEmbedding{Message: "error getting embedding", Err: err}
+				}
+				centroid = centroid.add(en)
+			}
+			continue
+		}
+		en, err := r.storeUtterance(ctx, utter, dimension)
+		if err != nil {
+			return centroid, err
+		}
+		if stored != nil {
+			stored[r.key(utter.Utterance)] = true
+		}
+		if r.centroids {
+			centroid = centroid.add(en)
+		}
+	}
+	if r.centroids && len(utters) > 0 {
+		err := r.storeCentroid(ctx, route.Name, centroid)
+		if err != nil {
+			return centroid, err
+		}
+	}
+	return centroid, nil
+}
+
+// storeUtterance encodes the given utterance and stores its embedding,
+// along with the embeddings of its variants.
+//
+// The dense embedding of the utterance is returned, nil if the router does
+// not use dense embeddings. If dimension is positive, dense embeddings of
+// another dimension are rejected with an ErrDimensionMismatch.
+func (r *Router) storeUtterance(
+	ctx context.Context,
+	utter domain.Utterance,
+	dimension int,
+) ([]float64, error) {
+	if variantCount(utter) > 0 && r.embeddings != denseEmbeddings {
+		return nil, fmt.Errorf("utterance %q: variants require dense embeddings: %w", utter.Utterance, ErrNotSupported)
+	}
+	switch r.embeddings {
+	case sparseEmbeddings:
+		return nil, r.storeSparseUtterance(ctx, utter)
+	case multiVectorEmbeddings:
+		return nil, r.storeMultiVectorUtterance(ctx, utter)
+	}
+	var err error
+	en, ok := r.prefetched[utter.Utterance]
+	if !ok {
+		en, err = r.encode(ctx, r.documentInput(utter.Utterance))
+		if err != nil {
+			return nil, ErrEncoding{Message: "error encoding utterance", Err: err}
+		}
+	}
+	if dimension > 0 && len(en) != dimension {
+		return nil, ErrDimensionMismatch{
+			Utterance: utter.Utterance,
+			Expected:  dimension,
+			Actual:    len(en),
+		}
+	}
+	err = r.storeEmbedding(ctx, utter, en)
+	if err != nil {
+		return nil, err
+	}
+	err = r.storeVariants(ctx, utter, en)
+	if err != nil {
+		return nil, err
+	}
+	return en, nil
+}
+
+// storeEmbedding stores the given dense embedding of the utterance.
+func (r *Router) storeEmbedding(
+	ctx context.Context,
+	utter domain.Utterance,
+	en []float64,
+) error {
+	err := utter.SetEmbedding(en)
+	if err != nil {
+		return fmt.Errorf("error encoding utterance: %w", err)
+	}
+	err = r.store(ctx, utter)
+	if err != nil {
+		return fmt.Errorf(
+			"error storing utterance: %s: %w",
+			utter.Utterance,
+			err,
+		)
+	}
+	if r.normCache != nil {
+		r.normCache.put(utter.Utterance, Norm(mat.NewVecDense(len(en), en)))
+	}
+	return nil
 }
 
 // Match returns the route that matches the given utterance.
@@ -82,35 +377,390 @@ func NewRouter(routes []Route, encoder Encoder, store Store) (router *Router, er
 // The score is the similarity score between the query vector and the index vector.
 //
 // If the given context is canceled, the context's error is returned if it is non-nil.
+//
+// Match is safe for concurrent use, including with AddRoute, AddUtterances
+// and UpdateRoute.
 func (r *Router) Match(
 	ctx context.Context,
 	utterance string,
 ) (bestRouteName string, bestScore float64, err error) {
-	encoding, err := r.Encoder.Encode(utterance)
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.match(ctx, utterance, true)
+}
+
+// MatchExact is like Match, but always scans the embeddings of every
+// utterance instead of searching the index of WithSpatialIndex or WithHNSW
+// or prefiltering routes with WithTwoStageScoring, so its result is exact
+// even when the router uses an approximate index.
+func (r *Router) MatchExact(
+	ctx context.Context,
+	utterance string,
+) (bestRouteName string, bestScore float64, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.match(ctx, utterance, false)
+}
+
+// match returns the route that matches the given utterance, searching the
+// router's spatial index, if any, and prefiltering routes, if configured,
+// when useIndex is set.
+func (r *Router) match(
+	ctx context.Context,
+	utterance string,
+	useIndex bool,
+) (bestRouteName string, bestScore float64, err error) {
+	return r.matchLoading(ctx, utterance, useIndex, r.loadIndexLimit)
+}
+
+// matchLoading is like match, but gets the index with the given function,
+// called with the number of candidates to load, see loadIndexLimit.
+func (r *Router) matchLoading(
+	ctx context.Context,
+	utterance string,
+	useIndex bool,
+	load func(ctx context.Context, limit int) ([]indexEntry, error),
+) (bestRouteName string, bestScore float64, err error) {
+	start := time.Now()
+	defer func() {
+		if err == nil && r.stats != nil {
+			r.stats.record(bestRouteName, bestScore, time.Now())
+		}
+		r.notify().ObserveMatch(MatchEvent{
+			Utterance: utterance,
+			Route:     bestRouteName,
+			Score:     bestScore,
+			Duration:  time.Since(start),
+			Err:       err,
+		})
+	}()
+	if r.exactShortcut {
+		route, score, ok := r.matchExactText(utterance)
+		if ok {
+			return route, score, nil
+		}
+	}
+	qs, err := r.encodeQueries(ctx, utterance)
+	if err != nil && ctx.Err() == nil && r.shouldFallback(err) {
+		return r.matchKeywords(utterance)
+	}
+	if err != nil {
+		return "", 0.0, err
+	}
+	if useIndex {
+		bestRouteName, bestScore, ok, err := r.matchSpatial(qs)
+		if ok {
+			return bestRouteName, bestScore, err
+		}
+	}
+	limit := 0
+	if useIndex {
+		limit = r.maxCandidates
+	}
+	index, err := load(ctx, limit)
+	if err != nil {
+		return "", 0.0, err
+	}
+	ranked, err := r.rankIndex(ctx, utterance, qs, index, useIndex)
+	if err != nil {
+		return "", 0.0, err
+	}
+	return r.bestRoute(ranked.scores)
+}
+
+// encodeQuery encodes the given utterance into a query.
+//
+// Dense embeddings whose components are all zero are rejected with an
+// ErrZeroEmbedding.
+func (r *Router) encodeQuery(
+	ctx context.Context,
+	utterance string,
+) (query, error) {
+	switch r.embeddings {
+	case sparseEmbeddings:
+		en, err := r.encodeSparse(ctx, r.queryInput(utterance))
+		if err != nil {
+			return query{}, ErrEncoding{Message: "error encoding utterance", Err: err}
+		}
+		return newSparseQuery(en), nil
+	case multiVectorEmbeddings:
+		en, err := r.encodeMultiVector(ctx, r.queryInput(utterance))
+		if err != nil {
+			return query{}, ErrEncoding{Message: "error encoding utterance", Err: err}
+		}
+		return newMultiVectorQuery(en), nil
+	}
+	encoding, err := r.encodeCached(ctx, utterance)
 	if err != nil {
-		return "", 0.0, fmt.Errorf("error encoding utterance: %w", err)
+		return query{}, ErrEncoding{Message: "error encoding utterance", Err: err}
 	}
-	queryVec := mat.NewVecDense(len(encoding), encoding)
-	for _, route := range r.Routes {
-		for _, ut := range route.Utterances {
-			em, err := r.Storage.Get(ctx, ut.Utterance)
+	q := newQuery(encoding)
+	if q.norm == 0 {
+		return query{}, ErrZeroEmbedding{Utterance: utterance}
+	}
+	q.text = utterance
+	return q, nil
+}
+
+// indexEntry is a single stored utterance embedding along with the name of
+// the route it belongs to.
+type indexEntry struct {
+	route     string
+	utterance string
+	tags      []string
+	vec       *mat.VecDense
+	norm      float64                     // norm is the L2 norm of vec.
+	boost     float64                     // boost is added to the score of the entry, such as the weighted score of a reranker.
+	sparse    domain.SparseEmbedding      // sparse is the sparse embedding of the utterance, if the router uses sparse embeddings.
+	multi     domain.MultiVectorEmbedding // multi is the multi-vector embedding of the utterance, if the router uses multi-vector embeddings.
+	variants  []indexEntry                // variants are the entries of the alternative embeddings of the utterance, see domain.Utterance.Variants.
+}
+
+// loadIndex fetches the embeddings of every utterance of every route from the
+// store, or the centroid of every route if the router uses route centroids.
+//
+// Routes with fewer utterances than the configured minimum are skipped.
+func (r *Router) loadIndex(ctx context.Context) ([]indexEntry, error) {
+	return r.loadIndexLimit(ctx, 0)
+}
+
+// loadIndexLimit is like loadIndex, but fetches at most limit embeddings,
+// spread across the routes as described by WithMaxCandidates, unless limit
+// is zero or less.
+func (r *Router) loadIndexLimit(ctx context.Context, limit int) (index []indexEntry, err error) {
+	counts := r.candidateCounts(limit)
+	for i, route := range r.Routes {
+		if counts[i] == 0 {
+			continue
+		}
+		if r.centroids {
+			entry, err := r.loadEntry(ctx, CentroidKey(route.Name))
 			if err != nil {
-				return "", 0.0, fmt.Errorf("error getting embedding: %w", err)
+				return nil, ErrGetEmbedding{Message: "error getting centroid", Err: err}
+			}
+			entry.route = route.Name
+			index = append(index, entry)
+			continue
+		}
+		for _, ut := range route.Utterances[:counts[i]] {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
 			}
-			emLen := len(em)
-			if emLen != queryVec.Len() {
-				continue
+			entry, err := r.loadEntry(ctx, ut.Utterance)
+			if err != nil {
+				return nil, ErrGetEmbedding{Message: "error getting embedding", Err: err}
 			}
-			indexVec := mat.NewVecDense(emLen, em)
-			simScore := SimilarityMatrix(queryVec, indexVec)
-			if simScore > bestScore {
-				bestScore = simScore
-				bestRouteName = route.Name
+			entry.route = route.Name
+			entry.tags = ut.Tags
+			err = r.loadVariants(ctx, &entry, ut)
+			if err != nil {
+				return nil, ErrGetEmbedding{Message: "error getting embedding", Err: err}
+			}
+			index = append(index, entry)
+		}
+	}
+	return index, nil
+}
+
+// routeScore is the aggregated score of a route.
+type routeScore struct {
+	route string
+	score float64
+}
+
+// checkDimensions returns an ErrDimensionMismatch for the first entry of the
+// index whose dimension differs from the one of the queries, if the router
+// uses strict dimensions.
+func (r *Router) checkDimensions(qs []query, index []indexEntry) error {
+	if !r.strictDimensions {
+		return nil
+	}
+	for _, q := range qs {
+		if q.kind != denseEmbeddings {
+			continue
+		}
+		for _, entry := range index {
+			if entry.vec.Len() != q.vec.Len() {
+				return ErrDimensionMismatch{
+					Utterance: entry.utterance,
+					Expected:  q.vec.Len(),
+					Actual:    entry.vec.Len(),
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// scoreIndex computes the aggregated score of each route of the index
+// against the given query.
+//
+// The scores of the utterances of each route are aggregated, see aggregate.
+// Routes are returned in the order they appear in the index; routes without
+// any utterance of the query's dimension are omitted, see checkDimensions.
+func (r *Router) scoreIndex(
+	q query,
+	index []indexEntry,
+) ([]routeScore, error) {
+	if r.parallelRoutes > 1 {
+		scored, err := r.scoreRoutesParallel(q, index)
+		if err != nil {
+			return nil, err
+		}
+		return r.aggregate(scored), nil
+	}
+	scored := make([]entryScore, 0, len(index))
+	for _, entry := range index {
+		if q.kind == denseEmbeddings && entry.vec.Len() != q.vec.Len() {
+			continue
+		}
+		es, ok, err := r.scoreEntry(q, entry)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			scored = append(scored, es)
+		}
+	}
+	return r.aggregate(scored), nil
+}
+
+// scoreEntry computes the score of the query against the index entry.
+//
+// ok is false if the entry must not be scored, see computeScore.
+func (r *Router) scoreEntry(q query, entry indexEntry) (es entryScore, ok bool, err error) {
+	score, ok, err := r.computeScore(q, entry, r.similarities(entry.route))
+	if err != nil || !ok {
+		return entryScore{}, false, err
+	}
+	return entryScore{entry: entry, score: score + entry.boost}, true, nil
+}
+
+// loadEntry fetches the embedding of the given utterance from the store, or
+// from the index cache if the router has one and it is fresh enough, see
+// WithCacheTTL.
+func (r *Router) loadEntry(
+	ctx context.Context,
+	utterance string,
+) (entry indexEntry, err error) {
+	if r.indexCache != nil {
+		now := time.Now()
+		key := r.key(utterance)
+		if cached, ok := r.indexCache.get(key, now); ok {
+			return cached, nil
+		}
+		defer func() {
+			if err == nil {
+				r.indexCache.put(key, entry, now)
 			}
+		}()
+	}
+	entry = indexEntry{utterance: utterance}
+	switch r.embeddings {
+	case sparseEmbeddings:
+		em, err := r.getSparse(ctx, utterance)
+		if err != nil {
+			return entry, err
+		}
+		entry.sparse = em
+	case multiVectorEmbeddings:
+		em, err := r.getMultiVector(ctx, utterance)
+		if err != nil {
+			return entry, err
+		}
+		entry.multi = em
+	default:
+		em, err := r.get(ctx, utterance)
+		if err != nil {
+			return entry, err
+		}
+		entry.vec = mat.NewVecDense(len(em), em)
+		entry.norm = r.indexNorm(utterance, entry.vec)
+	}
+	return entry, nil
+}
+
+// rankedIndex is an index scored against the queries of an utterance, see
+// rankIndex.
+type rankedIndex struct {
+	qs     []query      // qs are the queries of the utterance.
+	index  []indexEntry // index holds the scored entries, once gated, prefiltered and reranked.
+	scores []routeScore // scores are the aggregated scores of the routes of the index.
+}
+
+// scoreUtterance encodes the given utterance and scores it against the whole
+// index, see rankIndex.
+//
+// The entries of the index are passed through filter, if any, before being
+// scored.
+func (r *Router) scoreUtterance(
+	ctx context.Context,
+	utterance string,
+	filter func([]indexEntry) []indexEntry,
+) (rankedIndex, error) {
+	qs, err := r.encodeQueries(ctx, utterance)
+	if err != nil {
+		return rankedIndex{}, err
+	}
+	index, err := r.loadIndex(ctx)
+	if err != nil {
+		return rankedIndex{}, err
+	}
+	if filter != nil {
+		index = filter(index)
+	}
+	return r.rankIndex(ctx, utterance, qs, index, false)
+}
+
+// rankIndex scores the given index against the queries of the given
+// utterance, the scoring path shared by Match and the other matching
+// methods.
+//
+// Entries of routes whose keyword gate the utterance fails are dropped, see
+// Route.RequiredKeywords, routes are prefiltered if prefilter is set, see
+// WithTwoStageScoring, the dimensions of the remaining entries are checked,
+// see WithStrictDimensions, and they are reranked, see WithReranker, before
+// the aggregated score of each route is computed.
+func (r *Router) rankIndex(
+	ctx context.Context,
+	utterance string,
+	qs []query,
+	index []indexEntry,
+	prefilter bool,
+) (rankedIndex, error) {
+	index = r.gateIndex(utterance, index)
+	if prefilter {
+		index = r.prefilterIndex(qs, index)
+	}
+	err := r.checkDimensions(qs, index)
+	if err != nil {
+		return rankedIndex{}, err
+	}
+	index, err = r.rerankIndex(ctx, utterance, qs, index)
+	if err != nil {
+		return rankedIndex{}, err
+	}
+	scores, err := r.scoreQueries(qs, index)
+	if err != nil {
+		return rankedIndex{}, err
+	}
+	return rankedIndex{qs: qs, index: index, scores: scores}, nil
+}
+
+// bestRoute returns the best scoring route among the given scores, or
+// ErrNoRouteFound if none is accepted, see accepts.
+func (r *Router) bestRoute(
+	scores []routeScore,
+) (bestRouteName string, bestScore float64, err error) {
+	bestScore = r.worstScore()
+	for _, rs := range scores {
+		if r.better(rs.score, bestScore) {
+			bestScore = rs.score
+			bestRouteName = rs.route
 		}
 	}
-	if bestRouteName == "" {
-		return "", 0.0, fmt.Errorf("no route found")
+	if bestRouteName == "" || !r.accepts(bestScore) {
+		return "", 0.0, ErrNoRouteFound
 	}
 	return bestRouteName, bestScore, nil
 }
@@ -0,0 +1,80 @@
+package semanticrouter
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/conneroisu/go-semantic-router/domain"
+	"github.com/conneroisu/go-semantic-router/encoders/encodertest"
+)
+
+// mockEncoder is an encoder that returns a fixed embedding for each known
+// utterance.
+type mockEncoder struct {
+	embeddings map[string][]float64
+}
+
+// Encode returns the fixed embedding of the given utterance.
+func (m *mockEncoder) Encode(
+	ctx context.Context,
+	utterance string,
+) ([]float64, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	em, ok := m.embeddings[utterance]
+	if !ok {
+		return nil, fmt.Errorf("unknown utterance: %s", utterance)
+	}
+	return em, nil
+}
+
+// newTestEncoder returns a mockEncoder knowing the utterances of the test
+// routes and a few queries.
+func newTestEncoder() *mockEncoder {
+	return &mockEncoder{embeddings: map[string][]float64{
+		"how's the weather today?": {1.0, 0.1, 0.0},
+		"lovely weather today":     {0.9, 0.2, 0.0},
+		"who will win the vote?":   {0.0, 1.0, 0.1},
+		"i love the president":     {0.1, 0.9, 0.0},
+		"is it raining outside?":   {0.8, 0.0, 0.1},
+		"what about the election?": {0.1, 0.8, 0.2},
+	}}
+}
+
+// TestMockEncoderSuite runs the encoder test suite against the mock
+// encoder.
+func TestMockEncoderSuite(t *testing.T) {
+	encodertest.RunSuite(t, func() encodertest.Encoder {
+		return newTestEncoder()
+	}, encodertest.Options{
+		Inputs: []string{
+			"how's the weather today?",
+			"who will win the vote?",
+			"is it raining outside?",
+		},
+		Dimension:     3,
+		Deterministic: true,
+	})
+}
+
+// newTestRoutes returns a chitchat and a politics route.
+func newTestRoutes() []Route {
+	return []Route{
+		{
+			Name: "chitchat",
+			Utterances: []domain.Utterance{
+				{Utterance: "how's the weather today?"},
+				{Utterance: "lovely weather today"},
+			},
+		},
+		{
+			Name: "politics",
+			Utterances: []domain.Utterance{
+				{Utterance: "who will win the vote?"},
+				{Utterance: "i love the president"},
+			},
+		},
+	}
+}
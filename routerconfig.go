@@ -0,0 +1,190 @@
+package semanticrouter
+
+import (
+	"fmt"
+	"sync"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// Names of the similarity functions provided by the package.
+const (
+	SimilarityDotProduct  = "dot_product"  // SimilarityDotProduct is the name of DotProduct.
+	SimilarityCosine      = "cosine"       // SimilarityCosine is the name of SimilarityMatrix.
+	SimilarityEuclidean   = "euclidean"    // SimilarityEuclidean is the name of the similarity derived from EuclideanDistance.
+	SimilarityManhattan   = "manhattan"    // SimilarityManhattan is the name of the similarity derived from ManhattanDistance.
+	SimilarityJaccard     = "jaccard"      // SimilarityJaccard is the name of JaccardSimilarity.
+	SimilarityPearson     = "pearson"      // SimilarityPearson is the name of PearsonCorrelation.
+	SimilarityTextJaccard = "text_jaccard" // SimilarityTextJaccard is the name of TextJaccard, see WithTextJaccard.
+)
+
+// similarityRegistry is the registry of similarity functions, keyed by name,
+// holding the functions provided by the package along with the ones
+// registered with RegisterSimilarity.
+var (
+	registryMu         sync.RWMutex
+	similarityRegistry = map[string]SimilarityFunc{
+		SimilarityDotProduct: DotProduct,
+		SimilarityCosine:     SimilarityMatrix,
+		SimilarityEuclidean:  distanceToSimilarity(EuclideanDistance),
+		SimilarityManhattan:  distanceToSimilarity(ManhattanDistance),
+		SimilarityJaccard:    JaccardSimilarity,
+		SimilarityPearson:    PearsonCorrelation,
+	}
+)
+
+// RegisterSimilarity makes a similarity function available by the given
+// name, so that it can be selected from a RouterConfig or a route's
+// Similarities.
+//
+// RegisterSimilarity is meant to be called from init functions. It panics if
+// the name is already registered, including the names of the functions
+// provided by the package, or if fn is nil.
+func RegisterSimilarity(name string, fn func(q, idx *mat.VecDense) float64) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if fn == nil {
+		panic("semanticrouter: RegisterSimilarity function is nil")
+	}
+	if _, dup := similarityRegistry[name]; dup || name == SimilarityTextJaccard {
+		panic("semanticrouter: RegisterSimilarity called twice for " + name)
+	}
+	similarityRegistry[name] = fn
+}
+
+// RouterConfig is the serializable scoring configuration of a Router.
+//
+// An empty list of similarities means the default cosine similarity is used.
+type RouterConfig struct {
+	Similarities []SimilaritySpec `json:"similarities" yaml:"similarities" toml:"similarities"` // Similarities are the similarity functions used for scoring.
+}
+
+// SimilaritySpec identifies a similarity function by name along with the
+// coefficient its score is weighted by.
+type SimilaritySpec struct {
+	Name        string  `json:"name"        yaml:"name"        toml:"name"`        // Name is the name of the similarity function.
+	Coefficient float64 `json:"coefficient" yaml:"coefficient" toml:"coefficient"` // Coefficient is the weight of the similarity function.
+}
+
+// ExportConfig returns the scoring configuration of the router.
+func (r *Router) ExportConfig() RouterConfig {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	cfg := RouterConfig{}
+	for _, bf := range r.biFuncCoefficients {
+		cfg.Similarities = append(cfg.Similarities, SimilaritySpec{
+			Name:        bf.name,
+			Coefficient: bf.coefficient,
+		})
+	}
+	return cfg
+}
+
+// ApplyConfig returns the options configuring a router with the given scoring
+// configuration.
+//
+// Similarity functions are resolved by name among the functions provided by
+// the package and the ones registered with RegisterSimilarity; NewRouter
+// fails if a name cannot be resolved.
+func ApplyConfig(cfg RouterConfig) []Option {
+	opts := make([]Option, 0, len(cfg.Similarities))
+	for _, spec := range cfg.Similarities {
+		opts = append(opts, withSimilarityName(spec.Name, spec.Coefficient))
+	}
+	return opts
+}
+
+// SetCoefficients replaces the similarity functions of the router and their
+// coefficients with the ones of the given configuration, without rebuilding
+// the router, for instance to tune coefficients on live traffic.
+//
+// Similarity functions are resolved by name as with ApplyConfig, so
+// functions added with WithCustomSimilarity must be registered with
+// RegisterSimilarity to be kept, and their coefficients are checked as when
+// the router is built. The functions are swapped atomically: calls in flight
+// complete with the previous functions, and later calls use the new ones.
+// The functions overridden by routes are left unchanged. Setting any
+// similarity function disables the spatial index of WithSpatialIndex or
+// WithHNSW, if any, until the routes are next changed.
+func (r *Router) SetCoefficients(cfg RouterConfig) error {
+	fns := make([]biFuncCoefficient, 0, len(cfg.Similarities))
+	for _, spec := range cfg.Similarities {
+		bf, err := resolveSimilarity(spec)
+		if err != nil {
+			return fmt.Errorf("error setting coefficients: %w", err)
+		}
+		fns = append(fns, bf)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	err := r.checkFuncCoefficients(fns)
+	if err != nil {
+		return fmt.Errorf("error setting coefficients: %w", err)
+	}
+	r.biFuncCoefficients = fns
+	if len(fns) > 0 {
+		r.spatial = nil
+	}
+	return nil
+}
+
+// withSimilarityName adds the similarity function with the given name to the
+// router.
+func withSimilarityName(name string, coefficient float64) Option {
+	return func(r *Router) {
+		bf, err := resolveSimilarity(SimilaritySpec{
+			Name:        name,
+			Coefficient: coefficient,
+		})
+		if err != nil {
+			r.errs = append(r.errs, err)
+			return
+		}
+		r.biFuncCoefficients = append(r.biFuncCoefficients, bf)
+	}
+}
+
+// resolveSimilarity resolves the similarity function of the given spec by
+// name.
+func resolveSimilarity(spec SimilaritySpec) (biFuncCoefficient, error) {
+	if spec.Name == SimilarityTextJaccard {
+		return biFuncCoefficient{
+			name:        spec.Name,
+			coefficient: spec.Coefficient,
+			kind:        SimilarityKindTextJaccard,
+			text:        true,
+		}, nil
+	}
+	registryMu.RLock()
+	fn, ok := similarityRegistry[spec.Name]
+	registryMu.RUnlock()
+	if !ok {
+		return biFuncCoefficient{}, fmt.Errorf(
+			"unknown similarity function: %q",
+			spec.Name,
+		)
+	}
+	return biFuncCoefficient{
+		name:        spec.Name,
+		fn:          fn,
+		coefficient: spec.Coefficient,
+		kind:        similarityKinds[spec.Name],
+		cosine:      spec.Name == SimilarityCosine,
+	}, nil
+}
+
+// resolveRouteSimilarities resolves the similarity functions overridden by
+// the router's routes.
+func (r *Router) resolveRouteSimilarities() error {
+	r.routeFuncs = make(map[string][]biFuncCoefficient)
+	for _, route := range r.Routes {
+		for _, spec := range route.Similarities {
+			bf, err := resolveSimilarity(spec)
+			if err != nil {
+				return fmt.Errorf("route %q: %w", route.Name, err)
+			}
+			r.routeFuncs[route.Name] = append(r.routeFuncs[route.Name], bf)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+package semanticrouter
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/conneroisu/go-semantic-router/stores/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gonum.org/v1/gonum/mat"
+)
+
+// TestConfigRoundTrip tests that an exported scoring configuration can be
+// serialized and re-applied to build an identically scoring router.
+func TestConfigRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	router, err := NewRouter(
+		newTestRoutes(),
+		newTestEncoder(),
+		memory.NewStore(),
+		WithCosineSimilarity(0.6),
+		WithEuclideanDistance(0.3),
+		WithPearsonCorrelation(0.1),
+	)
+	require.NoError(t, err)
+	cfg := router.ExportConfig()
+	assert.Equal(t, RouterConfig{Similarities: []SimilaritySpec{
+		{Name: SimilarityCosine, Coefficient: 0.6},
+		{Name: SimilarityEuclidean, Coefficient: 0.3},
+		{Name: SimilarityPearson, Coefficient: 0.1},
+	}}, cfg)
+
+	data, err := json.Marshal(cfg)
+	require.NoError(t, err)
+	var loaded RouterConfig
+	require.NoError(t, json.Unmarshal(data, &loaded))
+
+	reloaded, err := NewRouter(
+		newTestRoutes(),
+		newTestEncoder(),
+		memory.NewStore(),
+		ApplyConfig(loaded)...,
+	)
+	require.NoError(t, err)
+	assert.Equal(t, cfg, reloaded.ExportConfig())
+
+	want, err := router.ScoreAll(ctx, "is it raining outside?")
+	require.NoError(t, err)
+	got, err := reloaded.ScoreAll(ctx, "is it raining outside?")
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+}
+
+// TestApplyConfigUnknown tests that NewRouter fails on unknown similarity
+// function names.
+func TestApplyConfigUnknown(t *testing.T) {
+	_, err := NewRouter(
+		newTestRoutes(),
+		newTestEncoder(),
+		memory.NewStore(),
+		ApplyConfig(RouterConfig{Similarities: []SimilaritySpec{
+			{Name: "unknown", Coefficient: 1.0},
+		}})...,
+	)
+	assert.ErrorContains(t, err, `unknown similarity function: "unknown"`)
+}
+
+// firstComponentCalls counts the calls to the similarity function
+// registered as "test_first_component".
+var firstComponentCalls atomic.Int64
+
+func init() {
+	RegisterSimilarity("test_first_component", func(q, idx *mat.VecDense) float64 {
+		firstComponentCalls.Add(1)
+		return q.AtVec(0) * idx.AtVec(0)
+	})
+}
+
+// TestRegisterSimilarity tests that registered similarity functions can be
+// selected by name from a config.
+func TestRegisterSimilarity(t *testing.T) {
+	ctx := context.Background()
+	router, err := NewRouter(
+		newTestRoutes(),
+		newTestEncoder(),
+		memory.NewStore(),
+		ApplyConfig(RouterConfig{Similarities: []SimilaritySpec{
+			{Name: "test_first_component", Coefficient: 1.0},
+		}})...,
+	)
+	require.NoError(t, err)
+	route, score, err := router.Match(ctx, "is it raining outside?")
+	require.NoError(t, err)
+	assert.Equal(t, "chitchat", route)
+	assert.InDelta(t, 0.8, score, 1e-12)
+	calls := firstComponentCalls.Load()
+	_, _, err = router.Match(ctx, "is it raining outside?")
+	require.NoError(t, err)
+	assert.Equal(t, calls+4, firstComponentCalls.Load())
+
+	assert.Panics(t, func() {
+		RegisterSimilarity(SimilarityCosine, SimilarityMatrix)
+	})
+	assert.Panics(t, func() {
+		RegisterSimilarity("test_nil", nil)
+	})
+}
+
+// TestSetCoefficients tests that coefficients can be swapped while the
+// router is matching, each match seeing either the previous or the new set.
+func TestSetCoefficients(t *testing.T) {
+	ctx := context.Background()
+	cosine := RouterConfig{Similarities: []SimilaritySpec{{Name: SimilarityCosine, Coefficient: 1.0}}}
+	penalized := RouterConfig{Similarities: []SimilaritySpec{
+		{Name: SimilarityCosine, Coefficient: 1.0},
+		{Name: "test_first_component", Coefficient: -2.0},
+	}}
+	expected := make(map[string]float64)
+	for _, cfg := range []RouterConfig{cosine, penalized} {
+		router, err := NewRouter(newTestRoutes(), newTestEncoder(), memory.NewStore(), ApplyConfig(cfg)...)
+		require.NoError(t, err)
+		route, score, err := router.Match(ctx, "is it raining outside?")
+		require.NoError(t, err)
+		expected[route] = score
+	}
+	require.Len(t, expected, 2)
+
+	router, err := NewRouter(newTestRoutes(), newTestEncoder(), memory.NewStore(), ApplyConfig(cosine)...)
+	require.NoError(t, err)
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				route, score, err := router.Match(ctx, "is it raining outside?")
+				if !assert.NoError(t, err) {
+					return
+				}
+				assert.Equal(t, expected[route], score, route)
+			}
+		}()
+	}
+	for i := range 100 {
+		cfg := cosine
+		if i%2 == 0 {
+			cfg = penalized
+		}
+		require.NoError(t, router.SetCoefficients(cfg))
+		assert.Equal(t, cfg, router.ExportConfig())
+	}
+	close(done)
+	wg.Wait()
+
+	assert.Error(t, router.SetCoefficients(RouterConfig{Similarities: []SimilaritySpec{{Name: "unknown", Coefficient: 1.0}}}))
+	assert.Error(t, router.SetCoefficients(RouterConfig{Similarities: []SimilaritySpec{{Name: SimilarityCosine, Coefficient: -1.0}}}))
+	assert.Equal(t, cosine, router.ExportConfig())
+	require.NoError(t, router.SetCoefficients(RouterConfig{}))
+	assert.Empty(t, router.SimilarityConfig())
+}
@@ -0,0 +1,157 @@
+package semanticrouter
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"math"
+
+	"github.com/conneroisu/go-semantic-router/domain"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// SimilarityFunc is a function computing the similarity between a query
+// vector and an index vector.
+type SimilarityFunc func(query, index *mat.VecDense) float64
+
+// biFuncCoefficient is a similarity function along with the coefficient its
+// score is weighted by.
+type biFuncCoefficient struct {
+	name        string
+	fn          SimilarityFunc
+	coefficient float64
+	cache       *lru[scoreKey, float64] // cache memoizes the scores of the function, nil if not cacheable.
+	kind        SimilarityKind          // kind is the kind of the function.
+	cosine      bool                    // cosine is whether the function is the builtin cosine similarity, computed from norms.
+	text        bool                    // text is whether the function is TextJaccard, comparing texts instead of embeddings.
+}
+
+// scoreKey identifies the score of a query vector against an index vector
+// by their hashes, so that a cached score is never used for a vector that
+// changed under the same utterance.
+type scoreKey struct {
+	query uint64
+	index uint64
+}
+
+// query is a query vector along with its hash and norm.
+type query struct {
+	text   string // text is the utterance the query was encoded from.
+	vec    *mat.VecDense
+	hash   uint64
+	norm   float64
+	kind   embeddingKind
+	sparse domain.SparseEmbedding
+	multi  domain.MultiVectorEmbedding
+}
+
+// newQuery creates a new query from the given encoding.
+func newQuery(encoding []float64) query {
+	vec := mat.NewVecDense(len(encoding), encoding)
+	return query{
+		vec:  vec,
+		hash: hashVector(encoding),
+		norm: Norm(vec),
+	}
+}
+
+// hashVector returns the FNV-1a hash of the given vector.
+func hashVector(vec []float64) uint64 {
+	h := fnv.New64a()
+	var buf [8]byte
+	for _, v := range vec {
+		binary.LittleEndian.PutUint64(buf[:], math.Float64bits(v))
+		_, _ = h.Write(buf[:])
+	}
+	return h.Sum64()
+}
+
+// newMultiVectorQuery creates a new query from the given multi-vector
+// encoding.
+func newMultiVectorQuery(encoding domain.MultiVectorEmbedding) query {
+	return query{multi: encoding, kind: multiVectorEmbeddings}
+}
+
+// newSparseQuery creates a new query from the given sparse encoding.
+func newSparseQuery(encoding domain.SparseEmbedding) query {
+	return query{sparse: encoding, kind: sparseEmbeddings}
+}
+
+// similarities returns the similarity functions used to score the route with
+// the given name.
+func (r *Router) similarities(route string) []biFuncCoefficient {
+	if fns, ok := r.routeFuncs[route]; ok {
+		return fns
+	}
+	return r.biFuncCoefficients
+}
+
+// computeScore computes the weighted sum of the scores of the given
+// similarity functions between the query and the index entry.
+//
+// If no similarity functions are given, the cosine similarity is used.
+// Sparse queries are always scored with the sparse dot product, and
+// multi-vector queries with MaxSim. NaN and infinite scores are handled
+// according to the router's NaN policy, see WithNaNPolicy; ok is false if
+// the entry must not be scored at all.
+//
+// Utterances with alternative embeddings, see domain.Utterance.Variants,
+// score the best score among their embeddings.
+func (r *Router) computeScore(
+	q query,
+	entry indexEntry,
+	fns []biFuncCoefficient,
+) (score float64, ok bool, err error) {
+	_, score, ok, err = r.bestVariant(q, entry, fns)
+	return score, ok, err
+}
+
+// computeEntryScore computes the score of computeScore against the given
+// embedding of the index entry, leaving its variants out.
+func (r *Router) computeEntryScore(
+	q query,
+	entry indexEntry,
+	fns []biFuncCoefficient,
+) (score float64, ok bool, err error) {
+	switch q.kind {
+	case sparseEmbeddings:
+		return r.checkScore("sparse_dot_product", entry, SparseDotProduct(q.sparse, entry.sparse))
+	case multiVectorEmbeddings:
+		return r.checkScore("max_sim", entry, MaxSim(q.multi, entry.multi))
+	}
+	if len(fns) == 0 {
+		return r.checkScore(SimilarityCosine, entry, CosineFromNorms(q.vec, entry.vec, q.norm, entry.norm))
+	}
+	for _, bf := range fns {
+		s, valid, err := r.checkScore(bf.name, entry, bf.score(q, entry))
+		if err != nil {
+			return 0, false, err
+		}
+		if valid {
+			score += bf.coefficient * s
+			ok = true
+		}
+	}
+	return score, ok, nil
+}
+
+// score computes the score of the function between the query and the index
+// entry, using the cache if the function is cacheable.
+func (bf biFuncCoefficient) score(q query, entry indexEntry) float64 {
+	if bf.cosine {
+		return CosineFromNorms(q.vec, entry.vec, q.norm, entry.norm)
+	}
+	if bf.text {
+		return TextJaccard(q.text, entry.utterance)
+	}
+	if bf.cache == nil {
+		return bf.fn(q.vec, entry.vec)
+	}
+	key := scoreKey{query: q.hash, index: hashVector(entry.vec.RawVector().Data)}
+	if score, ok := bf.cache.get(key); ok {
+		return score
+	}
+	score := bf.fn(q.vec, entry.vec)
+	bf.cache.put(key, score)
+	return score
+}
@@ -0,0 +1,131 @@
+package semanticrouter
+
+import (
+	"context"
+	"math"
+	"sync/atomic"
+	"testing"
+
+	"github.com/conneroisu/go-semantic-router/domain"
+	"github.com/conneroisu/go-semantic-router/stores/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gonum.org/v1/gonum/mat"
+)
+
+// countingSimilarity returns a cosine similarity function counting its
+// invocations in calls.
+func countingSimilarity(calls *atomic.Int64) SimilarityFunc {
+	return func(query, index *mat.VecDense) float64 {
+		calls.Add(1)
+		return SimilarityMatrix(query, index)
+	}
+}
+
+// TestCacheableSimilarity tests that a cacheable similarity function is only
+// invoked once per query and index utterance across Match calls.
+func TestCacheableSimilarity(t *testing.T) {
+	ctx := context.Background()
+	var cached, uncached atomic.Int64
+	router, err := NewRouter(
+		newTestRoutes(),
+		newTestEncoder(),
+		memory.NewStore(),
+		WithCustomSimilarity("cached", countingSimilarity(&cached), 1.0, Cacheable(16)),
+		WithCustomSimilarity("uncached", countingSimilarity(&uncached), 1.0),
+	)
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		route, score, err := router.Match(ctx, "is it raining outside?")
+		require.NoError(t, err)
+		assert.Equal(t, "chitchat", route)
+		assert.Greater(t, score, 1.0)
+	}
+	assert.Equal(t, int64(4), cached.Load())
+	assert.Equal(t, int64(12), uncached.Load())
+
+	_, _, err = router.Match(ctx, "what about the election?")
+	require.NoError(t, err)
+	assert.Equal(t, int64(8), cached.Load())
+}
+
+// TestCacheableSimilarityChangedEmbedding tests that the cached scores of an
+// utterance are not used once its embedding changed.
+func TestCacheableSimilarityChangedEmbedding(t *testing.T) {
+	ctx := context.Background()
+	var calls atomic.Int64
+	store := memory.NewStore()
+	router, err := NewRouter(
+		newTestRoutes(),
+		newTestEncoder(),
+		store,
+		WithCustomSimilarity("cached", countingSimilarity(&calls), 1.0, Cacheable(16)),
+	)
+	require.NoError(t, err)
+	route, _, err := router.Match(ctx, "is it raining outside?")
+	require.NoError(t, err)
+	assert.Equal(t, "chitchat", route)
+
+	for _, utterance := range []string{"how's the weather today?", "lovely weather today"} {
+		utter := domain.Utterance{Utterance: utterance}
+		require.NoError(t, utter.SetEmbedding([]float64{0.0, 0.0, -1.0}))
+		require.NoError(t, store.Store(ctx, utter))
+	}
+	route, _, err = router.Match(ctx, "is it raining outside?")
+	require.NoError(t, err)
+	assert.Equal(t, "politics", route)
+}
+
+// TestRouteSimilarities tests that routes overriding the similarity functions
+// are scored with their own functions.
+func TestRouteSimilarities(t *testing.T) {
+	ctx := context.Background()
+	routes := newTestRoutes()
+	routes[0].Similarities = []SimilaritySpec{{Name: SimilarityCosine, Coefficient: 1.0}}
+	routes[1].Similarities = []SimilaritySpec{{Name: SimilarityEuclidean, Coefficient: 1.0}}
+	router, err := NewRouter(routes, newTestEncoder(), memory.NewStore())
+	require.NoError(t, err)
+
+	route, _, err := router.Match(ctx, "is it raining outside?")
+	require.NoError(t, err)
+	assert.Equal(t, "chitchat", route)
+	route, _, err = router.Match(ctx, "what about the election?")
+	require.NoError(t, err)
+	assert.Equal(t, "politics", route)
+
+	scores, err := router.ScoreAll(ctx, "what about the election?")
+	require.NoError(t, err)
+	assert.InDelta(t, 1/(1+math.Sqrt(0.05)), scores["politics"], 1e-9)
+
+	routes[1].Similarities = []SimilaritySpec{{Name: "unknown", Coefficient: 1.0}}
+	_, err = NewRouter(routes, newTestEncoder(), memory.NewStore())
+	assert.ErrorContains(t, err, `route "politics": unknown similarity function: "unknown"`)
+}
+
+// TestWithNormCache tests that caching index norms does not change the
+// scores of the router.
+func TestWithNormCache(t *testing.T) {
+	ctx := context.Background()
+	plain, err := NewRouter(newTestRoutes(), newTestEncoder(), memory.NewStore())
+	require.NoError(t, err)
+	cached, err := NewRouter(
+		newTestRoutes(),
+		newTestEncoder(),
+		memory.NewStore(),
+		WithNormCache(16),
+		WithCosineSimilarity(1),
+	)
+	require.NoError(t, err)
+	norm, ok := cached.normCache.get("lovely weather today")
+	require.True(t, ok)
+	assert.InDelta(t, math.Sqrt(0.85), norm, 1e-12)
+
+	for _, utterance := range []string{"is it raining outside?", "what about the election?"} {
+		expected, err := plain.ScoreAll(ctx, utterance)
+		require.NoError(t, err)
+		actual, err := cached.ScoreAll(ctx, utterance)
+		require.NoError(t, err)
+		assert.InDeltaMapValues(t, expected, actual, 1e-12)
+	}
+}
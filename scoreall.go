@@ -0,0 +1,33 @@
+package semanticrouter
+
+import "context"
+
+// ScoreAll returns the aggregated score of every route for the given
+// utterance.
+//
+// The scores are computed by an exhaustive scan of the embeddings of every
+// utterance, through the same scoring steps as Match. The best scoring route
+// is not always the one Match returns, threshold aside, as ScoreAll applies
+// none of WithMaxCandidates, WithTwoStageScoring, the approximate indexes of
+// WithSpatialIndex and WithHNSW, WithExactMatchShortcut and
+// WithEncoderFallback. Routes the scan cannot return are omitted: those
+// blocked by their keyword gate, see Route.RequiredKeywords, those without
+// any utterance of the query's dimension, unless the router uses
+// WithStrictDimensions, and with WithKNNVoting those without any utterance
+// among the k nearest, which get no vote.
+func (r *Router) ScoreAll(
+	ctx context.Context,
+	utterance string,
+) (map[string]float64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	ranked, err := r.scoreUtterance(ctx, utterance, nil)
+	if err != nil {
+		return nil, err
+	}
+	scores := make(map[string]float64)
+	for _, rs := range ranked.scores {
+		scores[rs.route] = rs.score
+	}
+	return scores, nil
+}
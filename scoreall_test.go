@@ -0,0 +1,67 @@
+package semanticrouter
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/conneroisu/go-semantic-router/stores/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestScoreAll tests that ScoreAll scores every route and that its best entry
+// is the result of Match.
+func TestScoreAll(t *testing.T) {
+	ctx := context.Background()
+	router, err := NewRouter(newTestRoutes(), newTestEncoder(), memory.NewStore())
+	require.NoError(t, err)
+
+	for _, utterance := range []string{
+		"is it raining outside?",
+		"what about the election?",
+	} {
+		scores, err := router.ScoreAll(ctx, utterance)
+		require.NoError(t, err)
+		assert.Len(t, scores, 2)
+
+		bestRoute, bestScore := "", math.Inf(-1)
+		for route, score := range scores {
+			if score > bestScore {
+				bestRoute, bestScore = route, score
+			}
+		}
+		route, score, err := router.Match(ctx, utterance)
+		require.NoError(t, err)
+		assert.Equal(t, bestRoute, route)
+		assert.Equal(t, bestScore, score)
+	}
+}
+
+// TestWithMinUtterancesPerRoute tests that routes with too few utterances are
+// excluded from matching.
+func TestWithMinUtterancesPerRoute(t *testing.T) {
+	ctx := context.Background()
+	routes := newTestRoutes()
+	routes[1].Utterances = routes[1].Utterances[:1]
+
+	router, err := NewRouter(routes, newTestEncoder(), memory.NewStore())
+	require.NoError(t, err)
+	route, _, err := router.Match(ctx, "what about the election?")
+	require.NoError(t, err)
+	assert.Equal(t, "politics", route)
+
+	router, err = NewRouter(
+		routes,
+		newTestEncoder(),
+		memory.NewStore(),
+		WithMinUtterancesPerRoute(2),
+	)
+	require.NoError(t, err)
+	route, _, err = router.Match(ctx, "what about the election?")
+	require.NoError(t, err)
+	assert.Equal(t, "chitchat", route)
+	scores, err := router.ScoreAll(ctx, "what about the election?")
+	require.NoError(t, err)
+	assert.NotContains(t, scores, "politics")
+}
@@ -8,6 +8,9 @@
 
 go test ./... -coverprofile=coverage.out -race
 
+# the gemini encoder is a separate module, see encoders/gemini/go.mod
+(cd encoders/gemini && go test ./... -race)
+
 # if gocovsh is installed, then run it
 if command -v gocovsh > /dev/null; then
   gocovsh
@@ -7,3 +7,4 @@
 # Usage: make tidy
 
 go mod tidy
+(cd encoders/gemini && go mod tidy)
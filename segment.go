@@ -0,0 +1,93 @@
+package semanticrouter
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// clauseConjunctions are the words at which SplitClauses splits a sentence.
+var clauseConjunctions = map[string]bool{
+	"and":  true,
+	"then": true,
+	"also": true,
+	"but":  true,
+}
+
+// SplitClauses is the default segmenter of MatchSegments. It splits the
+// given text into sentences ending with ".", "!", "?", ";" or a new line,
+// then splits each sentence at the conjunctions "and", "then", "also" and
+// "but", which are left out of the segments.
+//
+// The words of each segment are joined by single spaces and the commas and
+// semicolons around them are trimmed, so "check my balance, and pay my
+// bill." is split into "check my balance" and "pay my bill.".
+func SplitClauses(text string) []string {
+	var segments []string
+	var words []string
+	flush := func() {
+		segment := strings.Trim(strings.Join(words, " "), ",; ")
+		if segment != "" {
+			segments = append(segments, segment)
+		}
+		words = words[:0]
+	}
+	for _, sentence := range strings.FieldsFunc(text, func(c rune) bool {
+		return c == ';' || c == '\n'
+	}) {
+		for _, word := range strings.Fields(sentence) {
+			if clauseConjunctions[strings.ToLower(strings.Trim(word, ","))] {
+				flush()
+				continue
+			}
+			words = append(words, word)
+			if strings.ContainsAny(word[len(word)-1:], ".!?") {
+				flush()
+			}
+		}
+		flush()
+	}
+	return segments
+}
+
+// MatchSegments splits the given utterance into segments with the given
+// segmenter and matches each segment independently, returning one result per
+// segment in the order of the segments, so that a query expressing several
+// intents, such as "check my balance and pay my bill", is routed to the
+// route of each intent.
+//
+// If segmenter is nil, SplitClauses is used. Blank segments are skipped, and
+// if there is no segment left the whole utterance is matched as a single
+// segment. A segment that no route matches has its Err set to
+// ErrNoRouteFound; other errors, such as encoder or store errors, are
+// returned.
+func (r *Router) MatchSegments(
+	ctx context.Context,
+	utterance string,
+	segmenter func(string) []string,
+) ([]MatchResult, error) {
+	if segmenter == nil {
+		segmenter = SplitClauses
+	}
+	var segments []string
+	for _, segment := range segmenter(utterance) {
+		if strings.TrimSpace(segment) != "" {
+			segments = append(segments, segment)
+		}
+	}
+	if len(segments) == 0 {
+		segments = []string{utterance}
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	results := make([]MatchResult, 0, len(segments))
+	for _, segment := range segments {
+		result := MatchResult{Utterance: segment}
+		result.Route, result.Score, result.Err = r.match(ctx, segment, true)
+		if result.Err != nil && !errors.Is(result.Err, ErrNoRouteFound) {
+			return nil, result.Err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
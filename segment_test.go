@@ -0,0 +1,81 @@
+package semanticrouter
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/conneroisu/go-semantic-router/stores/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSplitClauses tests that SplitClauses splits text into sentences and
+// clauses.
+func TestSplitClauses(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected []string
+	}{
+		{name: "single", text: "check my balance", expected: []string{"check my balance"}},
+		{name: "conjunction", text: "check my balance, and pay my bill.", expected: []string{"check my balance", "pay my bill."}},
+		{name: "sentences", text: "Hi! Is it raining?  What now", expected: []string{"Hi!", "Is it raining?", "What now"}},
+		{name: "separators", text: "one; two\nthree Then four", expected: []string{"one", "two", "three", "four"}},
+		{name: "blank", text: "  and ", expected: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, SplitClauses(tt.text))
+		})
+	}
+}
+
+// TestMatchSegments tests that MatchSegments matches each intent of a
+// compound query to its own route.
+func TestMatchSegments(t *testing.T) {
+	ctx := context.Background()
+	router, err := NewRouter(newTestRoutes(), newTestEncoder(), memory.NewStore(), WithAdaptiveThreshold(0.5))
+	require.NoError(t, err)
+
+	results, err := router.MatchSegments(ctx, "is it raining outside? and what about the election?", nil)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, "is it raining outside?", results[0].Utterance)
+	assert.Equal(t, "chitchat", results[0].Route)
+	assert.Equal(t, "what about the election?", results[1].Utterance)
+	assert.Equal(t, "politics", results[1].Route)
+	for _, result := range results {
+		assert.NoError(t, result.Err)
+		route, score, err := router.Match(ctx, result.Utterance)
+		require.NoError(t, err)
+		assert.Equal(t, route, result.Route)
+		assert.InDelta(t, score, result.Score, 1e-9)
+	}
+
+	t.Run("custom segmenter", func(t *testing.T) {
+		results, err := router.MatchSegments(ctx, "lovely weather today|who will win the vote?", func(s string) []string {
+			return strings.Split(s, "|")
+		})
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+		assert.Equal(t, "chitchat", results[0].Route)
+		assert.Equal(t, "politics", results[1].Route)
+	})
+
+	t.Run("no route", func(t *testing.T) {
+		router, err := NewRouter(newTestRoutes(), newTestEncoder(), memory.NewStore(), WithAdaptiveThreshold(0.99))
+		require.NoError(t, err)
+		results, err := router.MatchSegments(ctx, "how's the weather today? and what about the election?", nil)
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+		assert.NoError(t, results[0].Err)
+		assert.Equal(t, "chitchat", results[0].Route)
+		assert.ErrorIs(t, results[1].Err, ErrNoRouteFound)
+	})
+
+	t.Run("encoder error", func(t *testing.T) {
+		_, err := router.MatchSegments(ctx, "lovely weather today and unknown", nil)
+		assert.Error(t, err)
+	})
+}
@@ -1,7 +1,8 @@
 package semanticrouter
 
 import (
-	"gonum.org/v1/gonum/floats"
+	"math"
+
 	"gonum.org/v1/gonum/mat"
 )
 
@@ -13,12 +14,115 @@ import (
 // The similarity matrix returned is a matrix where each element is the similarity score between the query
 // vector and the corresponding index vector.
 func SimilarityMatrix(xq, index *mat.VecDense) float64 {
-	// normalize the query vector
-	xqNorm := mat.Norm(xq, 2)
-	// normalize the index vector
-	indexNorm := mat.Norm(index, 2)
-	// perform dot product between query vector and index vector
-	dot := floats.Dot(xq.RawVector().Data, index.RawVector().Data)
+	var dot, xqNorm, indexNorm float64
+	q, x := xq.RawVector(), index.RawVector()
+	for i := 0; i < xq.Len(); i++ {
+		a, b := q.Data[i*q.Inc], x.Data[i*x.Inc]
+		dot += a * b
+		xqNorm += a * a
+		indexNorm += b * b
+	}
 	// return the similarity score (dot product) divided by the product of the query vector norm and the index vector norm
-	return dot / (xqNorm * indexNorm)
+	return dot / (math.Sqrt(xqNorm) * math.Sqrt(indexNorm))
+}
+
+// CosineFromNorms computes the cosine similarity between the query vector and
+// the index vector from their precomputed L2 norms, see Norm.
+//
+// This only needs the dot product of the vectors, computed as in DotProduct,
+// avoiding to recompute the norm of index vectors on every query.
+func CosineFromNorms(xq, index *mat.VecDense, xqNorm, indexNorm float64) float64 {
+	return dot(xq, index) / (xqNorm * indexNorm)
+}
+
+// Norm computes the L2 norm of the given vector.
+func Norm(v *mat.VecDense) float64 {
+	var sum float64
+	raw := v.RawVector()
+	for i := 0; i < v.Len(); i++ {
+		sum += raw.Data[i*raw.Inc] * raw.Data[i*raw.Inc]
+	}
+	return math.Sqrt(sum)
+}
+
+// DotProduct computes the dot product of the query vector and the index
+// vector.
+//
+// Contiguous vectors are multiplied with SIMD instructions on amd64 CPUs
+// supporting AVX2 and FMA and on arm64, unless the purego build tag is set.
+func DotProduct(xq, index *mat.VecDense) float64 {
+	return dot(xq, index)
+}
+
+// EuclideanDistance computes the euclidean distance between the query vector
+// and the index vector.
+func EuclideanDistance(xq, index *mat.VecDense) float64 {
+	var sum float64
+	q, x := xq.RawVector(), index.RawVector()
+	for i := 0; i < xq.Len(); i++ {
+		d := q.Data[i*q.Inc] - x.Data[i*x.Inc]
+		sum += d * d
+	}
+	return math.Sqrt(sum)
+}
+
+// ManhattanDistance computes the manhattan distance between the query vector
+// and the index vector.
+func ManhattanDistance(xq, index *mat.VecDense) float64 {
+	var sum float64
+	q, x := xq.RawVector(), index.RawVector()
+	for i := 0; i < xq.Len(); i++ {
+		sum += math.Abs(q.Data[i*q.Inc] - x.Data[i*x.Inc])
+	}
+	return sum
+}
+
+// JaccardSimilarity computes the weighted jaccard similarity between the
+// query vector and the index vector.
+//
+// The weighted jaccard similarity is the sum of the element-wise minimums
+// divided by the sum of the element-wise maximums.
+func JaccardSimilarity(xq, index *mat.VecDense) float64 {
+	var minSum, maxSum float64
+	q, x := xq.RawVector(), index.RawVector()
+	for i := 0; i < xq.Len(); i++ {
+		a, b := q.Data[i*q.Inc], x.Data[i*x.Inc]
+		minSum += math.Min(a, b)
+		maxSum += math.Max(a, b)
+	}
+	if maxSum == 0 {
+		return 0
+	}
+	return minSum / maxSum
+}
+
+// PearsonCorrelation computes the pearson correlation coefficient between the
+// query vector and the index vector.
+func PearsonCorrelation(xq, index *mat.VecDense) float64 {
+	n := xq.Len()
+	q, x := xq.RawVector(), index.RawVector()
+	var qMean, xMean float64
+	for i := 0; i < n; i++ {
+		qMean += q.Data[i*q.Inc]
+		xMean += x.Data[i*x.Inc]
+	}
+	qMean /= float64(n)
+	xMean /= float64(n)
+	var cov, qVar, xVar float64
+	for i := 0; i < n; i++ {
+		a, b := q.Data[i*q.Inc]-qMean, x.Data[i*x.Inc]-xMean
+		cov += a * b
+		qVar += a * a
+		xVar += b * b
+	}
+	return cov / math.Sqrt(qVar*xVar)
+}
+
+// distanceToSimilarity converts a distance function into a similarity
+// function scoring 1 for identical vectors and approaching 0 as the distance
+// grows.
+func distanceToSimilarity(fn SimilarityFunc) SimilarityFunc {
+	return func(xq, index *mat.VecDense) float64 {
+		return 1 / (1 + fn(xq, index))
+	}
 }
@@ -2,10 +2,13 @@ package semanticrouter
 
 import (
 	"fmt"
+	"math"
+	"math/rand"
 	"testing"
 
 	"gonum.org/v1/gonum/floats"
 	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/stat"
 )
 
 // Helper function to create a VecDense from a slice
@@ -92,3 +95,139 @@ func TestSimilarityMatrix(t *testing.T) {
 		})
 	}
 }
+
+// TestBuiltinSimilarities tests the similarity functions provided by the
+// package on known vectors.
+func TestBuiltinSimilarities(t *testing.T) {
+	query := createVecDense([]float64{1, 2, 3})
+	index := createVecDense([]float64{2, 0, 4})
+	testCases := []struct {
+		name     string
+		fn       SimilarityFunc
+		expected float64
+	}{
+		{name: "dot product", fn: DotProduct, expected: 14},
+		{name: "euclidean", fn: EuclideanDistance, expected: 2.449489742783178},
+		{name: "manhattan", fn: ManhattanDistance, expected: 4},
+		{name: "jaccard", fn: JaccardSimilarity, expected: 4.0 / 8.0},
+		{name: "pearson", fn: PearsonCorrelation, expected: 0.5},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := tc.fn(query, index)
+			if math.Abs(tc.expected-actual) > 1e-9 {
+				t.Errorf("%s = %v; want %v", tc.name, actual, tc.expected)
+			}
+		})
+	}
+}
+
+// referenceSimilarities are the allocating implementations of the builtin
+// similarity functions, based on gonum, used to check the results of the
+// builtin ones.
+var referenceSimilarities = map[string][2]SimilarityFunc{
+	"cosine": {SimilarityMatrix, func(xq, index *mat.VecDense) float64 {
+		return mat.Dot(xq, index) / (mat.Norm(xq, 2) * mat.Norm(index, 2))
+	}},
+	"dot product": {DotProduct, func(xq, index *mat.VecDense) float64 {
+		return mat.Dot(xq, index)
+	}},
+	"euclidean": {EuclideanDistance, func(xq, index *mat.VecDense) float64 {
+		var diff mat.VecDense
+		diff.SubVec(xq, index)
+		return mat.Norm(&diff, 2)
+	}},
+	"manhattan": {ManhattanDistance, func(xq, index *mat.VecDense) float64 {
+		var diff mat.VecDense
+		diff.SubVec(xq, index)
+		return mat.Norm(&diff, 1)
+	}},
+	"pearson": {PearsonCorrelation, func(xq, index *mat.VecDense) float64 {
+		return stat.Correlation(
+			mat.Col(nil, 0, xq),
+			mat.Col(nil, 0, index),
+			nil,
+		)
+	}},
+}
+
+// TestSimilaritiesMatchReference tests that the builtin similarity functions
+// give the same results as their reference implementations, including on
+// strided vectors.
+func TestSimilaritiesMatchReference(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for name, fns := range referenceSimilarities {
+		t.Run(name, func(t *testing.T) {
+			for n := 1; n <= 64; n *= 2 {
+				data := make([]float64, 4*n)
+				for i := range data {
+					data[i] = rnd.Float64()*2 - 1
+				}
+				m := mat.NewDense(n, 4, data)
+				xq, index := m.ColView(1).(*mat.VecDense), m.ColView(3).(*mat.VecDense)
+				actual, expected := fns[0](xq, index), fns[1](xq, index)
+				if math.Abs(expected-actual) > 1e-12*math.Max(1, math.Abs(expected)) {
+					t.Errorf("%s(n=%d) = %v; want %v", name, n, actual, expected)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkSimilarities measures the builtin similarity functions on vectors
+// of a typical embedding dimension.
+func BenchmarkSimilarities(b *testing.B) {
+	rnd := rand.New(rand.NewSource(1))
+	xq, index := mat.NewVecDense(1536, nil), mat.NewVecDense(1536, nil)
+	for i := 0; i < 1536; i++ {
+		xq.SetVec(i, rnd.Float64())
+		index.SetVec(i, rnd.Float64())
+	}
+	for name, fns := range referenceSimilarities {
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				fns[0](xq, index)
+			}
+		})
+		b.Run(name+" reference", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				fns[1](xq, index)
+			}
+		})
+	}
+}
+
+// TestCosineFromNorms tests that the cosine similarity computed from
+// precomputed norms equals SimilarityMatrix.
+func TestCosineFromNorms(t *testing.T) {
+	xq := createVecDense([]float64{0.3, -1.2, 0.7, 2.5})
+	index := createVecDense([]float64{1.1, 0.4, -0.2, 0.9})
+	actual := CosineFromNorms(xq, index, Norm(xq), Norm(index))
+	if math.Abs(SimilarityMatrix(xq, index)-actual) > 1e-12 {
+		t.Errorf("CosineFromNorms = %v; want %v", actual, SimilarityMatrix(xq, index))
+	}
+}
+
+// BenchmarkCosine compares the cosine similarity computed from precomputed
+// norms with SimilarityMatrix computing both norms on every call.
+func BenchmarkCosine(b *testing.B) {
+	rnd := rand.New(rand.NewSource(1))
+	xq, index := mat.NewVecDense(1536, nil), mat.NewVecDense(1536, nil)
+	for i := 0; i < 1536; i++ {
+		xq.SetVec(i, rnd.Float64())
+		index.SetVec(i, rnd.Float64())
+	}
+	xqNorm, indexNorm := Norm(xq), Norm(index)
+	b.Run("naive", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			SimilarityMatrix(xq, index)
+		}
+	})
+	b.Run("precomputed norms", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			CosineFromNorms(xq, index, xqNorm, indexNorm)
+		}
+	})
+}
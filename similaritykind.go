@@ -0,0 +1,76 @@
+package semanticrouter
+
+// SimilarityKind identifies the kind of a similarity function configured on a
+// router, see Router.SimilarityConfig.
+type SimilarityKind int
+
+const (
+	// SimilarityKindCustom is a function added with WithCustomSimilarity or
+	// registered with RegisterSimilarity.
+	SimilarityKindCustom SimilarityKind = iota
+	// SimilarityKindDotProduct is DotProduct.
+	SimilarityKindDotProduct
+	// SimilarityKindCosine is the cosine similarity, see SimilarityMatrix.
+	SimilarityKindCosine
+	// SimilarityKindEuclidean is the similarity derived from
+	// EuclideanDistance.
+	SimilarityKindEuclidean
+	// SimilarityKindManhattan is the similarity derived from
+	// ManhattanDistance.
+	SimilarityKindManhattan
+	// SimilarityKindJaccard is JaccardSimilarity.
+	SimilarityKindJaccard
+	// SimilarityKindPearson is PearsonCorrelation.
+	SimilarityKindPearson
+	// SimilarityKindTextJaccard is TextJaccard.
+	SimilarityKindTextJaccard
+)
+
+// similarityKinds are the kinds of the similarity functions provided by the
+// package, keyed by name.
+var similarityKinds = map[string]SimilarityKind{
+	SimilarityDotProduct:  SimilarityKindDotProduct,
+	SimilarityCosine:      SimilarityKindCosine,
+	SimilarityEuclidean:   SimilarityKindEuclidean,
+	SimilarityManhattan:   SimilarityKindManhattan,
+	SimilarityJaccard:     SimilarityKindJaccard,
+	SimilarityPearson:     SimilarityKindPearson,
+	SimilarityTextJaccard: SimilarityKindTextJaccard,
+}
+
+// String returns the name of the similarity functions of the kind, such as
+// SimilarityCosine, or "custom".
+func (k SimilarityKind) String() string {
+	for name, kind := range similarityKinds {
+		if kind == k {
+			return name
+		}
+	}
+	return "custom"
+}
+
+// SimilarityInfo describes a similarity function configured on a router.
+type SimilarityInfo struct {
+	Kind        SimilarityKind // Kind is the kind of the function.
+	Name        string         // Name is the name the function was configured with.
+	Coefficient float64        // Coefficient is the weight of the function.
+}
+
+// SimilarityConfig returns the similarity functions configured on the
+// router, in the order their scores are summed.
+//
+// The functions overridden by routes are not included. An empty result means
+// the default cosine similarity is used.
+func (r *Router) SimilarityConfig() []SimilarityInfo {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	infos := make([]SimilarityInfo, 0, len(r.biFuncCoefficients))
+	for _, bf := range r.biFuncCoefficients {
+		infos = append(infos, SimilarityInfo{
+			Kind:        bf.kind,
+			Name:        bf.name,
+			Coefficient: bf.coefficient,
+		})
+	}
+	return infos
+}
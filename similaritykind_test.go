@@ -0,0 +1,44 @@
+package semanticrouter
+
+import (
+	"testing"
+
+	"github.com/conneroisu/go-semantic-router/stores/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSimilarityKindString tests the names of the similarity kinds.
+func TestSimilarityKindString(t *testing.T) {
+	for kind, want := range map[SimilarityKind]string{
+		SimilarityKindCustom:     "custom",
+		SimilarityKindDotProduct: SimilarityDotProduct,
+		SimilarityKindCosine:     SimilarityCosine,
+		SimilarityKindEuclidean:  SimilarityEuclidean,
+		SimilarityKindManhattan:  SimilarityManhattan,
+		SimilarityKindJaccard:    SimilarityJaccard,
+		SimilarityKindPearson:    SimilarityPearson,
+		SimilarityKind(42):       "custom",
+	} {
+		assert.Equal(t, want, kind.String())
+	}
+}
+
+// TestSimilarityConfig tests that the configured similarity functions are
+// described with their kinds.
+func TestSimilarityConfig(t *testing.T) {
+	router, err := NewRouter(
+		newTestRoutes(),
+		newTestEncoder(),
+		memory.NewStore(),
+		WithCosineSimilarity(0.5),
+		WithManhattanDistance(0.2),
+		WithCustomSimilarity("mine", DotProduct, 0.3),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, []SimilarityInfo{
+		{Kind: SimilarityKindCosine, Name: SimilarityCosine, Coefficient: 0.5},
+		{Kind: SimilarityKindManhattan, Name: SimilarityManhattan, Coefficient: 0.2},
+		{Kind: SimilarityKindCustom, Name: "mine", Coefficient: 0.3},
+	}, router.SimilarityConfig())
+}
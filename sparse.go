@@ -0,0 +1,67 @@
+package semanticrouter
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/conneroisu/go-semantic-router/domain"
+)
+
+// SparseEncoder is an Encoder that can also encode utterances into sparse
+// embeddings.
+type SparseEncoder interface {
+	Encoder
+	EncodeSparse(ctx context.Context, utterance string) (domain.SparseEmbedding, error)
+}
+
+// SparseStore is a Store that can also store sparse embeddings.
+type SparseStore interface {
+	Store
+	StoreSparse(ctx context.Context, utterance domain.SparseUtterance) error
+	GetSparse(ctx context.Context, utterance string) (domain.SparseEmbedding, error)
+}
+
+// storeSparseUtterance encodes the given utterance into a sparse embedding
+// and stores it.
+func (r *Router) storeSparseUtterance(
+	ctx context.Context,
+	utter domain.Utterance,
+) error {
+	en, err := r.encodeSparse(ctx, r.documentInput(utter.Utterance))
+	if err != nil {
+		return ErrEncoding{Message: "error encoding utterance", Err: err}
+	}
+	err = r.storeSparse(ctx, domain.SparseUtterance{
+		Utterance: utter.Utterance,
+		Embedding: en,
+	})
+	if err != nil {
+		return fmt.Errorf(
+			"error storing utterance: %s: %w",
+			utter.Utterance,
+			err,
+		)
+	}
+	return nil
+}
+
+// SparseDotProduct computes the dot product of two sparse embeddings.
+//
+// The indices of both embeddings must be sorted in ascending order.
+func SparseDotProduct(a, b domain.SparseEmbedding) float64 {
+	var dot float64
+	i, j := 0, 0
+	for i < len(a.Indices) && j < len(b.Indices) {
+		switch {
+		case a.Indices[i] < b.Indices[j]:
+			i++
+		case a.Indices[i] > b.Indices[j]:
+			j++
+		default:
+			dot += a.Values[i] * b.Values[j]
+			i++
+			j++
+		}
+	}
+	return dot
+}
@@ -0,0 +1,87 @@
+package semanticrouter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/conneroisu/go-semantic-router/domain"
+	"github.com/conneroisu/go-semantic-router/stores/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gonum.org/v1/gonum/floats"
+)
+
+// sparseEncoder is a mock encoder returning the sparse form of the fixed
+// embeddings of a mockEncoder.
+type sparseEncoder struct {
+	*mockEncoder
+}
+
+// EncodeSparse returns the sparse form of the fixed embedding of the given
+// utterance.
+func (s sparseEncoder) EncodeSparse(
+	ctx context.Context,
+	utterance string,
+) (domain.SparseEmbedding, error) {
+	em, err := s.Encode(ctx, utterance)
+	if err != nil {
+		return domain.SparseEmbedding{}, err
+	}
+	return domain.NewSparseEmbedding(em), nil
+}
+
+// TestSparseDotProduct tests that the sparse dot product equals the dense dot
+// product of the equivalent dense vectors.
+func TestSparseDotProduct(t *testing.T) {
+	testCases := []struct {
+		a, b []float64
+	}{
+		{a: []float64{0, 1, 0, 2}, b: []float64{3, 4, 0, 5}},
+		{a: []float64{1, 0, 0, 0, 0, 7}, b: []float64{0, 0, 0, 0, 0, 2}},
+		{a: []float64{0, 0, 1}, b: []float64{1, 1, 0}},
+		{a: []float64{0.5, -1.5, 0, 2.25}, b: []float64{-2, 0.25, 9, 1}},
+	}
+	for _, tc := range testCases {
+		sparse := SparseDotProduct(
+			domain.NewSparseEmbedding(tc.a),
+			domain.NewSparseEmbedding(tc.b),
+		)
+		assert.InDelta(t, floats.Dot(tc.a, tc.b), sparse, 1e-12)
+	}
+
+	unsorted := domain.SparseEmbedding{
+		Indices: []int{3, 0, 1},
+		Values:  []float64{2, 4, 1},
+	}.Sorted()
+	assert.Equal(t, []int{0, 1, 3}, unsorted.Indices)
+	assert.Equal(t, []float64{4, 1, 2}, unsorted.Values)
+}
+
+// TestSparseMatch tests that a router using sparse embeddings matches
+// queries against sparse index embeddings.
+func TestSparseMatch(t *testing.T) {
+	ctx := context.Background()
+	encoder := sparseEncoder{newTestEncoder()}
+	router, err := NewRouter(
+		newTestRoutes(),
+		encoder,
+		memory.NewStore(),
+		WithSparseEmbeddings(),
+	)
+	require.NoError(t, err)
+
+	route, _, err := router.Match(ctx, "is it raining outside?")
+	require.NoError(t, err)
+	assert.Equal(t, "chitchat", route)
+	route, _, err = router.Match(ctx, "what about the election?")
+	require.NoError(t, err)
+	assert.Equal(t, "politics", route)
+
+	_, err = NewRouter(
+		newTestRoutes(),
+		newTestEncoder(),
+		memory.NewStore(),
+		WithSparseEmbeddings(),
+	)
+	assert.Error(t, err)
+}
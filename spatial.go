@@ -0,0 +1,224 @@
+package semanticrouter
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// spatialTreeMaxDimension is the highest dimension for which the spatial
+// index uses a KD-tree; above it, pruning rarely pays off and the index is
+// scanned linearly.
+const spatialTreeMaxDimension = 10
+
+// nearestIndex is an in-process index of the dense embeddings of a router,
+// finding the utterance most similar to a query by cosine similarity.
+type nearestIndex interface {
+	// nearest returns the entry whose embedding is the most similar to the
+	// given query, and false if the index has none.
+	nearest(q query) (indexEntry, bool)
+	// dimension returns the dimension of the indexed embeddings.
+	dimension() int
+}
+
+// spatialPoint is a normalized index embedding along with the position of
+// its entry in the index.
+type spatialPoint struct {
+	vec []float64
+	pos int
+}
+
+// spatialIndex is an in-process index of the normalized embeddings of the
+// utterances of a router, finding the utterance with the highest cosine
+// similarity to a query.
+//
+// Between unit vectors, the nearest by euclidean distance is the most similar
+// by cosine similarity, so the points are searched by euclidean distance.
+type spatialIndex struct {
+	dim     int
+	tree    bool           // tree is whether points are laid out as a KD-tree.
+	points  []spatialPoint // points are the normalized embeddings, in tree order if tree is set.
+	entries []indexEntry   // entries are the entries of the index the points refer to.
+}
+
+// newSpatialIndex creates a spatial index over the dense embeddings of the
+// given index entries.
+//
+// Entries with a zero norm, which never match, are left out. It returns nil
+// if the entries do not all have the same dimension.
+func newSpatialIndex(index []indexEntry) *spatialIndex {
+	s := &spatialIndex{entries: index}
+	for pos, entry := range index {
+		if pos == 0 {
+			s.dim = entry.vec.Len()
+		}
+		if entry.vec.Len() != s.dim {
+			return nil
+		}
+		if entry.norm == 0 {
+			continue
+		}
+		s.points = append(s.points, spatialPoint{vec: unitVector(entry.vec, entry.norm), pos: pos})
+	}
+	if s.dim <= spatialTreeMaxDimension {
+		s.tree = true
+		s.build(0, len(s.points), 0)
+	}
+	return s
+}
+
+// build lays out the points in [lo, hi) as a KD-tree split on the axis of
+// the given depth: the median point is the root and the points before and
+// after it are its subtrees.
+func (s *spatialIndex) build(lo, hi, depth int) {
+	if hi-lo <= 1 {
+		return
+	}
+	axis := depth % s.dim
+	points := s.points[lo:hi]
+	sort.Slice(points, func(i, j int) bool {
+		return points[i].vec[axis] < points[j].vec[axis]
+	})
+	mid := (lo + hi) / 2
+	s.build(lo, mid, depth+1)
+	s.build(mid+1, hi, depth+1)
+}
+
+// dimension returns the dimension of the indexed embeddings.
+func (s *spatialIndex) dimension() int {
+	return s.dim
+}
+
+// nearest returns the entry whose embedding is the most similar to the given
+// query, and false if the index has none.
+//
+// Equally similar entries are broken by their position in the index, as in a
+// linear scan of the index.
+func (s *spatialIndex) nearest(q query) (indexEntry, bool) {
+	vec := unitVector(q.vec, q.norm)
+	best, bestDist := -1, 0.0
+	if s.tree {
+		s.search(vec, 0, len(s.points), 0, &best, &bestDist)
+	} else {
+		for i := range s.points {
+			s.visit(vec, i, &best, &bestDist)
+		}
+	}
+	if best == -1 {
+		return indexEntry{}, false
+	}
+	return s.entries[s.points[best].pos], true
+}
+
+// search searches the KD-tree of the points in [lo, hi) for the point
+// nearest to vec.
+func (s *spatialIndex) search(
+	vec []float64,
+	lo, hi, depth int,
+	best *int,
+	bestDist *float64,
+) {
+	if lo >= hi {
+		return
+	}
+	mid := (lo + hi) / 2
+	s.visit(vec, mid, best, bestDist)
+	axis := depth % s.dim
+	diff := vec[axis] - s.points[mid].vec[axis]
+	nearLo, nearHi, farLo, farHi := lo, mid, mid+1, hi
+	if diff > 0 {
+		nearLo, nearHi, farLo, farHi = mid+1, hi, lo, mid
+	}
+	s.search(vec, nearLo, nearHi, depth+1, best, bestDist)
+	if diff*diff <= *bestDist {
+		s.search(vec, farLo, farHi, depth+1, best, bestDist)
+	}
+}
+
+// visit makes the point at position i the best one if it is nearer to vec
+// than the current best one.
+func (s *spatialIndex) visit(vec []float64, i int, best *int, bestDist *float64) {
+	point := s.points[i]
+	var dist float64
+	for d, v := range point.vec {
+		dist += (v - vec[d]) * (v - vec[d])
+	}
+	if *best == -1 ||
+		dist < *bestDist ||
+		dist == *bestDist && point.pos < s.points[*best].pos {
+		*best, *bestDist = i, dist
+	}
+}
+
+// unitVector returns the components of v divided by its given norm.
+func unitVector(v *mat.VecDense, norm float64) []float64 {
+	unit := make([]float64, v.Len())
+	for i := range unit {
+		unit[i] = v.AtVec(i) / norm
+	}
+	return unit
+}
+
+// buildSpatialIndex builds the spatial index of the router from its current
+// index, if enabled with WithSpatialIndex or WithHNSW.
+//
+// The spatial index is left unset when the router's configuration scores
+// routes other than by the cosine similarity of their best utterance, such
+// as with route priors, in which case Match scans the index linearly.
+func (r *Router) buildSpatialIndex(ctx context.Context) error {
+	r.spatial = nil
+	if !r.spatialEnabled && r.hnsw == nil ||
+		r.embeddings != denseEmbeddings ||
+		len(r.biFuncCoefficients) > 0 ||
+		len(r.routeFuncs) > 0 ||
+		r.knn > 0 ||
+		r.aggregation != AggregationMax ||
+		r.scoreDirection != HigherIsBetter ||
+		r.reranker != nil ||
+		r.hasPriors() ||
+		r.hasKeywordGates() {
+		return nil
+	}
+	index, err := r.loadIndex(ctx)
+	if err != nil {
+		return fmt.Errorf("error building spatial index: %w", err)
+	}
+	index = flattenVariants(index)
+	if r.hnsw != nil {
+		if h := newHNSWIndex(index, *r.hnsw); h != nil {
+			r.spatial = h
+		}
+		return nil
+	}
+	if s := newSpatialIndex(index); s != nil {
+		r.spatial = s
+	}
+	return nil
+}
+
+// matchSpatial returns the route best matching the given queries using the
+// spatial index, and false if the spatial index cannot be used for them.
+func (r *Router) matchSpatial(
+	qs []query,
+) (bestRouteName string, bestScore float64, ok bool, err error) {
+	if r.spatial == nil ||
+		len(qs) != 1 ||
+		qs[0].vec.Len() != r.spatial.dimension() {
+		return "", 0.0, false, nil
+	}
+	entry, found := r.spatial.nearest(qs[0])
+	if found {
+		var es entryScore
+		es, found, err = r.scoreEntry(qs[0], entry)
+		if err != nil {
+			return "", 0.0, true, err
+		}
+		bestRouteName, bestScore = entry.route, es.score
+	}
+	if !found || !r.accepts(bestScore) {
+		return "", 0.0, true, ErrNoRouteFound
+	}
+	return bestRouteName, bestScore, true, nil
+}
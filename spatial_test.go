@@ -0,0 +1,151 @@
+package semanticrouter
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/conneroisu/go-semantic-router/domain"
+	"github.com/conneroisu/go-semantic-router/stores/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// addRandomQueries adds n random queries of the given dimension to the
+// encoder, returning their utterances.
+func addRandomQueries(encoder *mockEncoder, n, dimension int) []string {
+	rnd := rand.New(rand.NewSource(2))
+	queries := make([]string, n)
+	for i := range queries {
+		v := make([]float64, dimension)
+		for j := range v {
+			v[j] = rnd.Float64() - 0.25
+		}
+		queries[i] = fmt.Sprintf("query %d", i)
+		encoder.embeddings[queries[i]] = v
+	}
+	return queries
+}
+
+// TestWithSpatialIndex tests that matching with the spatial index gives the
+// same results as scanning the index, both with a KD-tree and above its
+// dimension limit.
+func TestWithSpatialIndex(t *testing.T) {
+	ctx := context.Background()
+	for _, dimension := range []int{2, 8, 64} {
+		t.Run(fmt.Sprintf("dimension=%d", dimension), func(t *testing.T) {
+			routes, encoder := newRandomRoutes(20, 25, dimension)
+			queries := addRandomQueries(encoder, 200, dimension)
+			store := memory.NewStore()
+			linear, err := NewRouter(routes, encoder, store)
+			require.NoError(t, err)
+			spatial, err := NewRouter(routes, encoder, store, WithSpatialIndex(true))
+			require.NoError(t, err)
+			require.NotNil(t, spatial.spatial)
+			assert.Equal(t, dimension <= spatialTreeMaxDimension, spatial.spatial.(*spatialIndex).tree)
+
+			for _, query := range queries {
+				wantRoute, wantScore, wantErr := linear.Match(ctx, query)
+				gotRoute, gotScore, gotErr := spatial.Match(ctx, query)
+				assert.Equal(t, wantErr, gotErr, query)
+				assert.Equal(t, wantRoute, gotRoute, query)
+				assert.Equal(t, wantScore, gotScore, query)
+			}
+		})
+	}
+}
+
+// TestWithSpatialIndexUpdates tests that the spatial index follows the routes
+// added to the router, and that it is not used with custom similarities.
+func TestWithSpatialIndexUpdates(t *testing.T) {
+	ctx := context.Background()
+	encoder := newTestEncoder()
+	router, err := NewRouter(newTestRoutes()[:1], encoder, memory.NewStore(), WithSpatialIndex(true))
+	require.NoError(t, err)
+	route, _, err := router.Match(ctx, "what about the election?")
+	require.NoError(t, err)
+	assert.Equal(t, "chitchat", route)
+
+	require.NoError(t, router.AddRoute(ctx, newTestRoutes()[1]))
+	route, _, err = router.Match(ctx, "what about the election?")
+	require.NoError(t, err)
+	assert.Equal(t, "politics", route)
+
+	_, err = router.UpdateRoute(ctx, "politics", []domain.Utterance{{Utterance: "lovely weather today"}})
+	require.NoError(t, err)
+	route, _, err = router.Match(ctx, "what about the election?")
+	require.NoError(t, err)
+	assert.Equal(t, "chitchat", route)
+
+	router, err = NewRouter(
+		newTestRoutes(),
+		encoder,
+		memory.NewStore(),
+		WithSpatialIndex(true),
+		WithDotProduct(1),
+	)
+	require.NoError(t, err)
+	assert.Nil(t, router.spatial)
+}
+
+// BenchmarkSpatialIndex compares matching by scanning the index and with the
+// spatial index, for growing numbers of utterances and dimensions, to show
+// where the spatial index starts paying off.
+func BenchmarkSpatialIndex(b *testing.B) {
+	ctx := context.Background()
+	for _, dimension := range []int{3, 8, 16, 64} {
+		for _, utterances := range []int{10, 100, 1000} {
+			routes, encoder := newRandomRoutes(10, utterances/10+1, dimension)
+			for _, enabled := range []bool{false, true} {
+				router, err := NewRouter(
+					routes,
+					encoder,
+					memory.NewStore(),
+					WithSpatialIndex(enabled),
+				)
+				require.NoError(b, err)
+				name := fmt.Sprintf(
+					"dimension=%d/utterances=%d/spatial=%t",
+					dimension,
+					utterances,
+					enabled,
+				)
+				b.Run(name, func(b *testing.B) {
+					for i := 0; i < b.N; i++ {
+						_, _, _ = router.Match(ctx, "query")
+					}
+				})
+			}
+		}
+	}
+}
+
+// BenchmarkSpatialTree compares searching the spatial index as a KD-tree and
+// scanning it, to show the dimension above which the KD-tree stops paying
+// off, see spatialTreeMaxDimension.
+func BenchmarkSpatialTree(b *testing.B) {
+	ctx := context.Background()
+	for _, dimension := range []int{3, 8, 10, 12, 16, 32} {
+		routes, encoder := newRandomRoutes(10, 500, dimension)
+		router, err := NewRouter(routes, encoder, memory.NewStore())
+		require.NoError(b, err)
+		index, err := router.loadIndex(ctx)
+		require.NoError(b, err)
+		qs, err := router.encodeQueries(ctx, "query")
+		require.NoError(b, err)
+		tree := newSpatialIndex(index)
+		tree.tree = true
+		tree.build(0, len(tree.points), 0)
+		scan := newSpatialIndex(index)
+		scan.tree = false
+		for _, s := range []*spatialIndex{tree, scan} {
+			name := fmt.Sprintf("dimension=%d/tree=%t", dimension, s.tree)
+			b.Run(name, func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					_, _ = s.nearest(qs[0])
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package semanticrouter
+
+import (
+	"sync"
+	"time"
+)
+
+// RouteStats are the statistics of the matches won by a route, see
+// Router.Stats.
+type RouteStats struct {
+	Wins      int       // Wins is the number of matches the route won.
+	LastWin   time.Time // LastWin is when the route last won a match.
+	MeanScore float64   // MeanScore is the mean score of the matches the route won.
+}
+
+// routeStats are the statistics of the routes of a router, keyed by route
+// name.
+type routeStats struct {
+	mu    sync.Mutex
+	stats map[string]RouteStats
+}
+
+// newRouteStats creates empty route statistics.
+func newRouteStats() *routeStats {
+	return &routeStats{stats: make(map[string]RouteStats)}
+}
+
+// record records a match won by the given route with the given score.
+func (s *routeStats) record(route string, score float64, at time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st := s.stats[route]
+	st.Wins++
+	st.LastWin = at
+	st.MeanScore += (score - st.MeanScore) / float64(st.Wins)
+	s.stats[route] = st
+}
+
+// Stats returns the statistics of the matches won by each route, keyed by
+// route name.
+//
+// Statistics are updated by each successful Match and MatchExact call;
+// routes that never won a match are omitted. The returned map is a copy,
+// safe to use while the router keeps matching.
+func (r *Router) Stats() map[string]RouteStats {
+	stats := make(map[string]RouteStats)
+	if r.stats == nil {
+		return stats
+	}
+	r.stats.mu.Lock()
+	defer r.stats.mu.Unlock()
+	for route, st := range r.stats.stats {
+		stats[route] = st
+	}
+	return stats
+}
@@ -0,0 +1,44 @@
+package semanticrouter
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/conneroisu/go-semantic-router/stores/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestStats tests that the statistics of each route count the matches it
+// won, including concurrent ones.
+func TestStats(t *testing.T) {
+	ctx := context.Background()
+	router, err := NewRouter(newTestRoutes(), newTestEncoder(), memory.NewStore())
+	require.NoError(t, err)
+	assert.Empty(t, router.Stats())
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _, err := router.Match(ctx, "is it raining outside?")
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+	_, electionScore, err := router.Match(ctx, "what about the election?")
+	require.NoError(t, err)
+	_, _, err = router.Match(ctx, "unknown")
+	require.Error(t, err)
+
+	stats := router.Stats()
+	require.Len(t, stats, 2)
+	assert.Equal(t, 3, stats["chitchat"].Wins)
+	assert.Equal(t, 1, stats["politics"].Wins)
+	assert.InDelta(t, electionScore, stats["politics"].MeanScore, 1e-12)
+	assert.False(t, stats["chitchat"].LastWin.Before(start))
+}
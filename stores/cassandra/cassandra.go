@@ -0,0 +1,112 @@
+// Package cassandra provides a key-value store for embeddings backed by
+// Apache Cassandra or ScyllaDB.
+package cassandra
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/conneroisu/go-semantic-router/domain"
+	"github.com/gocql/gocql"
+)
+
+// Store is a key-value store for embeddings backed by a Cassandra table.
+//
+// The table is keyed by utterance and holds the embedding as a
+// frozen<list<double>>. Being frozen, the list is written as a single cell, so
+// overwriting an embedding does not leave collection tombstones behind.
+type Store struct {
+	session     *gocql.Session
+	keyspace    string
+	table       string
+	consistency gocql.Consistency
+}
+
+// Option is a function that configures a Store.
+type Option func(*Store)
+
+// WithConsistency sets the consistency level of the store's queries.
+//
+// The default is gocql.Quorum.
+func WithConsistency(consistency gocql.Consistency) Option {
+	return func(s *Store) {
+		s.consistency = consistency
+	}
+}
+
+// NewStore creates a new Store from a gocql session, creating the table in
+// the given keyspace if it does not exist.
+func NewStore(
+	ctx context.Context,
+	session *gocql.Session,
+	keyspace, table string,
+	opts ...Option,
+) (*Store, error) {
+	s := &Store{
+		session:     session,
+		keyspace:    keyspace,
+		table:       table,
+		consistency: gocql.Quorum,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	err := s.session.Query(fmt.Sprintf(
+		`CREATE TABLE IF NOT EXISTS %s (
+			utterance text PRIMARY KEY,
+			embedding frozen<list<double>>
+		)`,
+		s.name(),
+	)).WithContext(ctx).Consistency(s.consistency).Exec()
+	if err != nil {
+		return nil, fmt.Errorf("error creating table: %w", err)
+	}
+	return s, nil
+}
+
+// name returns the fully qualified name of the store's table.
+func (s *Store) name() string {
+	return fmt.Sprintf("%q.%q", s.keyspace, s.table)
+}
+
+// Get gets a value from the store.
+func (s *Store) Get(
+	ctx context.Context,
+	utterance string,
+) (embedding []float64, err error) {
+	err = s.session.Query(
+		fmt.Sprintf(`SELECT embedding FROM %s WHERE utterance = ?`, s.name()),
+		utterance,
+	).WithContext(ctx).Consistency(s.consistency).Scan(&embedding)
+	if err != nil {
+		if errors.Is(err, gocql.ErrNotFound) {
+			return nil, fmt.Errorf("key does not exist: %w", err)
+		}
+		return nil, fmt.Errorf("error getting embedding: %w", err)
+	}
+	return embedding, nil
+}
+
+// Store sets a value in the store.
+func (s *Store) Store(
+	ctx context.Context,
+	utterance domain.Utterance,
+) error {
+	embedding, err := utterance.Embedding()
+	if err != nil {
+		return fmt.Errorf("error getting embedding: %w", err)
+	}
+	err = s.session.Query(
+		fmt.Sprintf(
+			`INSERT INTO %s (utterance, embedding) VALUES (?, ?)`,
+			s.name(),
+		),
+		utterance.Utterance,
+		embedding,
+	).WithContext(ctx).Consistency(s.consistency).Exec()
+	if err != nil {
+		return fmt.Errorf("error storing embedding: %w", err)
+	}
+	return nil
+}
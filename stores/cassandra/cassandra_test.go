@@ -0,0 +1,57 @@
+//go:build integration
+
+package cassandra
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/conneroisu/go-semantic-router/domain"
+	"github.com/gocql/gocql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+// TestStore is a test for the cassandra store.
+func TestStore(t *testing.T) {
+	ctx := context.Background()
+	container, err := testcontainers.GenericContainer(
+		ctx,
+		testcontainers.GenericContainerRequest{
+			ContainerRequest: testcontainers.ContainerRequest{
+				Image:        "cassandra:4.1",
+				ExposedPorts: []string{"9042/tcp"},
+				WaitingFor: wait.ForLog("Startup complete").
+					WithStartupTimeout(3 * time.Minute),
+			},
+			Started: true,
+		})
+	require.NoError(t, err)
+	defer func() { _ = container.Terminate(ctx) }()
+	endpoint, err := container.PortEndpoint(ctx, "9042/tcp", "")
+	require.NoError(t, err)
+
+	cluster := gocql.NewCluster(endpoint)
+	cluster.Consistency = gocql.One
+	session, err := cluster.CreateSession()
+	require.NoError(t, err)
+	defer session.Close()
+	err = session.Query(`CREATE KEYSPACE IF NOT EXISTS router WITH replication = {'class': 'SimpleStrategy', 'replication_factor': 1}`).Exec()
+	require.NoError(t, err)
+
+	store, err := NewStore(ctx, session, "router", "utterances", WithConsistency(gocql.One))
+	require.NoError(t, err)
+	utter := domain.Utterance{Utterance: "key"}
+	require.NoError(t, utter.SetEmbedding([]float64{1.0, 2.0, 3.0, 4.0, 5.0}))
+	require.NoError(t, store.Store(ctx, utter))
+
+	floats, err := store.Get(ctx, "key")
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{1.0, 2.0, 3.0, 4.0, 5.0}, floats)
+
+	_, err = store.Get(ctx, "missing")
+	assert.ErrorIs(t, err, gocql.ErrNotFound)
+}
@@ -0,0 +1,175 @@
+// Package dynamodb provides a key-value store for embeddings backed by
+// Amazon DynamoDB.
+package dynamodb
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/conneroisu/go-semantic-router/domain"
+)
+
+const (
+	// KeyAttribute is the name of the partition key attribute holding the
+	// utterance.
+	KeyAttribute = "utterance"
+	// EmbeddingAttribute is the name of the binary attribute holding the
+	// embedding.
+	EmbeddingAttribute = "embedding"
+)
+
+// API is the subset of the DynamoDB client used by the store.
+type API interface {
+	GetItem(
+		ctx context.Context,
+		params *dynamodb.GetItemInput,
+		optFns ...func(*dynamodb.Options),
+	) (*dynamodb.GetItemOutput, error)
+	PutItem(
+		ctx context.Context,
+		params *dynamodb.PutItemInput,
+		optFns ...func(*dynamodb.Options),
+	) (*dynamodb.PutItemOutput, error)
+}
+
+// Store is a key-value store for embeddings backed by a DynamoDB table.
+//
+// The table must have a string partition key named KeyAttribute. Embeddings
+// are stored in EmbeddingAttribute as little-endian float64 binaries.
+type Store struct {
+	client       API
+	table        string
+	ttl          time.Duration
+	ttlAttribute string
+	now          func() time.Time
+}
+
+// Option is a function that configures a Store.
+type Option func(*Store)
+
+// WithTTL makes stored items expire after the given duration.
+//
+// The expiry time is written, in epoch seconds, to the given attribute, which
+// must be configured as the table's TTL attribute for DynamoDB to delete
+// expired items. Expired items are missing to Get even before DynamoDB
+// deletes them.
+func WithTTL(attribute string, ttl time.Duration) Option {
+	return func(s *Store) {
+		s.ttlAttribute = attribute
+		s.ttl = ttl
+	}
+}
+
+// NewStore creates a new Store from a DynamoDB client and a table name.
+func NewStore(client API, table string, opts ...Option) *Store {
+	s := &Store{
+		client: client,
+		table:  table,
+		now:    time.Now,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Get gets a value from the store.
+func (s *Store) Get(
+	ctx context.Context,
+	utterance string,
+) (embedding []float64, err error) {
+	out, err := s.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(s.table),
+		Key: map[string]types.AttributeValue{
+			KeyAttribute: &types.AttributeValueMemberS{Value: utterance},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error getting item: %w", err)
+	}
+	if out.Item == nil || s.expired(out.Item) {
+		return nil, fmt.Errorf("key does not exist: %s", utterance)
+	}
+	attr, ok := out.Item[EmbeddingAttribute].(*types.AttributeValueMemberB)
+	if !ok {
+		return nil, fmt.Errorf("item has no binary embedding: %s", utterance)
+	}
+	return decode(attr.Value)
+}
+
+// expired reports whether the given item has expired, see WithTTL.
+//
+// DynamoDB deletes expired items lazily, typically within a few days, so
+// they must be filtered out when read. Items without a valid expiry time
+// never expire.
+func (s *Store) expired(item map[string]types.AttributeValue) bool {
+	if s.ttl <= 0 {
+		return false
+	}
+	attr, ok := item[s.ttlAttribute].(*types.AttributeValueMemberN)
+	if !ok {
+		return false
+	}
+	expiry, err := strconv.ParseInt(attr.Value, 10, 64)
+	if err != nil {
+		return false
+	}
+	return expiry <= s.now().Unix()
+}
+
+// Store sets a value in the store.
+func (s *Store) Store(
+	ctx context.Context,
+	utterance domain.Utterance,
+) error {
+	embedding, err := utterance.Embedding()
+	if err != nil {
+		return fmt.Errorf("error getting embedding: %w", err)
+	}
+	item := map[string]types.AttributeValue{
+		KeyAttribute:       &types.AttributeValueMemberS{Value: utterance.Utterance},
+		EmbeddingAttribute: &types.AttributeValueMemberB{Value: encode(embedding)},
+	}
+	if s.ttl > 0 {
+		expiry := s.now().Add(s.ttl).Unix()
+		item[s.ttlAttribute] = &types.AttributeValueMemberN{
+			Value: strconv.FormatInt(expiry, 10),
+		}
+	}
+	_, err = s.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(s.table),
+		Item:      item,
+	})
+	if err != nil {
+		return fmt.Errorf("error putting item: %w", err)
+	}
+	return nil
+}
+
+// encode encodes an embedding as a little-endian float64 binary.
+func encode(embedding []float64) []byte {
+	buf := make([]byte, 8*len(embedding))
+	for i, v := range embedding {
+		binary.LittleEndian.PutUint64(buf[8*i:], math.Float64bits(v))
+	}
+	return buf
+}
+
+// decode decodes a little-endian float64 binary into an embedding.
+func decode(buf []byte) ([]float64, error) {
+	if len(buf)%8 != 0 {
+		return nil, fmt.Errorf("invalid embedding length: %d", len(buf))
+	}
+	embedding := make([]float64, len(buf)/8)
+	for i := range embedding {
+		embedding[i] = math.Float64frombits(binary.LittleEndian.Uint64(buf[8*i:]))
+	}
+	return embedding, nil
+}
@@ -0,0 +1,91 @@
+//go:build integration
+
+package dynamodb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/conneroisu/go-semantic-router/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+// TestStore is a test for the dynamodb store using a local DynamoDB
+// container.
+func TestStore(t *testing.T) {
+	ctx := context.Background()
+	container, err := testcontainers.GenericContainer(
+		ctx,
+		testcontainers.GenericContainerRequest{
+			ContainerRequest: testcontainers.ContainerRequest{
+				Image:        "amazon/dynamodb-local:2.5.2",
+				ExposedPorts: []string{"8000/tcp"},
+				WaitingFor:   wait.ForListeningPort("8000/tcp"),
+			},
+			Started: true,
+		})
+	require.NoError(t, err)
+	defer func() { _ = container.Terminate(ctx) }()
+	endpoint, err := container.PortEndpoint(ctx, "8000/tcp", "http")
+	require.NoError(t, err)
+
+	client := dynamodb.New(dynamodb.Options{
+		BaseEndpoint: aws.String(endpoint),
+		Region:       "us-east-1",
+		Credentials: aws.CredentialsProviderFunc(
+			func(context.Context) (aws.Credentials, error) {
+				return aws.Credentials{AccessKeyID: "local", SecretAccessKey: "local"}, nil
+			},
+		),
+	})
+	_, err = client.CreateTable(ctx, &dynamodb.CreateTableInput{
+		TableName: aws.String("utterances"),
+		AttributeDefinitions: []types.AttributeDefinition{{
+			AttributeName: aws.String(KeyAttribute),
+			AttributeType: types.ScalarAttributeTypeS,
+		}},
+		KeySchema: []types.KeySchemaElement{{
+			AttributeName: aws.String(KeyAttribute),
+			KeyType:       types.KeyTypeHash,
+		}},
+		BillingMode: types.BillingModePayPerRequest,
+	})
+	require.NoError(t, err)
+
+	store := NewStore(client, "utterances", WithTTL("expires_at", time.Hour))
+	utter := domain.Utterance{Utterance: "key"}
+	require.NoError(t, utter.SetEmbedding([]float64{1.0, 2.0, 3.0, 4.0, 5.0}))
+	require.NoError(t, store.Store(ctx, utter))
+
+	floats, err := store.Get(ctx, "key")
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{1.0, 2.0, 3.0, 4.0, 5.0}, floats)
+
+	out, err := client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String("utterances"),
+		Key: map[string]types.AttributeValue{
+			KeyAttribute: &types.AttributeValueMemberS{Value: "key"},
+		},
+	})
+	require.NoError(t, err)
+	assert.Contains(t, out.Item, "expires_at")
+
+	_, err = store.Get(ctx, "missing")
+	assert.Error(t, err)
+
+	// Expired items are missing until DynamoDB deletes them.
+	store.now = func() time.Time { return time.Now().Add(-2 * time.Hour) }
+	expired := domain.Utterance{Utterance: "expired"}
+	require.NoError(t, expired.SetEmbedding([]float64{1.0}))
+	require.NoError(t, store.Store(ctx, expired))
+	store.now = time.Now
+	_, err = store.Get(ctx, "expired")
+	assert.ErrorContains(t, err, "key does not exist")
+}
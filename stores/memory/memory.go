@@ -1,20 +1,51 @@
 package memory
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
 
 	"github.com/conneroisu/go-semantic-router/domain"
 )
 
 // Store is a simple key-value store for embeddings.
 type Store struct {
-	store map[string][]float64
+	mu      sync.RWMutex
+	store   map[string][]float64
+	sparse  map[string]domain.SparseEmbedding
+	multi   map[string]domain.MultiVectorEmbedding
+	meta    map[string][]byte
+	expires map[string]time.Time // expires are the expiry times of the dense embeddings set with SetWithTTL.
+
+	sweepInterval time.Duration // sweepInterval is the interval between sweeps of expired embeddings.
+	sweepOnce     sync.Once
+	stop          chan struct{}
+	closeOnce     sync.Once
 }
 
+// Option is a function that configures a Store.
+type Option func(*Store)
+
 // NewStore creates a new Store from a redis client.
-func NewStore() *Store {
-	return &Store{store: make(map[string][]float64)}
+func NewStore(opts ...Option) *Store {
+	s := &Store{
+		store:         make(map[string][]float64),
+		sparse:        make(map[string]domain.SparseEmbedding),
+		multi:         make(map[string]domain.MultiVectorEmbedding),
+		meta:          make(map[string][]byte),
+		expires:       make(map[string]time.Time),
+		sweepInterval: DefaultSweepInterval,
+		stop:          make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 // Get gets a value from the
@@ -22,9 +53,11 @@ func (s *Store) Get(
 	_ context.Context,
 	utterance string,
 ) (embedding []float64, err error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 	embedding, ok := s.store[utterance]
-	if !ok {
-		return nil, fmt.Errorf("key does not exist: %w", err)
+	if !ok || s.expired(utterance, time.Now()) {
+		return nil, fmt.Errorf("key does not exist: %s", utterance)
 	}
 	return embedding, nil
 }
@@ -34,10 +67,178 @@ func (s *Store) Store(
 	_ context.Context,
 	utterance domain.Utterance,
 ) error {
-	var err error
-	s.store[utterance.Utterance], err = utterance.Embedding()
+	embedding, err := utterance.Embedding()
 	if err != nil {
 		return fmt.Errorf("error getting embedding: %w", err)
 	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.store[utterance.Utterance] = embedding
+	delete(s.expires, utterance.Utterance)
+	return nil
+}
+
+// GetSparse gets a sparse embedding from the store.
+func (s *Store) GetSparse(
+	_ context.Context,
+	utterance string,
+) (domain.SparseEmbedding, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	embedding, ok := s.sparse[utterance]
+	if !ok {
+		return domain.SparseEmbedding{}, fmt.Errorf("key does not exist: %s", utterance)
+	}
+	return embedding, nil
+}
+
+// StoreSparse sets a sparse embedding in the store.
+func (s *Store) StoreSparse(
+	_ context.Context,
+	utterance domain.SparseUtterance,
+) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sparse[utterance.Utterance] = utterance.Embedding
+	return nil
+}
+
+// GetMulti gets a multi-vector embedding from the store.
+func (s *Store) GetMulti(
+	_ context.Context,
+	utterance string,
+) (domain.MultiVectorEmbedding, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	embedding, ok := s.multi[utterance]
+	if !ok {
+		return domain.MultiVectorEmbedding{}, fmt.Errorf("key does not exist: %s", utterance)
+	}
+	return embedding, nil
+}
+
+// StoreMulti sets a multi-vector embedding in the store.
+func (s *Store) StoreMulti(
+	_ context.Context,
+	utterance domain.MultiVectorUtterance,
+) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.multi[utterance.Utterance] = utterance.Embedding
+	return nil
+}
+
+// GetMetadata gets a metadata record from the store.
+func (s *Store) GetMetadata(
+	_ context.Context,
+	key string,
+) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	value, ok := s.meta[key]
+	if !ok {
+		return nil, fmt.Errorf("key does not exist: %s", key)
+	}
+	return bytes.Clone(value), nil
+}
+
+// StoreMetadata sets a metadata record in the store.
+func (s *Store) StoreMetadata(
+	_ context.Context,
+	key string,
+	value []byte,
+) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.meta[key] = bytes.Clone(value)
+	return nil
+}
+
+// Delete removes the dense, sparse and multi-vector embeddings of the
+// utterance from the store.
+func (s *Store) Delete(
+	_ context.Context,
+	utterance string,
+) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.store, utterance)
+	delete(s.expires, utterance)
+	delete(s.sparse, utterance)
+	delete(s.multi, utterance)
+	return nil
+}
+
+// Keys returns the sorted utterances having a dense, sparse or multi-vector
+// embedding in the store.
+func (s *Store) Keys(_ context.Context) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	seen := make(map[string]bool, len(s.store))
+	keys := make([]string, 0, len(s.store))
+	add := func(utterance string) {
+		if !seen[utterance] {
+			seen[utterance] = true
+			keys = append(keys, utterance)
+		}
+	}
+	now := time.Now()
+	for utterance := range s.store {
+		if !s.expired(utterance, now) {
+			add(utterance)
+		}
+	}
+	for utterance := range s.sparse {
+		add(utterance)
+	}
+	for utterance := range s.multi {
+		add(utterance)
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// DumpJSON writes the dense embeddings of the store to w as a JSON object
+// mapping each utterance to its embedding.
+//
+// Utterances are sorted, so dumps of identical stores are identical, which
+// makes them suitable for diffing indexes between runs. Expired embeddings
+// are left out.
+func (s *Store) DumpJSON(w io.Writer) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	embeddings := s.store
+	if len(s.expires) > 0 {
+		now := time.Now()
+		embeddings = make(map[string][]float64, len(s.store))
+		for utterance, embedding := range s.store {
+			if !s.expired(utterance, now) {
+				embeddings[utterance] = embedding
+			}
+		}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	err := enc.Encode(embeddings)
+	if err != nil {
+		return fmt.Errorf("error encoding embeddings: %w", err)
+	}
+	return nil
+}
+
+// LoadJSON reads dense embeddings written by DumpJSON from r into the store,
+// replacing the embeddings of utterances already in the store.
+func (s *Store) LoadJSON(r io.Reader) error {
+	var embeddings map[string][]float64
+	err := json.NewDecoder(r).Decode(&embeddings)
+	if err != nil {
+		return fmt.Errorf("error decoding embeddings: %w", err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for utterance, embedding := range embeddings {
+		s.store[utterance] = embedding
+		delete(s.expires, utterance)
+	}
 	return nil
 }
@@ -1,33 +1,132 @@
 package memory
 
 import (
+	"bytes"
 	"context"
 	"testing"
 
+	semanticrouter "github.com/conneroisu/go-semantic-router"
 	"github.com/conneroisu/go-semantic-router/domain"
+	"github.com/conneroisu/go-semantic-router/stores/storetest"
 	"github.com/stretchr/testify/assert"
 )
 
-func TestStore(t *testing.T) {
+// TestSuite runs the store test suite against the memory store.
+func TestSuite(t *testing.T) {
+	storetest.RunSuite(t, func() semanticrouter.Store {
+		return NewStore()
+	})
+}
+
+func TestStoreSparse(t *testing.T) {
 	ctx := context.Background()
 	store := NewStore()
-	utter := domain.Utterance{
+	err := store.StoreSparse(ctx, domain.SparseUtterance{
 		Utterance: "key",
+		Embedding: domain.SparseEmbedding{
+			Indices: []int{1, 4},
+			Values:  []float64{0.5, 2.0},
+		},
+	})
+	assert.NoError(t, err)
+
+	embedding, err := store.GetSparse(ctx, "key")
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 4}, embedding.Indices)
+	assert.Equal(t, []float64{0.5, 2.0}, embedding.Values)
+
+	_, err = store.GetSparse(ctx, "missing")
+	assert.Error(t, err)
+}
+
+func TestStoreMulti(t *testing.T) {
+	ctx := context.Background()
+	store := NewStore()
+	err := store.StoreMulti(ctx, domain.MultiVectorUtterance{
+		Utterance: "key",
+		Embedding: domain.MultiVectorEmbedding{
+			Vectors: [][]float64{{1, 0}, {0, 1}},
+			Weights: []float64{0.5, 2.0},
+		},
+	})
+	assert.NoError(t, err)
+
+	embedding, err := store.GetMulti(ctx, "key")
+	assert.NoError(t, err)
+	assert.Equal(t, [][]float64{{1, 0}, {0, 1}}, embedding.Vectors)
+	assert.Equal(t, []float64{0.5, 2.0}, embedding.Weights)
+
+	_, err = store.GetMulti(ctx, "missing")
+	assert.Error(t, err)
+}
+
+func TestStoreJSON(t *testing.T) {
+	ctx := context.Background()
+	store := NewStore()
+	for utterance, embedding := range map[string][]float64{
+		"b": {0.25, -1.5},
+		"a": {1.0, 2.0},
+	} {
+		utter := domain.Utterance{Utterance: utterance}
+		assert.NoError(t, utter.SetEmbedding(embedding))
+		assert.NoError(t, store.Store(ctx, utter))
 	}
-	utter.SetEmbedding([]float64{1.0, 2.0, 3.0, 4.0, 5.0})
-	err := store.Store(
-		ctx,
-		utter,
-	)
+
+	var buf bytes.Buffer
+	assert.NoError(t, store.DumpJSON(&buf))
+	assert.JSONEq(t, `{"a": [1, 2], "b": [0.25, -1.5]}`, buf.String())
+
+	loaded := NewStore()
+	assert.NoError(t, loaded.LoadJSON(bytes.NewReader(buf.Bytes())))
+	embedding, err := loaded.Get(ctx, "b")
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{0.25, -1.5}, embedding)
+
+	var again bytes.Buffer
+	assert.NoError(t, loaded.DumpJSON(&again))
+	assert.Equal(t, buf.String(), again.String())
+
+	assert.Error(t, loaded.LoadJSON(bytes.NewBufferString("not json")))
+}
+
+func TestStoreKeys(t *testing.T) {
+	ctx := context.Background()
+	store := NewStore()
+	keys, err := store.Keys(ctx)
 	assert.NoError(t, err)
+	assert.Empty(t, keys)
+
+	utter := domain.Utterance{Utterance: "dense"}
+	utter.SetEmbedding([]float64{1.0, 2.0})
+	assert.NoError(t, store.Store(ctx, utter))
+	assert.NoError(t, store.StoreSparse(ctx, domain.SparseUtterance{Utterance: "sparse"}))
+	assert.NoError(t, store.StoreSparse(ctx, domain.SparseUtterance{Utterance: "dense"}))
+	assert.NoError(t, store.StoreMulti(ctx, domain.MultiVectorUtterance{Utterance: "multi"}))
 
-	floats, err := store.Get(ctx, "key")
+	keys, err = store.Keys(ctx)
 	assert.NoError(t, err)
-	assert.NotNil(t, floats)
+	assert.Equal(t, []string{"dense", "multi", "sparse"}, keys)
 
-	assert.Equal(
-		t,
-		[]float64{1.0, 2.0, 3.0, 4.0, 5.0},
-		floats,
-	)
+	assert.NoError(t, store.Delete(ctx, "dense"))
+	keys, err = store.Keys(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"multi", "sparse"}, keys)
+}
+
+func TestStoreMetadata(t *testing.T) {
+	ctx := context.Background()
+	store := NewStore()
+	_, err := store.GetMetadata(ctx, "key")
+	assert.Error(t, err)
+
+	value := []byte(`{"version":1}`)
+	assert.NoError(t, store.StoreMetadata(ctx, "key", value))
+	value[0] = 'x'
+	got, err := store.GetMetadata(ctx, "key")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte(`{"version":1}`), got)
+
+	keys, err := store.Keys(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, keys)
 }
@@ -0,0 +1,95 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/conneroisu/go-semantic-router/domain"
+)
+
+// DefaultSweepInterval is the default interval between the sweeps removing
+// expired embeddings from a Store, see WithSweepInterval.
+const DefaultSweepInterval = time.Minute
+
+// WithSweepInterval sets the interval between the sweeps removing expired
+// embeddings from the store. The default is DefaultSweepInterval.
+//
+// Expired embeddings are never returned, even before they are swept.
+func WithSweepInterval(interval time.Duration) Option {
+	return func(s *Store) {
+		if interval > 0 {
+			s.sweepInterval = interval
+		}
+	}
+}
+
+// SetWithTTL stores the dense embedding of the utterance, expiring it after
+// the given ttl. A ttl of zero or less stores it without expiry, as Store.
+//
+// The first call starts a background goroutine sweeping expired embeddings
+// out of the store, stopped by Close.
+func (s *Store) SetWithTTL(
+	_ context.Context,
+	utterance domain.Utterance,
+	ttl time.Duration,
+) error {
+	embedding, err := utterance.Embedding()
+	if err != nil {
+		return fmt.Errorf("error getting embedding: %w", err)
+	}
+	s.mu.Lock()
+	s.store[utterance.Utterance] = embedding
+	if ttl > 0 {
+		s.expires[utterance.Utterance] = time.Now().Add(ttl)
+	} else {
+		delete(s.expires, utterance.Utterance)
+	}
+	s.mu.Unlock()
+	if ttl > 0 {
+		s.sweepOnce.Do(func() { go s.sweepLoop() })
+	}
+	return nil
+}
+
+// Close stops the background sweeping of expired embeddings, if started.
+//
+// The store remains usable; expired embeddings are still never returned.
+func (s *Store) Close() error {
+	s.closeOnce.Do(func() { close(s.stop) })
+	return nil
+}
+
+// expired reports whether the dense embedding of the utterance has expired
+// at the given time. The store must be locked.
+func (s *Store) expired(utterance string, now time.Time) bool {
+	expires, ok := s.expires[utterance]
+	return ok && !now.Before(expires)
+}
+
+// sweepLoop sweeps expired embeddings at each sweep interval until the store
+// is closed.
+func (s *Store) sweepLoop() {
+	ticker := time.NewTicker(s.sweepInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case now := <-ticker.C:
+			s.sweep(now)
+		}
+	}
+}
+
+// sweep removes the dense embeddings expired at the given time.
+func (s *Store) sweep(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for utterance := range s.expires {
+		if s.expired(utterance, now) {
+			delete(s.store, utterance)
+			delete(s.expires, utterance)
+		}
+	}
+}
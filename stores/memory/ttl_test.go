@@ -0,0 +1,64 @@
+package memory
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/conneroisu/go-semantic-router/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreSetWithTTL(t *testing.T) {
+	ctx := context.Background()
+	store := NewStore(WithSweepInterval(5 * time.Millisecond))
+	t.Cleanup(func() { _ = store.Close() })
+	expiring := domain.Utterance{Utterance: "expiring"}
+	require.NoError(t, expiring.SetEmbedding([]float64{1.0, 2.0}))
+	require.NoError(t, store.SetWithTTL(ctx, expiring, 20*time.Millisecond))
+	kept := domain.Utterance{Utterance: "kept"}
+	require.NoError(t, kept.SetEmbedding([]float64{3.0}))
+	require.NoError(t, store.SetWithTTL(ctx, kept, 20*time.Millisecond))
+	// Storing again without a TTL clears the expiry.
+	require.NoError(t, store.Store(ctx, kept))
+
+	embedding, err := store.Get(ctx, "expiring")
+	require.NoError(t, err)
+	assert.Equal(t, []float64{1.0, 2.0}, embedding)
+
+	assert.Eventually(t, func() bool {
+		store.mu.RLock()
+		defer store.mu.RUnlock()
+		_, ok := store.store["expiring"]
+		return !ok
+	}, time.Second, 5*time.Millisecond, "expired embedding was not swept")
+	_, err = store.Get(ctx, "expiring")
+	assert.Error(t, err)
+	keys, err := store.Keys(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"kept"}, keys)
+	embedding, err = store.Get(ctx, "kept")
+	require.NoError(t, err)
+	assert.Equal(t, []float64{3.0}, embedding)
+}
+
+func TestStoreExpiredBeforeSweep(t *testing.T) {
+	ctx := context.Background()
+	store := NewStore()
+	t.Cleanup(func() { _ = store.Close() })
+	utter := domain.Utterance{Utterance: "key"}
+	require.NoError(t, utter.SetEmbedding([]float64{1.0}))
+	require.NoError(t, store.SetWithTTL(ctx, utter, time.Millisecond))
+	time.Sleep(5 * time.Millisecond)
+
+	_, err := store.Get(ctx, "key")
+	assert.Error(t, err)
+	keys, err := store.Keys(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, keys)
+	var buf bytes.Buffer
+	require.NoError(t, store.DumpJSON(&buf))
+	assert.JSONEq(t, `{}`, buf.String())
+}
@@ -0,0 +1,203 @@
+// Package mongo provides a key-value store for embeddings backed by MongoDB,
+// with optional support for Atlas Vector Search.
+package mongo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/conneroisu/go-semantic-router/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"gonum.org/v1/gonum/floats"
+)
+
+// document is a document of the store's collection.
+type document struct {
+	Utterance string    `bson:"utterance"`
+	Embedding []float64 `bson:"embedding"`
+}
+
+// Store is a key-value store for embeddings backed by a MongoDB collection
+// holding {utterance, embedding} documents.
+type Store struct {
+	collection    *mongo.Collection
+	vectorIndex   string
+	numCandidates int
+}
+
+// Option is a function that configures a Store.
+type Option func(*Store)
+
+// WithVectorSearchIndex makes Search use the Atlas Vector Search index with
+// the given name, defined on the embedding field of the collection.
+//
+// numCandidates is the number of nearest neighbors considered per result,
+// see the $vectorSearch documentation; a non-positive value defaults to ten
+// times the number of requested results.
+func WithVectorSearchIndex(name string, numCandidates int) Option {
+	return func(s *Store) {
+		s.vectorIndex = name
+		s.numCandidates = numCandidates
+	}
+}
+
+// NewStore creates a new Store from a MongoDB collection.
+func NewStore(collection *mongo.Collection, opts ...Option) *Store {
+	s := &Store{collection: collection}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Get gets a value from the store.
+func (s *Store) Get(
+	ctx context.Context,
+	utterance string,
+) (embedding []float64, err error) {
+	var doc document
+	err = s.collection.FindOne(ctx, bson.M{"utterance": utterance}).Decode(&doc)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, fmt.Errorf("key does not exist: %w", err)
+		}
+		return nil, fmt.Errorf("error finding document: %w", err)
+	}
+	return doc.Embedding, nil
+}
+
+// Store sets a value in the store.
+func (s *Store) Store(
+	ctx context.Context,
+	utterance domain.Utterance,
+) error {
+	embedding, err := utterance.Embedding()
+	if err != nil {
+		return fmt.Errorf("error getting embedding: %w", err)
+	}
+	_, err = s.collection.ReplaceOne(
+		ctx,
+		bson.M{"utterance": utterance.Utterance},
+		document{Utterance: utterance.Utterance, Embedding: embedding},
+		options.Replace().SetUpsert(true),
+	)
+	if err != nil {
+		return fmt.Errorf("error storing document: %w", err)
+	}
+	return nil
+}
+
+// Search returns the k utterances whose embeddings are the most similar to
+// the query embedding, ordered by descending score.
+//
+// If a vector search index is configured, the search runs on the cluster
+// with $vectorSearch and the scores are the ones computed by Atlas.
+// Otherwise, every document is scanned client-side and scored by cosine
+// similarity, skipping the documents whose embedding is a zero vector.
+//
+// k must be positive.
+func (s *Store) Search(
+	ctx context.Context,
+	query []float64,
+	k int,
+) ([]domain.ScoredUtterance, error) {
+	if k <= 0 {
+		return nil, fmt.Errorf("search result count %d is not positive", k)
+	}
+	if s.vectorIndex != "" {
+		return s.vectorSearch(ctx, query, k)
+	}
+	return s.scan(ctx, query, k)
+}
+
+// vectorSearch searches the collection using Atlas Vector Search.
+func (s *Store) vectorSearch(
+	ctx context.Context,
+	query []float64,
+	k int,
+) ([]domain.ScoredUtterance, error) {
+	numCandidates := s.numCandidates
+	if numCandidates <= 0 {
+		numCandidates = 10 * k
+	}
+	cursor, err := s.collection.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$vectorSearch", Value: bson.M{
+			"index":         s.vectorIndex,
+			"path":          "embedding",
+			"queryVector":   query,
+			"numCandidates": numCandidates,
+			"limit":         k,
+		}}},
+		{{Key: "$project", Value: bson.M{
+			"utterance": 1,
+			"score":     bson.M{"$meta": "vectorSearchScore"},
+		}}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error running vector search: %w", err)
+	}
+	var results []struct {
+		Utterance string  `bson:"utterance"`
+		Score     float64 `bson:"score"`
+	}
+	err = cursor.All(ctx, &results)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding vector search results: %w", err)
+	}
+	scored := make([]domain.ScoredUtterance, len(results))
+	for i, res := range results {
+		scored[i] = domain.ScoredUtterance{
+			Utterance: res.Utterance,
+			Score:     res.Score,
+		}
+	}
+	return scored, nil
+}
+
+// scan searches the collection by scoring every document client-side.
+func (s *Store) scan(
+	ctx context.Context,
+	query []float64,
+	k int,
+) ([]domain.ScoredUtterance, error) {
+	cursor, err := s.collection.Find(ctx, bson.M{})
+	if err != nil {
+		return nil, fmt.Errorf("error finding documents: %w", err)
+	}
+	defer cursor.Close(ctx)
+	queryNorm := floats.Norm(query, 2)
+	var scored []domain.ScoredUtterance
+	for cursor.Next(ctx) {
+		var doc document
+		err = cursor.Decode(&doc)
+		if err != nil {
+			return nil, fmt.Errorf("error decoding document: %w", err)
+		}
+		if len(doc.Embedding) != len(query) {
+			continue
+		}
+		// The cosine similarity of zero vectors is undefined.
+		docNorm := floats.Norm(doc.Embedding, 2)
+		if queryNorm == 0 || docNorm == 0 {
+			continue
+		}
+		scored = append(scored, domain.ScoredUtterance{
+			Utterance: doc.Utterance,
+			Score:     floats.Dot(query, doc.Embedding) / (queryNorm * docNorm),
+		})
+	}
+	if err = cursor.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating documents: %w", err)
+	}
+	sort.SliceStable(scored, func(i, j int) bool {
+		return scored[i].Score > scored[j].Score
+	})
+	if len(scored) > k {
+		scored = scored[:k]
+	}
+	return scored, nil
+}
@@ -0,0 +1,99 @@
+//go:build integration
+
+package mongo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/conneroisu/go-semantic-router/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// newCollection starts a MongoDB container and returns a collection of it.
+func newCollection(t *testing.T) *mongo.Collection {
+	t.Helper()
+	ctx := context.Background()
+	container, err := testcontainers.GenericContainer(
+		ctx,
+		testcontainers.GenericContainerRequest{
+			ContainerRequest: testcontainers.ContainerRequest{
+				Image:        "mongo:7",
+				ExposedPorts: []string{"27017/tcp"},
+				WaitingFor:   wait.ForLog("Waiting for connections"),
+			},
+			Started: true,
+		})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = container.Terminate(ctx) })
+	endpoint, err := container.PortEndpoint(ctx, "27017/tcp", "mongodb")
+	require.NoError(t, err)
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(endpoint))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = client.Disconnect(ctx) })
+	return client.Database("router").Collection("utterances")
+}
+
+// TestStore is a test for the mongo store.
+func TestStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewStore(newCollection(t))
+	utter := domain.Utterance{Utterance: "key"}
+	require.NoError(t, utter.SetEmbedding([]float64{1.0, 2.0, 3.0, 4.0, 5.0}))
+	require.NoError(t, store.Store(ctx, utter))
+
+	floats, err := store.Get(ctx, "key")
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{1.0, 2.0, 3.0, 4.0, 5.0}, floats)
+
+	_, err = store.Get(ctx, "missing")
+	assert.ErrorIs(t, err, mongo.ErrNoDocuments)
+}
+
+// TestStoreSearch tests the client-side scan fallback of Search.
+func TestStoreSearch(t *testing.T) {
+	ctx := context.Background()
+	store := NewStore(newCollection(t))
+	for name, embedding := range map[string][]float64{
+		"a":    {1.0, 0.0},
+		"b":    {0.8, 0.2},
+		"c":    {0.0, 1.0},
+		"zero": {0.0, 0.0},
+	} {
+		utter := domain.Utterance{Utterance: name}
+		require.NoError(t, utter.SetEmbedding(embedding))
+		require.NoError(t, store.Store(ctx, utter))
+	}
+
+	results, err := store.Search(ctx, []float64{1.0, 0.1}, 2)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, "a", results[0].Utterance)
+	assert.Equal(t, "b", results[1].Utterance)
+	assert.Greater(t, results[0].Score, results[1].Score)
+
+	// Zero vectors have no cosine similarity.
+	results, err = store.Search(ctx, []float64{1.0, 0.1}, 4)
+	require.NoError(t, err)
+	assert.Len(t, results, 3)
+	results, err = store.Search(ctx, []float64{0.0, 0.0}, 4)
+	require.NoError(t, err)
+	assert.Empty(t, results)
+}
+
+// TestStoreSearchCount tests that searches for no results are rejected
+// before reaching the collection.
+func TestStoreSearchCount(t *testing.T) {
+	ctx := context.Background()
+	for _, k := range []int{0, -1} {
+		_, err := NewStore(nil).Search(ctx, []float64{1.0, 0.1}, k)
+		assert.Error(t, err)
+		_, err = NewStore(nil, WithVectorSearchIndex("vector_index", 0)).Search(ctx, []float64{1.0, 0.1}, k)
+		assert.Error(t, err)
+	}
+}
@@ -0,0 +1,10 @@
+// Package precision provides a store decorator rounding embeddings to a
+// fixed number of significant digits before storing them.
+//
+// The decorator forwards semanticrouter.Deleter to the underlying store, but
+// none of the other optional store interfaces: wrapping a store hides its
+// semanticrouter.Enumerator, so that PruneStore fails with ErrNotSupported,
+// its semanticrouter.MetadataStore, so that IndexInfo is not recorded, and
+// its semanticrouter.TTLStore, so that WithQueryCacheTTL does not persist
+// query embeddings. Sparse and multi-vector embeddings are not supported.
+package precision
@@ -0,0 +1,108 @@
+package precision
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	semanticrouter "github.com/conneroisu/go-semantic-router"
+	"github.com/conneroisu/go-semantic-router/domain"
+)
+
+// Store is a store rounding embeddings to a fixed number of significant
+// digits before storing them in an underlying store.
+//
+// Encoders may return slightly different floats for the same utterance
+// across runs; rounding makes the stored index reproducible, for instance
+// for golden tests. Rounding each component to n significant digits changes
+// it by a relative error of at most 5e-n, so similarity scores change by
+// roughly the same order of magnitude: with 6 digits, a cosine similarity
+// moves by no more than about 1e-5.
+type Store struct {
+	store  semanticrouter.Store
+	digits int
+}
+
+// deleterStore is a Store whose underlying store implements
+// semanticrouter.Deleter.
+//
+// The Store is not embedded, as its Store method would be shadowed by the
+// name of the embedded field.
+type deleterStore struct {
+	rounding *Store
+}
+
+// NewStore creates a new Store rounding embeddings to the given number of
+// significant digits before storing them in the given store.
+//
+// The returned store implements semanticrouter.Deleter if the given store
+// does; see the package documentation for the other optional interfaces.
+func NewStore(store semanticrouter.Store, digits int) semanticrouter.Store {
+	s := &Store{store: store, digits: digits}
+	if _, ok := store.(semanticrouter.Deleter); ok {
+		return deleterStore{s}
+	}
+	return s
+}
+
+// Get gets an embedding from the underlying store.
+func (s deleterStore) Get(
+	ctx context.Context,
+	utterance string,
+) ([]float64, error) {
+	return s.rounding.Get(ctx, utterance)
+}
+
+// Store rounds the embedding of the given utterance and stores it in the
+// underlying store.
+func (s deleterStore) Store(
+	ctx context.Context,
+	utterance domain.Utterance,
+) error {
+	return s.rounding.Store(ctx, utterance)
+}
+
+// Delete deletes an embedding from the underlying store.
+func (s deleterStore) Delete(ctx context.Context, utterance string) error {
+	return s.rounding.store.(semanticrouter.Deleter).Delete(ctx, utterance)
+}
+
+// Get gets an embedding from the underlying store.
+func (s *Store) Get(
+	ctx context.Context,
+	utterance string,
+) ([]float64, error) {
+	return s.store.Get(ctx, utterance)
+}
+
+// Store rounds the embedding of the given utterance and stores it in the
+// underlying store.
+func (s *Store) Store(
+	ctx context.Context,
+	utterance domain.Utterance,
+) error {
+	embedding, err := utterance.Embedding()
+	if err != nil {
+		return fmt.Errorf("error getting embedding: %w", err)
+	}
+	err = utterance.SetEmbedding(Round(embedding, s.digits))
+	if err != nil {
+		return fmt.Errorf("error setting embedding: %w", err)
+	}
+	return s.store.Store(ctx, utterance)
+}
+
+// Round returns a copy of the given embedding with each component rounded
+// to the given number of significant digits.
+func Round(embedding []float64, digits int) []float64 {
+	rounded := make([]float64, len(embedding))
+	for i, v := range embedding {
+		// Formatting and parsing back gives the float closest to the
+		// decimal representation, so equal decimals give equal floats.
+		rounded[i], _ = strconv.ParseFloat(
+			strconv.FormatFloat(v, 'g', digits, 64),
+			64,
+		)
+	}
+	return rounded
+}
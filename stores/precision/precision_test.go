@@ -0,0 +1,71 @@
+package precision
+
+import (
+	"context"
+	"testing"
+
+	semanticrouter "github.com/conneroisu/go-semantic-router"
+	"github.com/conneroisu/go-semantic-router/domain"
+	"github.com/conneroisu/go-semantic-router/stores/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestStore tests that embeddings are rounded before they are stored, so
+// embeddings equal to the given significant digits are stored equal.
+func TestStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewStore(memory.NewStore(), 6)
+	a := domain.Utterance{Utterance: "a"}
+	require.NoError(t, a.SetEmbedding([]float64{0.12345678, -3.14159265, 0, 1e-9}))
+	b := domain.Utterance{Utterance: "b"}
+	require.NoError(t, b.SetEmbedding([]float64{0.12345681, -3.14159249, 0, 1.0000001e-9}))
+	require.NoError(t, store.Store(ctx, a))
+	require.NoError(t, store.Store(ctx, b))
+
+	embA, err := store.Get(ctx, "a")
+	require.NoError(t, err)
+	embB, err := store.Get(ctx, "b")
+	require.NoError(t, err)
+	assert.Equal(t, []float64{0.123457, -3.14159, 0, 1e-9}, embA)
+	assert.Equal(t, embA, embB)
+}
+
+// plainStore is a store only exposing the Get and Store methods of its
+// underlying store.
+type plainStore struct {
+	store semanticrouter.Store
+}
+
+// Get gets an embedding from the underlying store.
+func (s plainStore) Get(ctx context.Context, utterance string) ([]float64, error) {
+	return s.store.Get(ctx, utterance)
+}
+
+// Store stores an embedding in the underlying store.
+func (s plainStore) Store(ctx context.Context, utterance domain.Utterance) error {
+	return s.store.Store(ctx, utterance)
+}
+
+// TestStoreDeleter tests that the store is a Deleter only if its underlying
+// store is one.
+func TestStoreDeleter(t *testing.T) {
+	ctx := context.Background()
+	store := NewStore(memory.NewStore(), 6)
+	deleter, ok := store.(semanticrouter.Deleter)
+	require.True(t, ok)
+	a := domain.Utterance{Utterance: "a"}
+	require.NoError(t, a.SetEmbedding([]float64{0.12345678}))
+	require.NoError(t, store.Store(ctx, a))
+	require.NoError(t, deleter.Delete(ctx, "a"))
+	_, err := store.Get(ctx, "a")
+	assert.Error(t, err)
+
+	_, ok = NewStore(plainStore{memory.NewStore()}, 6).(semanticrouter.Deleter)
+	assert.False(t, ok)
+}
+
+// TestRound tests that Round keeps the given number of significant digits.
+func TestRound(t *testing.T) {
+	assert.Equal(t, []float64{1.23, 12300, -0.000123}, Round([]float64{1.2345, 12345, -0.00012345}, 3))
+}
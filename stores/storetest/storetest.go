@@ -0,0 +1,263 @@
+// Package storetest provides a suite of tests checking that a store
+// implements the behavioral contract of semanticrouter.Store, so that every
+// store, including third-party ones, behaves the same under a router.
+//
+// The suite is run from the tests of a store package:
+//
+//	func TestSuite(t *testing.T) {
+//		storetest.RunSuite(t, func() semanticrouter.Store {
+//			return memory.NewStore()
+//		})
+//	}
+//
+// The optional capabilities of the store, such as semanticrouter.Deleter or
+// semanticrouter.Enumerator, are tested when it implements them, and their
+// tests are skipped otherwise.
+package storetest
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	semanticrouter "github.com/conneroisu/go-semantic-router"
+	"github.com/conneroisu/go-semantic-router/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// concurrency is the number of goroutines of the concurrency test.
+const concurrency = 8
+
+// RunSuite runs the suite of store tests against stores returned by
+// newStore, which is called once per test and must return an empty store.
+//
+// The embeddings used by the suite have components exactly representable in
+// half precision, so that stores with lossy encodings pass it.
+func RunSuite(t *testing.T, newStore func() semanticrouter.Store) {
+	t.Helper()
+	tests := []struct {
+		name string
+		test func(t *testing.T, store semanticrouter.Store)
+	}{
+		{"RoundTrip", testRoundTrip},
+		{"Overwrite", testOverwrite},
+		{"MissingKey", testMissingKey},
+		{"Concurrency", testConcurrency},
+		{"Delete", testDelete},
+		{"Keys", testKeys},
+		{"Metadata", testMetadata},
+		{"Sparse", testSparse},
+		{"MultiVector", testMultiVector},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.test(t, newStore())
+		})
+	}
+}
+
+// store stores the given embedding of the given utterance.
+func store(
+	t *testing.T,
+	s semanticrouter.Store,
+	utterance string,
+	embedding []float64,
+) {
+	t.Helper()
+	utter := domain.Utterance{Utterance: utterance}
+	require.NoError(t, utter.SetEmbedding(embedding))
+	require.NoError(t, s.Store(context.Background(), utter))
+}
+
+// testRoundTrip tests that a stored embedding is returned by Get.
+func testRoundTrip(t *testing.T, s semanticrouter.Store) {
+	store(t, s, "hello world", []float64{0.5, -1.25, 2, 0})
+	store(t, s, "goodbye", []float64{1, 0, 0, 0})
+
+	embedding, err := s.Get(context.Background(), "hello world")
+	require.NoError(t, err)
+	assert.Equal(t, []float64{0.5, -1.25, 2, 0}, embedding)
+	embedding, err = s.Get(context.Background(), "goodbye")
+	require.NoError(t, err)
+	assert.Equal(t, []float64{1, 0, 0, 0}, embedding)
+}
+
+// testOverwrite tests that storing an utterance again replaces its
+// embedding.
+func testOverwrite(t *testing.T, s semanticrouter.Store) {
+	store(t, s, "hello world", []float64{1, 2, 3})
+	store(t, s, "hello world", []float64{4, 5})
+
+	embedding, err := s.Get(context.Background(), "hello world")
+	require.NoError(t, err)
+	assert.Equal(t, []float64{4, 5}, embedding)
+}
+
+// testMissingKey tests that getting an utterance that was never stored is
+// an error.
+func testMissingKey(t *testing.T, s semanticrouter.Store) {
+	embedding, err := s.Get(context.Background(), "missing")
+	assert.Error(t, err)
+	assert.Empty(t, embedding)
+}
+
+// testConcurrency tests that the store can be used by concurrent
+// goroutines, each storing and getting its own utterances along with an
+// utterance they share.
+func testConcurrency(t *testing.T, s semanticrouter.Store) {
+	ctx := context.Background()
+	var wg sync.WaitGroup
+	for g := range concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 25 {
+				for _, utterance := range []string{
+					fmt.Sprintf("utterance %d-%d", g, i),
+					"shared",
+				} {
+					utter := domain.Utterance{Utterance: utterance}
+					if !assert.NoError(t, utter.SetEmbedding([]float64{float64(g), float64(i)})) {
+						return
+					}
+					if !assert.NoError(t, s.Store(ctx, utter)) {
+						return
+					}
+				}
+				embedding, err := s.Get(ctx, fmt.Sprintf("utterance %d-%d", g, i))
+				if !assert.NoError(t, err) {
+					return
+				}
+				assert.Equal(t, []float64{float64(g), float64(i)}, embedding)
+				embedding, err = s.Get(ctx, "shared")
+				if assert.NoError(t, err) {
+					assert.Len(t, embedding, 2)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// testDelete tests that a deleted utterance is missing from the store,
+// without affecting the other utterances, and that deleting a missing
+// utterance is not an error.
+func testDelete(t *testing.T, s semanticrouter.Store) {
+	deleter, ok := s.(semanticrouter.Deleter)
+	if !ok {
+		t.Skipf("store %T does not implement Deleter", s)
+	}
+	ctx := context.Background()
+	store(t, s, "deleted", []float64{1, 2})
+	store(t, s, "kept", []float64{3, 4})
+
+	require.NoError(t, deleter.Delete(ctx, "deleted"))
+	_, err := s.Get(ctx, "deleted")
+	assert.Error(t, err)
+	embedding, err := s.Get(ctx, "kept")
+	require.NoError(t, err)
+	assert.Equal(t, []float64{3, 4}, embedding)
+
+	assert.NoError(t, deleter.Delete(ctx, "deleted"))
+	assert.NoError(t, deleter.Delete(ctx, "missing"))
+}
+
+// testKeys tests that the store enumerates the utterances it has
+// embeddings for.
+func testKeys(t *testing.T, s semanticrouter.Store) {
+	enumerator, ok := s.(semanticrouter.Enumerator)
+	if !ok {
+		t.Skipf("store %T does not implement Enumerator", s)
+	}
+	ctx := context.Background()
+	keys, err := enumerator.Keys(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, keys)
+
+	store(t, s, "hello world", []float64{1, 2})
+	store(t, s, "goodbye", []float64{3, 4})
+	keys, err = enumerator.Keys(ctx)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"hello world", "goodbye"}, keys)
+
+	if deleter, ok := s.(semanticrouter.Deleter); ok {
+		require.NoError(t, deleter.Delete(ctx, "goodbye"))
+		keys, err = enumerator.Keys(ctx)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"hello world"}, keys)
+	}
+}
+
+// testMetadata tests that metadata records round-trip through the store and
+// that getting a missing record is an error.
+func testMetadata(t *testing.T, s semanticrouter.Store) {
+	meta, ok := s.(semanticrouter.MetadataStore)
+	if !ok {
+		t.Skipf("store %T does not implement MetadataStore", s)
+	}
+	ctx := context.Background()
+	_, err := meta.GetMetadata(ctx, "missing")
+	assert.Error(t, err)
+
+	value := []byte(`{"version":1}`)
+	require.NoError(t, meta.StoreMetadata(ctx, "record", value))
+	value[0] = 'x'
+	got, err := meta.GetMetadata(ctx, "record")
+	require.NoError(t, err)
+	assert.Equal(t, []byte(`{"version":1}`), got)
+
+	require.NoError(t, meta.StoreMetadata(ctx, "record", []byte(`{"version":2}`)))
+	got, err = meta.GetMetadata(ctx, "record")
+	require.NoError(t, err)
+	assert.Equal(t, []byte(`{"version":2}`), got)
+}
+
+// testSparse tests that sparse embeddings round-trip through the store and
+// that getting a missing one is an error.
+func testSparse(t *testing.T, s semanticrouter.Store) {
+	sparse, ok := s.(semanticrouter.SparseStore)
+	if !ok {
+		t.Skipf("store %T does not implement SparseStore", s)
+	}
+	ctx := context.Background()
+	_, err := sparse.GetSparse(ctx, "missing")
+	assert.Error(t, err)
+
+	require.NoError(t, sparse.StoreSparse(ctx, domain.SparseUtterance{
+		Utterance: "hello world",
+		Embedding: domain.SparseEmbedding{
+			Indices: []int{1, 4},
+			Values:  []float64{0.5, 2},
+		},
+	}))
+	embedding, err := sparse.GetSparse(ctx, "hello world")
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 4}, embedding.Indices)
+	assert.Equal(t, []float64{0.5, 2}, embedding.Values)
+}
+
+// testMultiVector tests that multi-vector embeddings round-trip through the
+// store and that getting a missing one is an error.
+func testMultiVector(t *testing.T, s semanticrouter.Store) {
+	multi, ok := s.(semanticrouter.MultiVectorStore)
+	if !ok {
+		t.Skipf("store %T does not implement MultiVectorStore", s)
+	}
+	ctx := context.Background()
+	_, err := multi.GetMulti(ctx, "missing")
+	assert.Error(t, err)
+
+	require.NoError(t, multi.StoreMulti(ctx, domain.MultiVectorUtterance{
+		Utterance: "hello world",
+		Embedding: domain.MultiVectorEmbedding{
+			Vectors: [][]float64{{1, 0}, {0, 1}},
+			Weights: []float64{0.5, 2},
+		},
+	}))
+	embedding, err := multi.GetMulti(ctx, "hello world")
+	require.NoError(t, err)
+	assert.Equal(t, [][]float64{{1, 0}, {0, 1}}, embedding.Vectors)
+	assert.Equal(t, []float64{0.5, 2}, embedding.Weights)
+}
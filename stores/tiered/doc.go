@@ -0,0 +1,8 @@
+// Package tiered provides a store chaining several stores, such as a fast
+// in-memory cache in front of a durable store.
+//
+//	store := tiered.NewStore(
+//		memory.NewStore(),
+//		valkey.NewStore(client),
+//	)
+package tiered
@@ -0,0 +1,136 @@
+package tiered
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	semanticrouter "github.com/conneroisu/go-semantic-router"
+	"github.com/conneroisu/go-semantic-router/domain"
+)
+
+// Store is a store chaining an ordered list of tiers, fastest first.
+//
+// Embeddings are read from the first tier having them and written through
+// to every tier, so earlier tiers act as caches of later ones.
+type Store struct {
+	tiers []semanticrouter.Store
+}
+
+// deleterStore is a Store whose tiers all implement semanticrouter.Deleter.
+//
+// The Store is not embedded, as its Store method would be shadowed by the
+// name of the embedded field.
+type deleterStore struct {
+	tiered *Store
+}
+
+// NewStore creates a new Store chaining the given tiers, fastest first.
+//
+// The returned store implements semanticrouter.Deleter if every tier does,
+// since an embedding left in a tier which cannot delete it would be promoted
+// back on the next lookup.
+func NewStore(tiers ...semanticrouter.Store) semanticrouter.Store {
+	s := &Store{tiers: tiers}
+	for _, tier := range tiers {
+		if _, ok := tier.(semanticrouter.Deleter); !ok {
+			return s
+		}
+	}
+	return deleterStore{s}
+}
+
+// Get gets an embedding from the first tier having it, trying the tiers in
+// order.
+//
+// An embedding found in a later tier is promoted to the earlier tiers, so
+// that the next lookups hit the fastest one. Promotion is best effort: a
+// tier failing to store the embedding does not fail the lookup.
+func (s *Store) Get(
+	ctx context.Context,
+	utterance string,
+) ([]float64, error) {
+	var errs []error
+	for i, tier := range s.tiers {
+		embedding, err := tier.Get(ctx, utterance)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("tier %d: %w", i, err))
+			continue
+		}
+		s.promote(ctx, utterance, embedding, s.tiers[:i])
+		return embedding, nil
+	}
+	if len(errs) == 0 {
+		return nil, fmt.Errorf("key does not exist: %s", utterance)
+	}
+	return nil, errors.Join(errs...)
+}
+
+// promote stores the given embedding of the utterance in the given tiers,
+// ignoring their errors.
+func (s *Store) promote(
+	ctx context.Context,
+	utterance string,
+	embedding []float64,
+	tiers []semanticrouter.Store,
+) {
+	utter := domain.Utterance{Utterance: utterance}
+	if utter.SetEmbedding(embedding) != nil {
+		return
+	}
+	for _, tier := range tiers {
+		_ = tier.Store(ctx, utter)
+	}
+}
+
+// Store stores an utterance in every tier, in order.
+//
+// Every tier is written even if an earlier one fails; the errors of the
+// failing tiers are joined.
+func (s *Store) Store(
+	ctx context.Context,
+	utterance domain.Utterance,
+) error {
+	var errs []error
+	for i, tier := range s.tiers {
+		err := tier.Store(ctx, utterance)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("error storing in tier %d: %w", i, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Get gets an embedding from the first tier having it, see Store.Get.
+func (s deleterStore) Get(
+	ctx context.Context,
+	utterance string,
+) ([]float64, error) {
+	return s.tiered.Get(ctx, utterance)
+}
+
+// Store stores an utterance in every tier, see Store.Store.
+func (s deleterStore) Store(
+	ctx context.Context,
+	utterance domain.Utterance,
+) error {
+	return s.tiered.Store(ctx, utterance)
+}
+
+// Delete deletes the embeddings of an utterance from every tier.
+//
+// Every tier is deleted from even if an earlier one fails; the errors of the
+// failing tiers are joined.
+func (s deleterStore) Delete(
+	ctx context.Context,
+	utterance string,
+) error {
+	var errs []error
+	for i, tier := range s.tiered.tiers {
+		err := tier.(semanticrouter.Deleter).Delete(ctx, utterance)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("error deleting from tier %d: %w", i, err))
+		}
+	}
+	return errors.Join(errs...)
+}
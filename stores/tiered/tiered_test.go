@@ -0,0 +1,95 @@
+package tiered
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	semanticrouter "github.com/conneroisu/go-semantic-router"
+	"github.com/conneroisu/go-semantic-router/domain"
+	"github.com/conneroisu/go-semantic-router/stores/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingStore is a store failing every call.
+type failingStore struct{}
+
+func (failingStore) Get(context.Context, string) ([]float64, error) {
+	return nil, errors.New("unavailable")
+}
+
+func (failingStore) Store(context.Context, domain.Utterance) error {
+	return errors.New("unavailable")
+}
+
+// newUtterance returns an utterance with the given embedding.
+func newUtterance(t *testing.T, utterance string, embedding []float64) domain.Utterance {
+	t.Helper()
+	utter := domain.Utterance{Utterance: utterance}
+	require.NoError(t, utter.SetEmbedding(embedding))
+	return utter
+}
+
+func TestStoreWriteThrough(t *testing.T) {
+	ctx := context.Background()
+	cache, durable := memory.NewStore(), memory.NewStore()
+	store := NewStore(cache, durable)
+	require.NoError(t, store.Store(ctx, newUtterance(t, "key", []float64{1.0, 2.0})))
+
+	for _, tier := range []*memory.Store{cache, durable} {
+		floats, err := tier.Get(ctx, "key")
+		require.NoError(t, err)
+		assert.Equal(t, []float64{1.0, 2.0}, floats)
+	}
+
+	// Every tier is written even if one fails.
+	store = NewStore(failingStore{}, durable)
+	assert.ErrorContains(t, store.Store(ctx, newUtterance(t, "other", []float64{3.0})), "tier 0")
+	floats, err := durable.Get(ctx, "other")
+	require.NoError(t, err)
+	assert.Equal(t, []float64{3.0}, floats)
+}
+
+func TestStorePromoteOnHit(t *testing.T) {
+	ctx := context.Background()
+	cache, durable := memory.NewStore(), memory.NewStore()
+	require.NoError(t, durable.Store(ctx, newUtterance(t, "key", []float64{1.0, 2.0})))
+	store := NewStore(cache, durable)
+
+	_, err := cache.Get(ctx, "key")
+	require.Error(t, err)
+	floats, err := store.Get(ctx, "key")
+	require.NoError(t, err)
+	assert.Equal(t, []float64{1.0, 2.0}, floats)
+	floats, err = cache.Get(ctx, "key")
+	require.NoError(t, err)
+	assert.Equal(t, []float64{1.0, 2.0}, floats)
+
+	// Failing tiers are skipped, and failing promotions do not fail lookups.
+	store = NewStore(failingStore{}, durable)
+	floats, err = store.Get(ctx, "key")
+	require.NoError(t, err)
+	assert.Equal(t, []float64{1.0, 2.0}, floats)
+
+	_, err = store.Get(ctx, "missing")
+	assert.Error(t, err)
+	_, err = NewStore().Get(ctx, "missing")
+	assert.Error(t, err)
+}
+
+func TestStoreDelete(t *testing.T) {
+	ctx := context.Background()
+	cache, durable := memory.NewStore(), memory.NewStore()
+	store := NewStore(cache, durable)
+	require.NoError(t, store.Store(ctx, newUtterance(t, "key", []float64{1.0})))
+	deleter, ok := store.(semanticrouter.Deleter)
+	require.True(t, ok)
+	require.NoError(t, deleter.Delete(ctx, "key"))
+	_, err := store.Get(ctx, "key")
+	assert.Error(t, err)
+
+	// A tier which cannot delete would bring deleted embeddings back.
+	_, ok = NewStore(cache, failingStore{}).(semanticrouter.Deleter)
+	assert.False(t, ok)
+}
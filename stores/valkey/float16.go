@@ -0,0 +1,125 @@
+package valkey
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// float16Prefix starts the values of embeddings stored in half precision,
+// telling them apart from JSON values, which never start with a NUL byte.
+const float16Prefix = "\x00f16"
+
+// WithFloat16 sets whether the store stores embeddings in IEEE 754 half
+// precision instead of JSON, halving their size compared to float32 and
+// shrinking it several times compared to the default JSON encoding.
+//
+// Half-precision floats have an 11-bit significand, so each component is
+// rounded to about three significant decimal digits, a relative error of
+// at most 2^-11. Components beyond ±65504 become infinite and those below
+// about 6e-8 in magnitude become zero, which the usually normalized
+// components of embeddings are far from. The similarity of rounded
+// embeddings typically differs from the exact one in the fourth decimal.
+//
+// Embeddings are read back in either encoding, so the option can be enabled
+// on a store already holding JSON embeddings.
+func WithFloat16(enabled bool) Option {
+	return func(s *Store) {
+		s.float16 = enabled
+	}
+}
+
+// encodeFloat16 returns the half-precision encoding of the given embedding,
+// prefixed with float16Prefix.
+func encodeFloat16(embedding []float64) string {
+	buf := make([]byte, len(float16Prefix)+2*len(embedding))
+	copy(buf, float16Prefix)
+	for i, v := range embedding {
+		binary.LittleEndian.PutUint16(buf[len(float16Prefix)+2*i:], float16Bits(v))
+	}
+	return string(buf)
+}
+
+// decodeFloat16 decodes the embedding of the given value if it is encoded
+// in half precision, and reports whether it is.
+//
+// A half-precision value whose payload is not a whole number of components
+// is corrupted and fails with an error.
+func decodeFloat16(val string) (embedding []float64, ok bool, err error) {
+	data, ok := strings.CutPrefix(val, float16Prefix)
+	if !ok {
+		return nil, false, nil
+	}
+	if len(data)%2 != 0 {
+		return nil, true, fmt.Errorf("half-precision embedding has an odd length of %d bytes", len(data))
+	}
+	embedding = make([]float64, len(data)/2)
+	for i := range embedding {
+		embedding[i] = float16Value(binary.LittleEndian.Uint16([]byte(data[2*i:])))
+	}
+	return embedding, true, nil
+}
+
+// float16Bits returns the IEEE 754 half-precision bits of the given value,
+// rounded to the nearest half-precision float, ties to even.
+func float16Bits(f float64) uint16 {
+	b := math.Float64bits(f)
+	sign := uint16(b>>48) & 0x8000
+	exp := int(b>>52) & 0x7ff
+	mant := b & (1<<52 - 1)
+	if exp == 0x7ff {
+		if mant != 0 {
+			return sign | 0x7e00
+		}
+		return sign | 0x7c00
+	}
+	e := exp - 1023 + 15
+	switch {
+	case e >= 0x1f:
+		return sign | 0x7c00
+	case e < -10:
+		return sign
+	case e <= 0:
+		// The value is subnormal in half precision: shift the significand,
+		// including its implicit bit, to a multiple of 2^-24.
+		return sign | uint16(roundShift(mant|1<<52, uint(43-e)))
+	}
+	bits := uint64(e)<<10 + roundShift(mant, 42)
+	if bits >= 0x7c00 {
+		return sign | 0x7c00
+	}
+	return sign | uint16(bits)
+}
+
+// roundShift returns v shifted right by the given number of bits, rounded to
+// the nearest integer, ties to even.
+func roundShift(v uint64, shift uint) uint64 {
+	shifted := v >> shift
+	rem := v & (1<<shift - 1)
+	half := uint64(1) << (shift - 1)
+	if rem > half || rem == half && shifted&1 == 1 {
+		shifted++
+	}
+	return shifted
+}
+
+// float16Value returns the value of the given IEEE 754 half-precision bits.
+func float16Value(h uint16) float64 {
+	sign := 1.0
+	if h&0x8000 != 0 {
+		sign = -1.0
+	}
+	exp := int(h>>10) & 0x1f
+	mant := float64(h & 0x3ff)
+	switch exp {
+	case 0:
+		return sign * math.Ldexp(mant, -24)
+	case 0x1f:
+		if mant != 0 {
+			return math.NaN()
+		}
+		return math.Inf(int(sign))
+	}
+	return sign * math.Ldexp(mant+0x400, exp-25)
+}
@@ -0,0 +1,108 @@
+package valkey
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	clientLib "github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestFloat16Bits tests the rounding of values to half precision.
+func TestFloat16Bits(t *testing.T) {
+	tests := []struct {
+		value    float64
+		expected uint16
+	}{
+		{value: 0, expected: 0x0000},
+		{value: math.Copysign(0, -1), expected: 0x8000},
+		{value: 1, expected: 0x3c00},
+		{value: -2, expected: 0xc000},
+		{value: 1.0 / 3.0, expected: 0x3555},
+		{value: 1 + math.Ldexp(1, -11), expected: 0x3c00},   // tie, to even
+		{value: 1 + 3*math.Ldexp(1, -11), expected: 0x3c02}, // tie, to even
+		{value: 65504, expected: 0x7bff},
+		{value: 65520, expected: 0x7c00},
+		{value: math.Inf(-1), expected: 0xfc00},
+		{value: math.NaN(), expected: 0x7e00},
+		{value: math.Ldexp(1, -14), expected: 0x0400},
+		{value: math.Ldexp(1, -24), expected: 0x0001},
+		{value: math.Ldexp(1, -25), expected: 0x0000},
+		{value: 1.5 * math.Ldexp(1, -25), expected: 0x0001},
+		{value: math.Ldexp(1023, -24) + math.Ldexp(1, -25), expected: 0x0400},
+		{value: 1e-300, expected: 0x0000},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, float16Bits(tt.value), "%v", tt.value)
+	}
+	for h := range 1 << 16 {
+		v := float16Value(uint16(h))
+		if math.IsNaN(v) {
+			continue
+		}
+		assert.Equal(t, uint16(h), float16Bits(v))
+	}
+}
+
+// TestStoreFloat16 tests that embeddings stored in half precision take half
+// the bytes of float32 and keep their similarities within tolerance.
+func TestStoreFloat16(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	rds := clientLib.NewClient(&clientLib.Options{Addr: mr.Addr()})
+	store := NewStore(rds, WithFloat16(true))
+
+	rng := rand.New(rand.NewSource(1))
+	const dimension = 384
+	embeddings := make([][]float64, 2)
+	for i := range embeddings {
+		embeddings[i] = make([]float64, dimension)
+		for j := range embeddings[i] {
+			embeddings[i][j] = rng.NormFloat64() / math.Sqrt(dimension)
+		}
+		_, err := store.Set(ctx, string(rune('a'+i)), embeddings[i])
+		require.NoError(t, err)
+	}
+
+	raw, err := mr.Get("a")
+	require.NoError(t, err)
+	assert.Equal(t, 4*dimension/2, len(raw)-len(float16Prefix))
+
+	a, err := store.Get(ctx, "a")
+	require.NoError(t, err)
+	b, err := store.Get(ctx, "b")
+	require.NoError(t, err)
+	require.Len(t, a, dimension)
+	for j, v := range a {
+		assert.InEpsilon(t, embeddings[0][j], v, math.Ldexp(1, -11))
+	}
+	assert.InDelta(t, 1.0, cosine(embeddings[0], a), 1e-6)
+	assert.InDelta(t, cosine(embeddings[0], embeddings[1]), cosine(a, b), 1e-3)
+
+	// JSON embeddings stored without the option are still read.
+	_, err = NewStore(rds).Set(ctx, "json", []float64{1.0, 2.0})
+	require.NoError(t, err)
+	floats, err := store.Get(ctx, "json")
+	require.NoError(t, err)
+	assert.Equal(t, []float64{1.0, 2.0}, floats)
+
+	// Truncated half-precision values are reported as corrupted.
+	require.NoError(t, mr.Set("truncated", raw[:len(raw)-1]))
+	_, err = store.Get(ctx, "truncated")
+	assert.ErrorContains(t, err, "odd length")
+}
+
+// cosine returns the cosine similarity of the given vectors.
+func cosine(a, b []float64) float64 {
+	var dot, na, nb float64
+	for i := range a {
+		dot += a[i] * b[i]
+		na += a[i] * a[i]
+		nb += b[i] * b[i]
+	}
+	return dot / math.Sqrt(na*nb)
+}
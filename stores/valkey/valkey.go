@@ -6,36 +6,100 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"slices"
+	"sort"
+	"sync"
+	"time"
 
 	"github.com/conneroisu/go-semantic-router/domain"
 	"github.com/redis/go-redis/v9"
 )
 
+const (
+	// DefaultTimeout is the default timeout of each redis operation.
+	DefaultTimeout = 5 * time.Second
+	// DefaultRetries is the default number of retries of a redis operation
+	// failing with a transient error.
+	DefaultRetries = 1
+	// DefaultBackoff is the default delay before retrying a redis operation.
+	DefaultBackoff = 50 * time.Millisecond
+)
+
 // Store is a simple key-value store for embeddings.
+//
+// The store only issues single-key commands, so it works unchanged against
+// Redis Cluster deployments, keys being spread across slots.
 type Store struct {
-	rds *redis.Client
+	rds     redis.UniversalClient
+	timeout time.Duration
+	retries int
+	backoff time.Duration
+	float16 bool // float16 is whether embeddings are stored in half precision, see WithFloat16.
+}
+
+// Option is a function that configures a Store.
+type Option func(*Store)
+
+// WithTimeout sets the timeout of each redis operation of the store.
+//
+// Each attempt of a retried operation gets its own timeout. A timeout of zero
+// disables it, leaving only the timeouts of the redis client.
+func WithTimeout(timeout time.Duration) Option {
+	return func(s *Store) {
+		s.timeout = timeout
+	}
+}
+
+// WithRetries sets how many times a redis operation failing with a transient
+// error is retried, waiting backoff between attempts.
+//
+// Missing keys and errors replied by the server are never retried.
+func WithRetries(retries int, backoff time.Duration) Option {
+	return func(s *Store) {
+		s.retries = retries
+		s.backoff = backoff
+	}
 }
 
 // NewStore creates a new Store from a redis client.
-func NewStore(rds *redis.Client) *Store {
-	return &Store{rds: rds}
+//
+// The client may be a *redis.Client, a *redis.ClusterClient for Redis
+// Cluster deployments, or any other redis.UniversalClient, such as a
+// *redis.Ring or a failover client.
+func NewStore(rds redis.UniversalClient, opts ...Option) *Store {
+	s := &Store{
+		rds:     rds,
+		timeout: DefaultTimeout,
+		retries: DefaultRetries,
+		backoff: DefaultBackoff,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 // Get gets a value from the
+//
+// If the utterance is not in the store, the returned error wraps redis.Nil.
 func (s *Store) Get(
 	ctx context.Context,
 	utterance string,
 ) (embedding []float64, err error) {
-	cmd := s.rds.Get(ctx, utterance)
-	val, err := cmd.Result()
+	var val string
+	err = s.do(ctx, func(ctx context.Context) error {
+		val, err = s.rds.Get(ctx, utterance).Result()
+		return err
+	})
 	if err != nil {
 		if errors.Is(err, redis.Nil) {
-			fmt.Println("key2 does not exist")
-			fmt.Println(err)
 			return nil, fmt.Errorf("key does not exist: %w", err)
 		}
 		return nil, err
 	}
+	if embedding, ok, err := decodeFloat16(val); ok {
+		return embedding, err
+	}
 	var utPr domain.UtterancePrime
 	err = json.Unmarshal(bytes.NewBufferString(val).Bytes(), &utPr)
 	if err != nil {
@@ -50,14 +114,200 @@ func (s *Store) Set(
 	utterance string,
 	value []float64,
 ) (string, error) {
-	val, err := json.Marshal(domain.UtterancePrime{Embedding: value})
+	return s.set(ctx, utterance, value, 0)
+}
+
+// SetWithTTL stores the embedding of an utterance in the store, expiring it
+// after the given ttl with the expiry of the redis key. A ttl of zero or
+// less stores it without expiry, as Store.
+func (s *Store) SetWithTTL(
+	ctx context.Context,
+	utterance domain.Utterance,
+	ttl time.Duration,
+) error {
+	embedding, err := utterance.Embedding()
 	if err != nil {
-		return "", fmt.Errorf("error marshaling embedding: %w", err)
+		return fmt.Errorf("error getting embedding: %w", err)
+	}
+	_, err = s.set(ctx, utterance.Utterance, embedding, max(ttl, 0))
+	return err
+}
+
+// set sets a value in the store, expiring after the given ttl unless it is
+// zero, and returns the stored value.
+func (s *Store) set(
+	ctx context.Context,
+	utterance string,
+	value []float64,
+	ttl time.Duration,
+) (string, error) {
+	var val string
+	if s.float16 {
+		val = encodeFloat16(value)
+	} else {
+		data, err := json.Marshal(domain.UtterancePrime{Embedding: value})
+		if err != nil {
+			return "", fmt.Errorf("error marshaling embedding: %w", err)
+		}
+		val = string(data)
 	}
-	cmd := s.rds.Set(ctx, utterance, string(val), 0)
-	err = cmd.Err()
+	err := s.do(ctx, func(ctx context.Context) error {
+		return s.rds.Set(ctx, utterance, val, ttl).Err()
+	})
 	if err != nil {
 		return "", err
 	}
-	return string(val), nil
+	return val, nil
+}
+
+// Store stores the embedding of an utterance in the store, see Set.
+//
+// It makes the store a semanticrouter.Store.
+func (s *Store) Store(
+	ctx context.Context,
+	utterance domain.Utterance,
+) error {
+	embedding, err := utterance.Embedding()
+	if err != nil {
+		return fmt.Errorf("error getting embedding: %w", err)
+	}
+	_, err = s.Set(ctx, utterance.Utterance, embedding)
+	return err
+}
+
+// GetMetadata gets a metadata record from the store.
+//
+// If the key is not in the store, the returned error wraps redis.Nil.
+func (s *Store) GetMetadata(
+	ctx context.Context,
+	key string,
+) (value []byte, err error) {
+	err = s.do(ctx, func(ctx context.Context) error {
+		value, err = s.rds.Get(ctx, key).Bytes()
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, fmt.Errorf("key does not exist: %w", err)
+		}
+		return nil, err
+	}
+	return value, nil
+}
+
+// StoreMetadata sets a metadata record in the store.
+func (s *Store) StoreMetadata(
+	ctx context.Context,
+	key string,
+	value []byte,
+) error {
+	return s.do(ctx, func(ctx context.Context) error {
+		return s.rds.Set(ctx, key, value, 0).Err()
+	})
+}
+
+// scanCount is the number of keys requested from redis per SCAN call.
+const scanCount = 100
+
+// Keys returns the sorted keys of the redis database of the store, scanning
+// it incrementally with SCAN so that large databases do not block the server.
+//
+// With a *redis.ClusterClient, the keys of every master node are scanned.
+// Keys not set by the store, if the database is shared, are listed as well.
+func (s *Store) Keys(ctx context.Context) ([]string, error) {
+	var keys []string
+	if cluster, ok := s.rds.(*redis.ClusterClient); ok {
+		var mu sync.Mutex
+		err := cluster.ForEachMaster(ctx, func(ctx context.Context, node *redis.Client) error {
+			nodeKeys, err := s.scan(ctx, node)
+			if err != nil {
+				return err
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			keys = append(keys, nodeKeys...)
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error scanning keys: %w", err)
+		}
+	} else {
+		var err error
+		keys, err = s.scan(ctx, s.rds)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning keys: %w", err)
+		}
+	}
+	sort.Strings(keys)
+	return slices.Compact(keys), nil
+}
+
+// scan returns the keys of the given redis node, possibly with duplicates
+// as SCAN may return a key more than once.
+func (s *Store) scan(ctx context.Context, rds redis.Cmdable) ([]string, error) {
+	var (
+		keys   []string
+		cursor uint64
+	)
+	for {
+		var (
+			page []string
+			next uint64
+		)
+		err := s.do(ctx, func(ctx context.Context) (err error) {
+			page, next, err = rds.Scan(ctx, cursor, "*", scanCount).Result()
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, page...)
+		cursor = next
+		if cursor == 0 {
+			return keys, nil
+		}
+	}
+}
+
+// do runs the given redis operation with the store's timeout, retrying it
+// while it fails with a transient error.
+func (s *Store) do(
+	ctx context.Context,
+	op func(ctx context.Context) error,
+) (err error) {
+	for attempt := 0; ; attempt++ {
+		err = s.attempt(ctx, op)
+		if err == nil || attempt >= s.retries || !transient(err) {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(s.backoff):
+		}
+	}
+}
+
+// attempt runs the given redis operation once with the store's timeout.
+func (s *Store) attempt(
+	ctx context.Context,
+	op func(ctx context.Context) error,
+) error {
+	if s.timeout <= 0 {
+		return op(ctx)
+	}
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return op(ctx)
+}
+
+// transient reports whether the given redis error may succeed if retried.
+//
+// Missing keys and errors replied by the server are not transient.
+func transient(err error) bool {
+	if errors.Is(err, redis.Nil) || errors.Is(err, context.Canceled) {
+		return false
+	}
+	var rErr redis.Error
+	return !errors.As(err, &rErr)
 }
@@ -2,17 +2,24 @@ package valkey
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/alicebob/miniredis/v2"
+	"github.com/conneroisu/go-semantic-router/domain"
 	clientLib "github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/testcontainers/testcontainers-go"
 	"github.com/testcontainers/testcontainers-go/wait"
 )
 
 // TestStore is a test for the redis/valkey store.
 func TestStore(t *testing.T) {
+	testcontainers.SkipIfProviderIsNotHealthy(t)
 	ctx := context.Background()
 	req := testcontainers.ContainerRequest{
 		Image: "redis:7.2",
@@ -56,3 +63,229 @@ func TestStore(t *testing.T) {
 		floats,
 	)
 }
+
+// flakyHook is a redis hook failing the first given number of commands with
+// a transient error.
+type flakyHook struct {
+	failures int
+	calls    int
+}
+
+func (h *flakyHook) DialHook(next clientLib.DialHook) clientLib.DialHook {
+	return next
+}
+
+func (h *flakyHook) ProcessHook(next clientLib.ProcessHook) clientLib.ProcessHook {
+	return func(ctx context.Context, cmd clientLib.Cmder) error {
+		h.calls++
+		if h.calls <= h.failures {
+			return errors.New("connection reset by peer")
+		}
+		return next(ctx, cmd)
+	}
+}
+
+func (h *flakyHook) ProcessPipelineHook(
+	next clientLib.ProcessPipelineHook,
+) clientLib.ProcessPipelineHook {
+	return next
+}
+
+// TestStoreRetry tests that transient redis errors are retried and missing
+// keys are not.
+func TestStoreRetry(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	rds := clientLib.NewClient(&clientLib.Options{Addr: mr.Addr()})
+	hook := &flakyHook{}
+	rds.AddHook(hook)
+	store := NewStore(rds, WithRetries(1, time.Millisecond))
+
+	hook.failures, hook.calls = 1, 0
+	_, err := store.Set(ctx, "key", []float64{1.0, 2.0, 3.0})
+	require.NoError(t, err)
+	assert.Equal(t, 2, hook.calls)
+
+	hook.failures, hook.calls = 1, 0
+	floats, err := store.Get(ctx, "key")
+	require.NoError(t, err)
+	assert.Equal(t, []float64{1.0, 2.0, 3.0}, floats)
+	assert.Equal(t, 2, hook.calls)
+
+	hook.failures, hook.calls = 0, 0
+	_, err = store.Get(ctx, "missing")
+	assert.ErrorIs(t, err, clientLib.Nil)
+	assert.Equal(t, 1, hook.calls)
+
+	hook.failures, hook.calls = 2, 0
+	_, err = store.Get(ctx, "key")
+	assert.Error(t, err)
+	assert.Equal(t, 2, hook.calls)
+}
+
+// TestStoreCluster tests the store against a cluster client, using a
+// single-node cluster emulated by miniredis.
+func TestStoreCluster(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	rds := clientLib.NewClusterClient(&clientLib.ClusterOptions{
+		Addrs: []string{mr.Addr()},
+	})
+	t.Cleanup(func() { _ = rds.Close() })
+	store := NewStore(rds)
+
+	for _, key := range []string{"key", "{route}:key", "other key"} {
+		_, err := store.Set(ctx, key, []float64{1.0, 2.0, 3.0})
+		require.NoError(t, err)
+		floats, err := store.Get(ctx, key)
+		require.NoError(t, err)
+		assert.Equal(t, []float64{1.0, 2.0, 3.0}, floats)
+	}
+	_, err := store.Get(ctx, "missing")
+	assert.ErrorIs(t, err, clientLib.Nil)
+}
+
+// TestStoreClusterContainer tests the store against a cluster client
+// connected to a single-node redis cluster container.
+func TestStoreClusterContainer(t *testing.T) {
+	testcontainers.SkipIfProviderIsNotHealthy(t)
+	ctx := context.Background()
+	container, err := testcontainers.GenericContainer(
+		ctx,
+		testcontainers.GenericContainerRequest{
+			ContainerRequest: testcontainers.ContainerRequest{
+				Image:        "redis:7.2",
+				ExposedPorts: []string{"6379/tcp"},
+				Cmd:          []string{"redis-server", "--cluster-enabled", "yes"},
+				WaitingFor:   wait.ForLog("Ready to accept connections"),
+			},
+			Started: true,
+		})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = container.Terminate(ctx) })
+	code, _, err := container.Exec(ctx, []string{"redis-cli", "cluster", "addslotsrange", "0", "16383"})
+	require.NoError(t, err)
+	require.Zero(t, code)
+	endpoint, err := container.Endpoint(ctx, "")
+	require.NoError(t, err)
+
+	// The node advertises its address on the container network, so its
+	// slots are mapped to the exposed endpoint instead of being discovered.
+	rds := clientLib.NewClusterClient(&clientLib.ClusterOptions{
+		ClusterSlots: func(context.Context) ([]clientLib.ClusterSlot, error) {
+			return []clientLib.ClusterSlot{{
+				Start: 0,
+				End:   16383,
+				Nodes: []clientLib.ClusterNode{{Addr: endpoint}},
+			}}, nil
+		},
+	})
+	t.Cleanup(func() { _ = rds.Close() })
+	require.Eventually(t, func() bool {
+		info, err := rds.ClusterInfo(ctx).Result()
+		return err == nil && strings.Contains(info, "cluster_state:ok")
+	}, 10*time.Second, 100*time.Millisecond)
+	store := NewStore(rds)
+
+	keys := []string{"key", "{route}:key", "other key"}
+	for _, key := range keys {
+		_, err := store.Set(ctx, key, []float64{1.0, 2.0, 3.0})
+		require.NoError(t, err)
+		floats, err := store.Get(ctx, key)
+		require.NoError(t, err)
+		assert.Equal(t, []float64{1.0, 2.0, 3.0}, floats)
+	}
+	stored, err := store.Keys(ctx)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, keys, stored)
+	_, err = store.Get(ctx, "missing")
+	assert.ErrorIs(t, err, clientLib.Nil)
+}
+
+// TestStoreKeys tests that the keys of the store are scanned across several
+// SCAN pages, with a single node and with a cluster client.
+func TestStoreKeys(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	var want []string
+	for i := 0; i < 2*scanCount+1; i++ {
+		key := fmt.Sprintf("key %03d", i)
+		want = append(want, key)
+	}
+
+	clients := map[string]clientLib.UniversalClient{
+		"client": clientLib.NewClient(&clientLib.Options{Addr: mr.Addr()}),
+		"cluster": clientLib.NewClusterClient(&clientLib.ClusterOptions{
+			Addrs: []string{mr.Addr()},
+		}),
+	}
+	for name, rds := range clients {
+		t.Run(name, func(t *testing.T) {
+			t.Cleanup(func() { _ = rds.Close() })
+			mr.FlushAll()
+			store := NewStore(rds)
+			keys, err := store.Keys(ctx)
+			require.NoError(t, err)
+			assert.Empty(t, keys)
+
+			for _, key := range want {
+				_, err = store.Set(ctx, key, []float64{1.0})
+				require.NoError(t, err)
+			}
+			keys, err = store.Keys(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, want, keys)
+		})
+	}
+}
+
+// TestStoreUtterance tests storing utterances through the Store method.
+func TestStoreUtterance(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	store := NewStore(clientLib.NewClient(&clientLib.Options{Addr: mr.Addr()}))
+	utter := domain.Utterance{Utterance: "key"}
+	require.NoError(t, utter.SetEmbedding([]float64{1.0, 2.0}))
+	require.NoError(t, store.Store(ctx, utter))
+	floats, err := store.Get(ctx, "key")
+	require.NoError(t, err)
+	assert.Equal(t, []float64{1.0, 2.0}, floats)
+
+	assert.Error(t, store.Store(ctx, domain.Utterance{Utterance: "no embedding"}))
+}
+
+// TestStoreMetadata tests storing and getting metadata records.
+func TestStoreMetadata(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	store := NewStore(clientLib.NewClient(&clientLib.Options{Addr: mr.Addr()}))
+	_, err := store.GetMetadata(ctx, "key")
+	assert.ErrorIs(t, err, clientLib.Nil)
+
+	require.NoError(t, store.StoreMetadata(ctx, "key", []byte(`{"version":1}`)))
+	value, err := store.GetMetadata(ctx, "key")
+	require.NoError(t, err)
+	assert.Equal(t, []byte(`{"version":1}`), value)
+}
+
+// TestStoreSetWithTTL tests that embeddings stored with a TTL expire.
+func TestStoreSetWithTTL(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	store := NewStore(clientLib.NewClient(&clientLib.Options{Addr: mr.Addr()}))
+	utter := domain.Utterance{Utterance: "key"}
+	require.NoError(t, utter.SetEmbedding([]float64{1.0, 2.0}))
+	require.NoError(t, store.SetWithTTL(ctx, utter, time.Minute))
+	assert.Equal(t, time.Minute, mr.TTL("key"))
+	floats, err := store.Get(ctx, "key")
+	require.NoError(t, err)
+	assert.Equal(t, []float64{1.0, 2.0}, floats)
+
+	mr.FastForward(time.Minute)
+	_, err = store.Get(ctx, "key")
+	assert.ErrorIs(t, err, clientLib.Nil)
+
+	// Embeddings stored without a TTL do not expire.
+	require.NoError(t, store.Store(ctx, utter))
+	assert.Zero(t, mr.TTL("key"))
+}
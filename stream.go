@@ -0,0 +1,92 @@
+package semanticrouter
+
+import (
+	"context"
+	"runtime"
+	"sync"
+)
+
+// MatchResult represents the result of matching a single utterance.
+type MatchResult struct {
+	Utterance string             `json:"utterance"           yaml:"utterance"           toml:"utterance"`           // Utterance is the utterance that was matched.
+	Route     string             `json:"route"               yaml:"route"               toml:"route"`               // Route is the name of the best matching route.
+	Score     float64            `json:"score"               yaml:"score"               toml:"score"`               // Score is the similarity score of the best matching route.
+	Breakdown map[string]float64 `json:"breakdown,omitempty" yaml:"breakdown,omitempty" toml:"breakdown,omitempty"` // Breakdown is the score of each similarity function, only set by MatchN.
+	Err       error              `json:"-"                   yaml:"-"                   toml:"-"`                   // Err is the error that occurred while matching, if any.
+}
+
+// MatchStream matches every utterance received on in and sends a MatchResult
+// for each of them on out.
+//
+// Each utterance is matched as by Match, and counts in Stats and in the
+// observer's matches, but the index embeddings are read from the store once
+// when the stream starts and are reused for every utterance, so only the
+// query is encoded as it arrives. Utterances are matched concurrently by at
+// most GOMAXPROCS workers, therefore results are not guaranteed to be sent
+// in the order the utterances were received.
+//
+// out is closed once in is closed and drained, or once the given context is
+// canceled. Errors are reported through the Err field of MatchResult.
+func (r *Router) MatchStream(
+	ctx context.Context,
+	in <-chan string,
+	out chan<- MatchResult,
+) {
+	defer close(out)
+	r.mu.RLock()
+	index, err := r.loadIndexLimit(ctx, r.maxCandidates)
+	r.mu.RUnlock()
+	if err != nil {
+		select {
+		case out <- MatchResult{Err: err}:
+		case <-ctx.Done():
+		}
+		return
+	}
+	var wg sync.WaitGroup
+	workers := runtime.GOMAXPROCS(0)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				var utterance string
+				var ok bool
+				select {
+				case <-ctx.Done():
+					return
+				case utterance, ok = <-in:
+					if !ok {
+						return
+					}
+				}
+				result := MatchResult{Utterance: utterance}
+				result.Route, result.Score, result.Err = r.matchStreamed(
+					ctx,
+					utterance,
+					index,
+				)
+				select {
+				case out <- result:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// matchStreamed matches the given utterance against the already loaded
+// index.
+func (r *Router) matchStreamed(
+	ctx context.Context,
+	utterance string,
+	index []indexEntry,
+) (string, float64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.matchLoading(ctx, utterance, true, func(context.Context, int) ([]indexEntry, error) {
+		return index, nil
+	})
+}
@@ -0,0 +1,88 @@
+package semanticrouter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/conneroisu/go-semantic-router/stores/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMatchStream tests that MatchStream matches every utterance it receives
+// and closes the output channel once the input channel is closed.
+func TestMatchStream(t *testing.T) {
+	ctx := context.Background()
+	router, err := NewRouter(newTestRoutes(), newTestEncoder(), memory.NewStore())
+	require.NoError(t, err)
+
+	expected := map[string]string{
+		"is it raining outside?":   "chitchat",
+		"what about the election?": "politics",
+		"lovely weather today":     "chitchat",
+		"unknown":                  "",
+	}
+	in := make(chan string)
+	out := make(chan MatchResult)
+	go router.MatchStream(ctx, in, out)
+	go func() {
+		defer close(in)
+		for utterance := range expected {
+			in <- utterance
+		}
+	}()
+
+	results := make(map[string]MatchResult)
+	for result := range out {
+		results[result.Utterance] = result
+	}
+	require.Len(t, results, len(expected))
+	for utterance, route := range expected {
+		result := results[utterance]
+		if route == "" {
+			assert.Error(t, result.Err)
+			continue
+		}
+		assert.NoError(t, result.Err)
+		assert.Equal(t, route, result.Route)
+		assert.Greater(t, result.Score, 0.0)
+	}
+}
+
+// TestMatchStreamStats tests that streamed matches count in the statistics
+// of the router as the matches of Match do.
+func TestMatchStreamStats(t *testing.T) {
+	ctx := context.Background()
+	router, err := NewRouter(newTestRoutes(), newTestEncoder(), memory.NewStore(), WithExactMatchShortcut(true))
+	require.NoError(t, err)
+
+	in := make(chan string, 2)
+	in <- "is it raining outside?"
+	in <- "lovely weather today"
+	close(in)
+	out := make(chan MatchResult)
+	go router.MatchStream(ctx, in, out)
+	for result := range out {
+		require.NoError(t, result.Err)
+		assert.Equal(t, "chitchat", result.Route)
+		if result.Utterance == "lovely weather today" {
+			assert.Equal(t, 1.0, result.Score)
+		}
+	}
+	assert.Equal(t, 2, router.Stats()["chitchat"].Wins)
+}
+
+// TestMatchStreamCanceled tests that MatchStream closes the output channel
+// once the context is canceled.
+func TestMatchStreamCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	router, err := NewRouter(newTestRoutes(), newTestEncoder(), memory.NewStore())
+	require.NoError(t, err)
+
+	in := make(chan string)
+	out := make(chan MatchResult)
+	go router.MatchStream(ctx, in, out)
+	cancel()
+	for range out {
+	}
+}
@@ -0,0 +1,53 @@
+package semanticrouter
+
+import (
+	"context"
+	"slices"
+)
+
+// MatchWithTags returns the route that matches the given utterance among the
+// utterances carrying at least one of the given tags.
+//
+// Untagged utterances are always considered, unless the router uses
+// WithStrictTags. If no tags are given, MatchWithTags is equivalent to Match.
+func (r *Router) MatchWithTags(
+	ctx context.Context,
+	utterance string,
+	tags []string,
+) (bestRouteName string, bestScore float64, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var filter func([]indexEntry) []indexEntry
+	if len(tags) > 0 {
+		filter = func(index []indexEntry) []indexEntry {
+			return r.tagIndex(index, tags)
+		}
+	}
+	ranked, err := r.scoreUtterance(ctx, utterance, filter)
+	if err != nil {
+		return "", 0.0, err
+	}
+	return r.bestRoute(ranked.scores)
+}
+
+// tagIndex returns the entries of the index carrying at least one of the
+// given tags, along with the untagged entries unless the router uses strict
+// tags.
+func (r *Router) tagIndex(index []indexEntry, tags []string) []indexEntry {
+	var tagged []indexEntry
+	for _, entry := range index {
+		if len(entry.tags) == 0 {
+			if !r.strictTags {
+				tagged = append(tagged, entry)
+			}
+			continue
+		}
+		for _, tag := range tags {
+			if slices.Contains(entry.tags, tag) {
+				tagged = append(tagged, entry)
+				break
+			}
+		}
+	}
+	return tagged
+}
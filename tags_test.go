@@ -0,0 +1,67 @@
+package semanticrouter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/conneroisu/go-semantic-router/domain"
+	"github.com/conneroisu/go-semantic-router/stores/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTaggedRoutes returns the test routes with the chitchat utterances
+// tagged for voice and one politics utterance tagged for text.
+func newTaggedRoutes() []Route {
+	routes := newTestRoutes()
+	for i := range routes[0].Utterances {
+		routes[0].Utterances[i].Tags = []string{"voice"}
+	}
+	routes[1].Utterances[0].Tags = []string{"text", "web"}
+	return routes
+}
+
+// TestMatchWithTags tests that only utterances carrying a requested tag are
+// matched, along with the untagged ones.
+func TestMatchWithTags(t *testing.T) {
+	ctx := context.Background()
+	router, err := NewRouter(newTaggedRoutes(), newTestEncoder(), memory.NewStore())
+	require.NoError(t, err)
+
+	route, _, err := router.MatchWithTags(ctx, "is it raining outside?", []string{"voice"})
+	require.NoError(t, err)
+	assert.Equal(t, "chitchat", route)
+
+	// The voice-only chitchat utterances are excluded, so politics wins.
+	route, _, err = router.MatchWithTags(ctx, "is it raining outside?", []string{"text"})
+	require.NoError(t, err)
+	assert.Equal(t, "politics", route)
+
+	route, _, err = router.MatchWithTags(ctx, "is it raining outside?", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "chitchat", route)
+}
+
+// TestMatchWithStrictTags tests that untagged utterances are excluded with
+// WithStrictTags.
+func TestMatchWithStrictTags(t *testing.T) {
+	ctx := context.Background()
+	routes := newTaggedRoutes()
+	routes[1].Utterances = append(routes[1].Utterances, domain.Utterance{
+		Utterance: "what about the election?",
+	})
+	router, err := NewRouter(
+		routes,
+		newTestEncoder(),
+		memory.NewStore(),
+		WithStrictTags(),
+	)
+	require.NoError(t, err)
+
+	route, _, err := router.MatchWithTags(ctx, "what about the election?", []string{"voice"})
+	require.NoError(t, err)
+	assert.Equal(t, "chitchat", route)
+
+	_, _, err = router.MatchWithTags(ctx, "what about the election?", []string{"sms"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,179 @@
+package semanticrouter
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"sync"
+
+	"github.com/conneroisu/go-semantic-router/domain"
+)
+
+// tenantKeyPrefix prefixes the keys of the embeddings of the tenants of a
+// TenantRouter in its store.
+const tenantKeyPrefix = "semanticrouter:tenant:"
+
+// TenantKeyPrefix returns the prefix of the keys under which the embeddings
+// of the given tenant of a TenantRouter are stored.
+//
+// The prefix includes the length of the tenant ID, so that no key of a
+// tenant is also a key of another tenant, whatever their IDs.
+func TenantKeyPrefix(tenantID string) string {
+	return tenantKeyPrefix + strconv.Itoa(len(tenantID)) + ":" + tenantID + ":"
+}
+
+// TenantRouter routes the utterances of several tenants, each with its own
+// independent set of routes, sharing one encoder and one store.
+//
+// Each tenant is served by its own Router, built with the options of the
+// TenantRouter, so that it has its own query cache, if any, and its matches
+// only ever consider its own routes. The embeddings of each tenant are
+// stored in the shared store under keys prefixed with TenantKeyPrefix, so
+// that tenants with the same utterances neither see nor overwrite each
+// other's embeddings. Through the prefix, the routers of the tenants see the
+// store as a Deleter if the store itself implements Deleter, and as a plain
+// Store otherwise: their IndexInfo is not recorded and features relying on
+// other capabilities of the store, such as PruneStore, are not supported.
+//
+// The methods of a TenantRouter are safe for concurrent use.
+type TenantRouter struct {
+	Encoder Encoder // Encoder is the Encoder shared by the tenants.
+	Storage Store   // Storage is the Store shared by the tenants.
+
+	opts    []Option
+	mu      sync.RWMutex
+	tenants map[string]*Router
+}
+
+// NewTenantRouter creates a new TenantRouter without tenants, whose tenants
+// are served by routers built with the given options, see SetTenant.
+func NewTenantRouter(encoder Encoder, store Store, opts ...Option) *TenantRouter {
+	return &TenantRouter{
+		Encoder: encoder,
+		Storage: store,
+		opts:    opts,
+		tenants: make(map[string]*Router),
+	}
+}
+
+// SetTenant sets the routes of the tenant with the given ID, adding the
+// tenant if it does not exist.
+//
+// A new tenant is served by a router built with NewRouterContext, while the
+// routes of an existing tenant are replaced with SetRoutes, so that only
+// the utterances the tenant did not have are encoded. Other tenants are not
+// affected, and can be matched while the routes are encoded. If several
+// calls add the same tenant concurrently, the last one wins.
+func (t *TenantRouter) SetTenant(
+	ctx context.Context,
+	tenantID string,
+	routes []Route,
+) error {
+	if tenantID == "" {
+		return fmt.Errorf("tenant ID must not be empty")
+	}
+	router, ok := t.Tenant(tenantID)
+	if ok {
+		_, err := router.SetRoutes(ctx, routes)
+		if err != nil {
+			return fmt.Errorf("error setting routes of tenant %q: %w", tenantID, err)
+		}
+		return nil
+	}
+	router, err := NewRouterContext(ctx, routes, t.Encoder, newTenantStore(t.Storage, TenantKeyPrefix(tenantID)), t.opts...)
+	if err != nil {
+		return fmt.Errorf("error creating router of tenant %q: %w", tenantID, err)
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.tenants[tenantID] = router
+	return nil
+}
+
+// RemoveTenant removes the tenant with the given ID, reporting whether it
+// existed. Its embeddings are left in the store.
+func (t *TenantRouter) RemoveTenant(tenantID string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	_, ok := t.tenants[tenantID]
+	delete(t.tenants, tenantID)
+	return ok
+}
+
+// Tenant returns the router serving the tenant with the given ID, if any,
+// for instance to use its other matching methods.
+func (t *TenantRouter) Tenant(tenantID string) (*Router, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	router, ok := t.tenants[tenantID]
+	return router, ok
+}
+
+// Tenants returns the sorted IDs of the tenants.
+func (t *TenantRouter) Tenants() []string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	ids := make([]string, 0, len(t.tenants))
+	for id := range t.tenants {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+// Match returns the route of the tenant with the given ID that matches the
+// given utterance, as Router.Match.
+func (t *TenantRouter) Match(
+	ctx context.Context,
+	tenantID string,
+	utterance string,
+) (bestRouteName string, bestScore float64, err error) {
+	router, ok := t.Tenant(tenantID)
+	if !ok {
+		return "", 0.0, fmt.Errorf("tenant not found: %q", tenantID)
+	}
+	return router.Match(ctx, utterance)
+}
+
+// tenantStore is the view of the store of a TenantRouter by one of its
+// tenants, whose keys are prefixed with the prefix of the tenant.
+type tenantStore struct {
+	store  Store
+	prefix string
+}
+
+// Store stores the utterance under the key prefixed with the prefix of the
+// tenant.
+func (s tenantStore) Store(ctx context.Context, utterance domain.Utterance) error {
+	utterance.Utterance = s.prefix + utterance.Utterance
+	return s.store.Store(ctx, utterance)
+}
+
+// Get gets the embedding stored under the key of the utterance prefixed
+// with the prefix of the tenant.
+func (s tenantStore) Get(ctx context.Context, utterance string) ([]float64, error) {
+	return s.store.Get(ctx, s.prefix+utterance)
+}
+
+// tenantDeleter is the view of the store of a TenantRouter by one of its
+// tenants when the store implements Deleter.
+type tenantDeleter struct {
+	tenantStore
+}
+
+// newTenantStore returns the view of the given store by the tenant with the
+// given prefix, which is a Deleter if the store is.
+func newTenantStore(store Store, prefix string) Store {
+	s := tenantStore{store: store, prefix: prefix}
+	if _, ok := store.(Deleter); ok {
+		return tenantDeleter{s}
+	}
+	return s
+}
+
+// Delete deletes the embeddings stored under the key of the utterance
+// prefixed with the prefix of the tenant.
+func (s tenantDeleter) Delete(ctx context.Context, utterance string) error {
+	return s.store.(Deleter).Delete(ctx, s.prefix+utterance)
+}
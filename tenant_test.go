@@ -0,0 +1,83 @@
+package semanticrouter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/conneroisu/go-semantic-router/domain"
+	"github.com/conneroisu/go-semantic-router/stores/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestTenantRouter tests that the tenants of a TenantRouter only match their
+// own routes, keep their embeddings apart in the shared store and have their
+// own query cache.
+func TestTenantRouter(t *testing.T) {
+	ctx := context.Background()
+	encoder := &countingEncoder{Encoder: newTestEncoder()}
+	store := memory.NewStore()
+	tenants := NewTenantRouter(encoder, store, WithQueryCache(8))
+	routes := newTestRoutes()
+	shared := Route{
+		Name:       "weather",
+		Utterances: []domain.Utterance{{Utterance: "lovely weather today"}},
+	}
+	require.NoError(t, tenants.SetTenant(ctx, "a", []Route{routes[0]}))
+	require.NoError(t, tenants.SetTenant(ctx, "b", []Route{routes[1], shared}))
+	assert.Equal(t, []string{"a", "b"}, tenants.Tenants())
+
+	route, _, err := tenants.Match(ctx, "a", "what about the election?")
+	require.NoError(t, err)
+	assert.Equal(t, "chitchat", route, "tenant a has no politics route")
+	route, _, err = tenants.Match(ctx, "b", "what about the election?")
+	require.NoError(t, err)
+	assert.Equal(t, "politics", route)
+	route, _, err = tenants.Match(ctx, "b", "is it raining outside?")
+	require.NoError(t, err)
+	assert.Equal(t, "weather", route)
+
+	keys, err := store.Keys(ctx)
+	require.NoError(t, err)
+	assert.Contains(t, keys, TenantKeyPrefix("a")+"lovely weather today")
+	assert.Contains(t, keys, TenantKeyPrefix("b")+"lovely weather today")
+	assert.NotContains(t, keys, "lovely weather today")
+
+	// Each tenant caches its own queries.
+	calls := encoder.calls.Load()
+	_, _, err = tenants.Match(ctx, "a", "what about the election?")
+	require.NoError(t, err)
+	assert.Equal(t, calls, encoder.calls.Load())
+	_, _, err = tenants.Match(ctx, "b", "is it raining outside?")
+	require.NoError(t, err)
+	assert.Equal(t, calls, encoder.calls.Load())
+
+	// Replacing the routes of a tenant leaves the other tenant untouched.
+	require.NoError(t, tenants.SetTenant(ctx, "b", []Route{routes[1]}))
+	_, err = store.Get(ctx, TenantKeyPrefix("b")+"lovely weather today")
+	assert.Error(t, err)
+	route, _, err = tenants.Match(ctx, "a", "is it raining outside?")
+	require.NoError(t, err)
+	assert.Equal(t, "chitchat", route)
+
+	_, _, err = tenants.Match(ctx, "c", "is it raining outside?")
+	assert.ErrorContains(t, err, `tenant not found: "c"`)
+	assert.Error(t, tenants.SetTenant(ctx, "", routes))
+	assert.True(t, tenants.RemoveTenant("a"))
+	assert.False(t, tenants.RemoveTenant("a"))
+	_, ok := tenants.Tenant("a")
+	assert.False(t, ok)
+
+	// Tenants only see the store as a Deleter if it is one.
+	router, ok := tenants.Tenant("b")
+	require.True(t, ok)
+	assert.Implements(t, (*Deleter)(nil), router.Storage)
+	tenants = NewTenantRouter(encoder, &countingStore{store: memory.NewStore()})
+	require.NoError(t, tenants.SetTenant(ctx, "a", routes))
+	router, ok = tenants.Tenant("a")
+	require.True(t, ok)
+	_, ok = router.Storage.(Deleter)
+	assert.False(t, ok)
+	_, err = router.UpdateRoute(ctx, "chitchat", routes[0].Utterances[:1])
+	assert.ErrorIs(t, err, ErrNotSupported)
+}
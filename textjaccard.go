@@ -0,0 +1,13 @@
+package semanticrouter
+
+// TextJaccard computes the jaccard similarity of the sets of words of two
+// texts, the number of words they share divided by the number of distinct
+// words of both.
+//
+// Words are the maximal runs of letters and digits, compared regardless of
+// case. Unlike JaccardSimilarity, it compares the texts rather than their
+// embeddings, so it rewards literal keyword overlap the embeddings may
+// miss, see WithTextJaccard.
+func TextJaccard(a, b string) float64 {
+	return keywordScore(keywordTokens(a), keywordTokens(b))
+}
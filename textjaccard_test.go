@@ -0,0 +1,73 @@
+package semanticrouter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/conneroisu/go-semantic-router/domain"
+	"github.com/conneroisu/go-semantic-router/stores/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestTextJaccard tests the jaccard similarity of overlapping, identical and
+// disjoint sets of words.
+func TestTextJaccard(t *testing.T) {
+	testCases := []struct {
+		name string
+		a, b string
+		want float64
+	}{
+		{name: "overlapping", a: "reset my password", b: "Reset the password!", want: 2.0 / 4.0},
+		{name: "identical", a: "track my order", b: "Track, my order.", want: 1.0},
+		{name: "disjoint", a: "track my order", b: "what is the weather", want: 0.0},
+		{name: "empty", a: "", b: "?!", want: 0.0},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.InDelta(t, tc.want, TextJaccard(tc.a, tc.b), 1e-12)
+			assert.InDelta(t, tc.want, TextJaccard(tc.b, tc.a), 1e-12)
+		})
+	}
+}
+
+// TestWithTextJaccard tests that the text jaccard similarity lets the route
+// sharing keywords with the query win over a semantically closer one.
+func TestWithTextJaccard(t *testing.T) {
+	ctx := context.Background()
+	routes := []Route{
+		{Name: "password", Utterances: []domain.Utterance{{Utterance: "reset my password"}}},
+		{Name: "username", Utterances: []domain.Utterance{{Utterance: "change my username"}}},
+	}
+	encoder := &mockEncoder{embeddings: map[string][]float64{
+		"reset my password":          {1.0, 0.1, 0.0},
+		"change my username":         {1.0, 0.2, 0.0},
+		"change the username please": {1.0, 0.05, 0.0},
+	}}
+	router, err := NewRouter(routes, encoder, memory.NewStore())
+	require.NoError(t, err)
+	route, _, err := router.Match(ctx, "change the username please")
+	require.NoError(t, err)
+	assert.Equal(t, "password", route)
+
+	router, err = NewRouter(routes, encoder, memory.NewStore(), WithCosineSimilarity(1.0), WithTextJaccard(0.5))
+	require.NoError(t, err)
+	route, score, err := router.Match(ctx, "change the username please")
+	require.NoError(t, err)
+	assert.Equal(t, "username", route)
+	assert.Greater(t, score, 1.0)
+	assert.Equal(t, []SimilarityInfo{
+		{Kind: SimilarityKindCosine, Name: SimilarityCosine, Coefficient: 1.0},
+		{Kind: SimilarityKindTextJaccard, Name: SimilarityTextJaccard, Coefficient: 0.5},
+	}, router.SimilarityConfig())
+
+	reloaded, err := NewRouter(routes, encoder, memory.NewStore(), ApplyConfig(router.ExportConfig())...)
+	require.NoError(t, err)
+	route, _, err = reloaded.Match(ctx, "change the username please")
+	require.NoError(t, err)
+	assert.Equal(t, "username", route)
+
+	assert.Panics(t, func() {
+		RegisterSimilarity(SimilarityTextJaccard, DotProduct)
+	})
+}
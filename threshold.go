@@ -0,0 +1,70 @@
+package semanticrouter
+
+import (
+	"math"
+	"sync"
+)
+
+// adaptiveThresholdStep is how much a single feedback moves an adaptive
+// threshold.
+const adaptiveThresholdStep = 0.01
+
+// adaptiveThreshold is a score threshold adjusted by feedback.
+type adaptiveThreshold struct {
+	mu    sync.Mutex
+	value float64
+}
+
+// get returns the current value of the threshold.
+func (t *adaptiveThreshold) get() float64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.value
+}
+
+// nudge moves the threshold by delta, without letting it go below zero.
+func (t *adaptiveThreshold) nudge(delta float64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.value = math.Max(0, t.value+delta)
+}
+
+// Threshold returns the current score threshold of the router.
+//
+// The threshold of a router using WithAdaptiveThreshold changes as feedback
+// is recorded; callers can save it and restore it later by passing it to
+// WithAdaptiveThreshold. Without a threshold, zero is returned.
+func (r *Router) Threshold() float64 {
+	if r.threshold == nil {
+		return 0
+	}
+	return r.threshold.get()
+}
+
+// RecordFeedback records whether the route returned by a match was correct,
+// adjusting the adaptive threshold of the router.
+//
+// utterance is the matched utterance, reserved for feedback depending on it
+// and currently unused. route is the route Match returned for it, empty if
+// no route was found. An incorrect match is a false positive and raises the
+// threshold; an incorrect empty result is a false negative and lowers it,
+// the other way around with LowerIsBetter. Correct results leave the
+// threshold unchanged.
+//
+// RecordFeedback does nothing if the router does not use
+// WithAdaptiveThreshold.
+func (r *Router) RecordFeedback(utterance, route string, correct bool) {
+	if r.threshold == nil || correct {
+		return
+	}
+	step := adaptiveThresholdStep
+	if r.scoreDirection == LowerIsBetter {
+		// The threshold is a maximum, made stricter by lowering it.
+		step = -step
+	}
+	if route == "" {
+		r.threshold.nudge(-step)
+		return
+	}
+	r.threshold.nudge(step)
+}
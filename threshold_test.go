@@ -0,0 +1,40 @@
+package semanticrouter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/conneroisu/go-semantic-router/stores/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestAdaptiveThreshold tests that feedback moves the threshold of the
+// router and changes its matches.
+func TestAdaptiveThreshold(t *testing.T) {
+	ctx := context.Background()
+	router, err := NewRouter(
+		newTestRoutes(),
+		newTestEncoder(),
+		memory.NewStore(),
+		WithAdaptiveThreshold(0.995),
+	)
+	require.NoError(t, err)
+	utterance := "is it raining outside?"
+
+	route, _, err := router.Match(ctx, utterance)
+	require.Error(t, err)
+	router.RecordFeedback(utterance, route, false)
+	assert.InDelta(t, 0.985, router.Threshold(), 1e-12)
+
+	route, _, err = router.Match(ctx, utterance)
+	require.NoError(t, err)
+	assert.Equal(t, "chitchat", route)
+	router.RecordFeedback(utterance, route, true)
+	assert.InDelta(t, 0.985, router.Threshold(), 1e-12)
+
+	router.RecordFeedback(utterance, route, false)
+	assert.InDelta(t, 0.995, router.Threshold(), 1e-12)
+	_, _, err = router.Match(ctx, utterance)
+	assert.Error(t, err)
+}
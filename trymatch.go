@@ -0,0 +1,26 @@
+package semanticrouter
+
+import (
+	"context"
+	"errors"
+)
+
+// TryMatch is like Match, but reports with matched whether a route matched
+// the given utterance instead of failing with ErrNoRouteFound.
+//
+// When no route matches, matched is false and err is nil; err is reserved
+// for actual failures, such as encoder or store errors, so that not
+// matching can be handled as ordinary control flow.
+func (r *Router) TryMatch(
+	ctx context.Context,
+	utterance string,
+) (route string, score float64, matched bool, err error) {
+	route, score, err = r.Match(ctx, utterance)
+	if errors.Is(err, ErrNoRouteFound) {
+		return "", 0.0, false, nil
+	}
+	if err != nil {
+		return "", 0.0, false, err
+	}
+	return route, score, true, nil
+}
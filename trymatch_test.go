@@ -0,0 +1,38 @@
+package semanticrouter
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/conneroisu/go-semantic-router/stores/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestTryMatch tests that not matching is reported without an error, and
+// that actual failures are still errors.
+func TestTryMatch(t *testing.T) {
+	ctx := context.Background()
+	router, err := NewRouter(newTestRoutes(), newTestEncoder(), memory.NewStore(), WithAdaptiveThreshold(0.985))
+	require.NoError(t, err)
+
+	route, score, matched, err := router.TryMatch(ctx, "is it raining outside?")
+	require.NoError(t, err)
+	assert.True(t, matched)
+	assert.Equal(t, "chitchat", route)
+	assert.GreaterOrEqual(t, score, 0.985)
+
+	route, score, matched, err = router.TryMatch(ctx, "what about the election?")
+	require.NoError(t, err)
+	assert.False(t, matched)
+	assert.Empty(t, route)
+	assert.Zero(t, score)
+	_, _, err = router.Match(ctx, "what about the election?")
+	assert.ErrorIs(t, err, ErrNoRouteFound)
+
+	router.Encoder = &errEncoder{err: errors.New("encoder down")}
+	_, _, matched, err = router.TryMatch(ctx, "is it raining outside?")
+	assert.ErrorAs(t, err, &ErrEncoding{})
+	assert.False(t, matched)
+}
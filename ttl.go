@@ -0,0 +1,94 @@
+package semanticrouter
+
+import (
+	"context"
+	"time"
+
+	"github.com/conneroisu/go-semantic-router/domain"
+)
+
+// queryCacheKeyPrefix prefixes the keys of cached query embeddings in the
+// store.
+const queryCacheKeyPrefix = "semanticrouter:query:"
+
+// QueryCacheKey returns the key under which the embedding of the given query
+// utterance is cached in the store, see WithQueryCacheTTL.
+func QueryCacheKey(utterance string) string {
+	return queryCacheKeyPrefix + utterance
+}
+
+// TTLStore is a Store that can also store embeddings expiring after a time
+// to live.
+//
+// Expiry is optional: the memory and valkey stores implement it. The router
+// only stores query embeddings with a TTL, see WithQueryCacheTTL; the
+// embeddings of the utterances of its routes are stored with Store and
+// never expire.
+type TTLStore interface {
+	Store
+	// SetWithTTL stores the embedding of the utterance, expiring it after
+	// the given ttl.
+	SetWithTTL(ctx context.Context, utterance domain.Utterance, ttl time.Duration) error
+}
+
+// cachedQuery is the embedding of a query in the query cache along with its
+// expiry time, zero if it does not expire.
+type cachedQuery struct {
+	embedding []float64
+	expires   time.Time
+}
+
+// getCachedQuery returns the cached embedding of the query utterance with
+// the given key, looking it up in the query cache and then, if query
+// embeddings expire, in the router's store if it is a TTLStore.
+//
+// Store errors are treated as misses.
+func (r *Router) getCachedQuery(ctx context.Context, key string) ([]float64, bool) {
+	now := time.Now()
+	if r.queryCache != nil {
+		cached, ok := r.queryCache.get(key)
+		if ok && (cached.expires.IsZero() || now.Before(cached.expires)) {
+			return cached.embedding, true
+		}
+	}
+	if _, ok := r.Storage.(TTLStore); !ok || r.queryCacheTTL <= 0 {
+		return nil, false
+	}
+	em, err := r.get(ctx, QueryCacheKey(key))
+	if err != nil {
+		return nil, false
+	}
+	if r.queryCache != nil {
+		// The remaining time to live of the stored embedding is unknown, so
+		// it is kept for at most a full time to live.
+		r.queryCache.put(key, cachedQuery{embedding: em, expires: now.Add(r.queryCacheTTL)})
+	}
+	return em, true
+}
+
+// putCachedQuery caches the embedding of the query utterance with the given
+// key in the query cache and, if query embeddings expire, in the router's
+// store if it is a TTLStore.
+//
+// Caching in the store is best effort: its errors are ignored, the query
+// being encoded again on the next lookup.
+func (r *Router) putCachedQuery(ctx context.Context, key string, em []float64) {
+	var expires time.Time
+	if r.queryCacheTTL > 0 {
+		expires = time.Now().Add(r.queryCacheTTL)
+	}
+	if r.queryCache != nil {
+		r.queryCache.put(key, cachedQuery{embedding: em, expires: expires})
+	}
+	store, ok := r.Storage.(TTLStore)
+	if !ok || r.queryCacheTTL <= 0 {
+		return
+	}
+	utter := domain.Utterance{Utterance: r.key(QueryCacheKey(key))}
+	if utter.SetEmbedding(em) != nil {
+		return
+	}
+	callCtx, cancel := r.callContext(ctx)
+	defer cancel()
+	_ = store.SetWithTTL(callCtx, utter, r.queryCacheTTL)
+}
@@ -0,0 +1,89 @@
+package semanticrouter
+
+import (
+	"math"
+	"sort"
+)
+
+// twoStageParams are the parameters of two-stage scoring, see
+// WithTwoStageScoring.
+type twoStageParams struct {
+	prefixDims int // prefixDims is the number of leading dimensions compared in the first stage.
+	candidates int // candidates is the number of routes rescored with the full embeddings.
+}
+
+// prefilterIndex returns the entries of the index belonging to the routes
+// best matching the given queries by the cosine similarity of the leading
+// dimensions of their embeddings, see WithTwoStageScoring.
+//
+// The index is returned unchanged if two-stage scoring is not configured, if
+// embeddings are not dense, if they are not longer than the prefix, or with
+// WithStrictDimensions, so that every mismatched embedding is reported.
+// Entries keep their order, so ties are broken as by a full scan.
+func (r *Router) prefilterIndex(qs []query, index []indexEntry) []indexEntry {
+	if r.twoStage == nil || r.embeddings != denseEmbeddings || r.strictDimensions {
+		return index
+	}
+	k := r.twoStage.prefixDims
+	positions := make(map[string]int)
+	var scores []routeScore
+	for _, entry := range index {
+		if entry.vec.Len() <= k {
+			return index
+		}
+		var best float64
+		var found bool
+		for _, q := range qs {
+			if q.vec.Len() != entry.vec.Len() {
+				continue
+			}
+			score := prefixCosine(q, entry, k)
+			if !found || score > best {
+				best, found = score, true
+			}
+		}
+		if !found {
+			continue
+		}
+		pos, ok := positions[entry.route]
+		if !ok {
+			positions[entry.route] = len(scores)
+			scores = append(scores, routeScore{route: entry.route, score: best})
+			continue
+		}
+		scores[pos].score = max(scores[pos].score, best)
+	}
+	if len(scores) <= r.twoStage.candidates {
+		return index
+	}
+	sort.SliceStable(scores, func(i, j int) bool {
+		return scores[i].score > scores[j].score
+	})
+	candidates := make(map[string]bool, r.twoStage.candidates)
+	for _, rs := range scores[:r.twoStage.candidates] {
+		candidates[rs.route] = true
+	}
+	filtered := make([]indexEntry, 0, len(index))
+	for _, entry := range index {
+		if candidates[entry.route] {
+			filtered = append(filtered, entry)
+		}
+	}
+	return filtered
+}
+
+// prefixCosine returns the cosine similarity of the first k components of
+// the embeddings of the query and the index entry, zero if either is zero.
+func prefixCosine(q query, entry indexEntry, k int) float64 {
+	var dot, qNorm, eNorm float64
+	for i := 0; i < k; i++ {
+		a, b := q.vec.AtVec(i), entry.vec.AtVec(i)
+		dot += a * b
+		qNorm += a * a
+		eNorm += b * b
+	}
+	if qNorm == 0 || eNorm == 0 {
+		return 0
+	}
+	return dot / math.Sqrt(qNorm*eNorm)
+}
@@ -0,0 +1,107 @@
+package semanticrouter
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/conneroisu/go-semantic-router/domain"
+	"github.com/conneroisu/go-semantic-router/stores/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newSeparatedRoutes returns routes whose utterance embeddings of the given
+// dimension are clustered around a random center per route, along with an
+// encoder knowing them and queries "query i" near the center of route i.
+func newSeparatedRoutes(routes, utterances, dimension int) ([]Route, *mockEncoder) {
+	rnd := rand.New(rand.NewSource(1))
+	encoder := &mockEncoder{embeddings: make(map[string][]float64)}
+	near := func(center []float64) []float64 {
+		v := make([]float64, len(center))
+		for i := range v {
+			v[i] = center[i] + 0.2*rnd.NormFloat64()
+		}
+		return v
+	}
+	var rs []Route
+	for i := 0; i < routes; i++ {
+		center := make([]float64, dimension)
+		for d := range center {
+			center[d] = rnd.NormFloat64()
+		}
+		route := Route{Name: fmt.Sprintf("route-%d", i)}
+		for j := 0; j < utterances; j++ {
+			utterance := fmt.Sprintf("utterance %d of route %d", j, i)
+			encoder.embeddings[utterance] = near(center)
+			route.Utterances = append(route.Utterances, domain.Utterance{Utterance: utterance})
+		}
+		encoder.embeddings[fmt.Sprintf("query %d", i)] = near(center)
+		rs = append(rs, route)
+	}
+	return rs, encoder
+}
+
+// TestWithTwoStageScoring tests that two-stage scoring finds the same route
+// and score as a full scan on well-separated routes.
+func TestWithTwoStageScoring(t *testing.T) {
+	ctx := context.Background()
+	routes, encoder := newSeparatedRoutes(16, 8, 256)
+	full, err := NewRouter(routes, encoder, memory.NewStore())
+	require.NoError(t, err)
+	twoStage, err := NewRouter(routes, encoder, memory.NewStore(), WithTwoStageScoring(16, 3))
+	require.NoError(t, err)
+
+	for i := range routes {
+		utterance := fmt.Sprintf("query %d", i)
+		wantRoute, wantScore, err := full.Match(ctx, utterance)
+		require.NoError(t, err)
+		gotRoute, gotScore, err := twoStage.Match(ctx, utterance)
+		require.NoError(t, err)
+		assert.Equal(t, routes[i].Name, gotRoute)
+		assert.Equal(t, wantRoute, gotRoute)
+		assert.Equal(t, wantScore, gotScore)
+	}
+
+	// Only the candidate routes are scored with the full embeddings.
+	qs, err := twoStage.encodeQueries(ctx, "query 0")
+	require.NoError(t, err)
+	index, err := twoStage.loadIndex(ctx)
+	require.NoError(t, err)
+	filtered := twoStage.prefilterIndex(qs, index)
+	assert.Len(t, filtered, 3*8)
+	assert.Equal(t, "route-0", filtered[0].route)
+
+	_, err = NewRouter(routes, encoder, memory.NewStore(), WithTwoStageScoring(0, 3))
+	assert.Error(t, err)
+	_, err = NewRouter(routes, encoder, memory.NewStore(), WithTwoStageScoring(16, 0))
+	assert.Error(t, err)
+}
+
+// BenchmarkTwoStageScoring compares scoring every route with the full
+// embeddings and scoring routes in two stages against an already loaded
+// index.
+func BenchmarkTwoStageScoring(b *testing.B) {
+	ctx := context.Background()
+	routes, encoder := newSeparatedRoutes(32, 100, 1024)
+	for _, bc := range []struct {
+		name string
+		opts []Option
+	}{
+		{name: "full"},
+		{name: "prefix=64,candidates=4", opts: []Option{WithTwoStageScoring(64, 4)}},
+	} {
+		router, err := NewRouter(routes, encoder, memory.NewStore(), bc.opts...)
+		require.NoError(b, err)
+		qs, err := router.encodeQueries(ctx, "query 0")
+		require.NoError(b, err)
+		index, err := router.loadIndex(ctx)
+		require.NoError(b, err)
+		b.Run(bc.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				_, _ = router.rankIndex(ctx, "query 0", qs, index, true)
+			}
+		})
+	}
+}
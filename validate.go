@@ -0,0 +1,83 @@
+package semanticrouter
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Validate reports whether the route is well-formed.
+//
+// A route must have a non-empty name and at least one utterance, none of
+// which may be empty or only contain whitespace, see ErrEmptyUtterance. All
+// problems found are joined into the returned error.
+func (r Route) Validate() error {
+	var errs []error
+	if r.Name == "" {
+		errs = append(errs, errors.New("route name is empty"))
+	}
+	if len(r.Utterances) == 0 {
+		errs = append(errs, fmt.Errorf("route %q has no utterances", r.Name))
+	}
+	for _, utter := range r.Utterances {
+		if strings.TrimSpace(utter.Utterance) == "" {
+			errs = append(errs, ErrEmptyUtterance{Route: r.Name})
+			break
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// validateRoutes validates each of the given routes and ensures that no two
+// routes share the same name and that route parents form a hierarchy.
+func validateRoutes(routes []Route) error {
+	var errs []error
+	seen := make(map[string]bool, len(routes))
+	for i, route := range routes {
+		if err := route.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("invalid route at index %d: %w", i, err))
+		}
+		if route.Name == "" {
+			continue
+		}
+		if seen[route.Name] {
+			errs = append(errs, fmt.Errorf("duplicate route name: %q", route.Name))
+		}
+		seen[route.Name] = true
+	}
+	errs = append(errs, validateParents(routes)...)
+	return errors.Join(errs...)
+}
+
+// validateParents ensures that the parent of each route exists and that no
+// route is its own ancestor.
+func validateParents(routes []Route) (errs []error) {
+	parents := make(map[string]string, len(routes))
+	for _, route := range routes {
+		parents[route.Name] = route.Parent
+	}
+	for _, route := range routes {
+		if route.Parent == "" {
+			continue
+		}
+		if _, ok := parents[route.Parent]; !ok {
+			errs = append(errs, fmt.Errorf(
+				"route %q has unknown parent: %q",
+				route.Name,
+				route.Parent,
+			))
+			continue
+		}
+		for ancestor, depth := route.Parent, 0; ancestor != ""; depth++ {
+			if ancestor == route.Name || depth > len(routes) {
+				errs = append(errs, fmt.Errorf(
+					"route %q is its own ancestor",
+					route.Name,
+				))
+				break
+			}
+			ancestor = parents[ancestor]
+		}
+	}
+	return errs
+}
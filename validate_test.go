@@ -0,0 +1,83 @@
+package semanticrouter
+
+import (
+	"testing"
+
+	"github.com/conneroisu/go-semantic-router/domain"
+	"github.com/conneroisu/go-semantic-router/stores/memory"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestRouteValidate tests that Route.Validate reports malformed routes.
+func TestRouteValidate(t *testing.T) {
+	testCases := []struct {
+		name    string
+		route   Route
+		wantErr []string
+	}{
+		{
+			name: "valid",
+			route: Route{
+				Name:       "chitchat",
+				Utterances: []domain.Utterance{{Utterance: "hello"}},
+			},
+		},
+		{
+			name: "empty name",
+			route: Route{
+				Utterances: []domain.Utterance{{Utterance: "hello"}},
+			},
+			wantErr: []string{"route name is empty"},
+		},
+		{
+			name:    "empty utterances",
+			route:   Route{Name: "chitchat"},
+			wantErr: []string{`route "chitchat" has no utterances`},
+		},
+		{
+			name: "whitespace utterance",
+			route: Route{
+				Name: "chitchat",
+				Utterances: []domain.Utterance{
+					{Utterance: "hello"},
+					{Utterance: " \t\n"},
+				},
+			},
+			wantErr: []string{`route "chitchat" has an empty utterance`},
+		},
+		{
+			name:  "empty name and utterances",
+			route: Route{},
+			wantErr: []string{
+				"route name is empty",
+				`route "" has no utterances`,
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.route.Validate()
+			if len(tc.wantErr) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Error(t, err)
+			for _, want := range tc.wantErr {
+				assert.Contains(t, err.Error(), want)
+			}
+		})
+	}
+}
+
+// TestNewRouterValidation tests that NewRouter rejects invalid and duplicate
+// routes before encoding anything.
+func TestNewRouterValidation(t *testing.T) {
+	routes := append(newTestRoutes(), Route{Name: "chitchat"}, Route{
+		Utterances: []domain.Utterance{{Utterance: "hello"}},
+	})
+	_, err := NewRouter(routes, newTestEncoder(), memory.NewStore())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `duplicate route name: "chitchat"`)
+	assert.Contains(t, err.Error(), `route "chitchat" has no utterances`)
+	assert.Contains(t, err.Error(), "route name is empty")
+}
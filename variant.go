@@ -0,0 +1,162 @@
+package semanticrouter
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+
+	"github.com/conneroisu/go-semantic-router/domain"
+)
+
+// variantKeyPrefix prefixes the keys of the alternative embeddings of
+// utterances in the store.
+const variantKeyPrefix = "semanticrouter:variant:"
+
+// VariantKey returns the key under which the i-th alternative embedding of
+// the given utterance is stored: the embeddings of its Variants first, in
+// order, then its Embeds.
+func VariantKey(utterance string, i int) string {
+	return variantKeyPrefix + strconv.Itoa(i) + ":" + utterance
+}
+
+// sameVariants reports whether the given versions of an utterance have the
+// same variants and precomputed alternative embeddings, so that its stored
+// alternative embeddings can be kept.
+func sameVariants(a, b domain.Utterance) bool {
+	return slices.Equal(a.Variants, b.Variants) &&
+		slices.EqualFunc(a.Embeds, b.Embeds, slices.Equal[[]float64])
+}
+
+// variantCount returns the number of alternative embeddings of the given
+// utterance.
+func variantCount(utter domain.Utterance) int {
+	return len(utter.Variants) + len(utter.Embeds)
+}
+
+// storeVariants encodes the variants of the given utterance and stores their
+// embeddings along with its precomputed ones under their VariantKey.
+//
+// The alternative embeddings must have the dimension of en, the embedding of
+// the utterance itself.
+func (r *Router) storeVariants(
+	ctx context.Context,
+	utter domain.Utterance,
+	en []float64,
+) error {
+	embeds := make([][]float64, 0, variantCount(utter))
+	for _, variant := range utter.Variants {
+		em, err := r.encode(ctx, r.documentInput(variant))
+		if err != nil {
+			return ErrEncoding{Message: "error encoding utterance variant", Err: err}
+		}
+		embeds = append(embeds, em)
+	}
+	for _, em := range utter.Embeds {
+		if r.outputDimension > 0 && len(em) > r.outputDimension {
+			em = em[:r.outputDimension:r.outputDimension]
+		}
+		embeds = append(embeds, em)
+	}
+	for i, em := range embeds {
+		if len(em) != len(en) {
+			return ErrDimensionMismatch{
+				Utterance: utter.Utterance,
+				Expected:  len(en),
+				Actual:    len(em),
+			}
+		}
+		err := r.storeEmbedding(ctx, domain.Utterance{Utterance: VariantKey(utter.Utterance, i)}, em)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadVariants fetches the alternative embeddings of the given utterance
+// into the variants of its index entry.
+func (r *Router) loadVariants(
+	ctx context.Context,
+	entry *indexEntry,
+	utter domain.Utterance,
+) error {
+	for i := range variantCount(utter) {
+		variant, err := r.loadEntry(ctx, VariantKey(utter.Utterance, i))
+		if err != nil {
+			return err
+		}
+		variant.route, variant.utterance = entry.route, entry.utterance
+		entry.variants = append(entry.variants, variant)
+	}
+	return nil
+}
+
+// deleteVariants deletes the alternative embeddings of the given utterance
+// from the router's store, which must implement Deleter.
+func (r *Router) deleteVariants(
+	ctx context.Context,
+	utter domain.Utterance,
+) error {
+	for i := range variantCount(utter) {
+		err := r.deleteUtterance(ctx, VariantKey(utter.Utterance, i))
+		if err != nil {
+			return fmt.Errorf("error deleting utterance variant: %w", err)
+		}
+	}
+	return nil
+}
+
+// deleteStaleVariants deletes the alternative embeddings of the previous
+// version of an utterance beyond the count of alternative embeddings of its
+// new version.
+func (r *Router) deleteStaleVariants(
+	ctx context.Context,
+	prev domain.Utterance,
+	count int,
+) error {
+	for i := count; i < variantCount(prev); i++ {
+		err := r.deleteUtterance(ctx, VariantKey(prev.Utterance, i))
+		if err != nil {
+			return fmt.Errorf("error deleting utterance variant: %w", err)
+		}
+	}
+	return nil
+}
+
+// flattenVariants returns the given index along with an entry for each
+// alternative embedding of its entries, carrying the route and utterance of
+// its parent entry, so that each alternative embedding is a point of the
+// spatial index.
+func flattenVariants(index []indexEntry) []indexEntry {
+	flat := slices.Clone(index)
+	for _, entry := range index {
+		flat = append(flat, entry.variants...)
+	}
+	return flat
+}
+
+// bestVariant returns the embedding of the index entry, its own or one of
+// its variants, scoring best against the query, along with its score, see
+// computeScore.
+func (r *Router) bestVariant(
+	q query,
+	entry indexEntry,
+	fns []biFuncCoefficient,
+) (best indexEntry, score float64, ok bool, err error) {
+	best = entry
+	score, ok, err = r.computeEntryScore(q, entry, fns)
+	if err != nil {
+		return indexEntry{}, 0, false, err
+	}
+	for _, variant := range entry.variants {
+		s, valid, err := r.computeEntryScore(q, variant, fns)
+		if err != nil {
+			return indexEntry{}, 0, false, err
+		}
+		if valid && (!ok || r.better(s, score)) {
+			best, score, ok = variant, s, true
+		}
+	}
+	return best, score, ok, nil
+}
@@ -0,0 +1,153 @@
+package semanticrouter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/conneroisu/go-semantic-router/domain"
+	"github.com/conneroisu/go-semantic-router/stores/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newVariantEncoder returns a mockEncoder knowing the short and expanded
+// forms of a billing utterance.
+func newVariantEncoder() *mockEncoder {
+	return &mockEncoder{embeddings: map[string][]float64{
+		"pay bill":                      {0.0, 0.0, 1.0},
+		"settle my outstanding invoice": {1.0, 0.0, 0.1},
+		"help me":                       {0.5, 0.5, 0.0},
+		"i want to clear my invoice":    {1.0, 0.0, 0.0},
+	}}
+}
+
+// newVariantRoutes returns a billing route whose utterance has the given
+// variants and precomputed embeddings, and a support route.
+func newVariantRoutes(variants []string, embeds [][]float64) []Route {
+	return []Route{
+		{Name: "billing", Utterances: []domain.Utterance{{Utterance: "pay bill", Variants: variants, Embeds: embeds}}},
+		{Name: "support", Utterances: []domain.Utterance{{Utterance: "help me"}}},
+	}
+}
+
+// TestUtteranceVariants tests that utterances are scored against the best
+// of their own embedding and the embeddings of their variants.
+func TestUtteranceVariants(t *testing.T) {
+	ctx := context.Background()
+	const query = "i want to clear my invoice"
+
+	router, err := NewRouter(newVariantRoutes(nil, nil), newVariantEncoder(), memory.NewStore())
+	require.NoError(t, err)
+	route, _, err := router.Match(ctx, query)
+	require.NoError(t, err)
+	assert.Equal(t, "support", route)
+
+	store := memory.NewStore()
+	router, err = NewRouter(newVariantRoutes([]string{"settle my outstanding invoice"}, nil), newVariantEncoder(), store)
+	require.NoError(t, err)
+	route, score, err := router.Match(ctx, query)
+	require.NoError(t, err)
+	assert.Equal(t, "billing", route)
+	assert.InDelta(t, 1/1.004987562112089, score, 1e-9)
+	em, err := store.Get(ctx, VariantKey("pay bill", 0))
+	require.NoError(t, err)
+	assert.Equal(t, []float64{1.0, 0.0, 0.1}, em)
+
+	results, err := router.MatchN(ctx, query, 1)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.InDelta(t, score, results[0].Breakdown[SimilarityCosine], 1e-9)
+
+	pruned, err := router.PruneStore(ctx, func(string) bool { return true })
+	require.NoError(t, err)
+	assert.Zero(t, pruned)
+
+	t.Run("precomputed", func(t *testing.T) {
+		router, err := NewRouter(newVariantRoutes(nil, [][]float64{{1.0, 0.0, 0.0}}), newVariantEncoder(), memory.NewStore())
+		require.NoError(t, err)
+		route, score, err := router.Match(ctx, query)
+		require.NoError(t, err)
+		assert.Equal(t, "billing", route)
+		assert.InDelta(t, 1.0, score, 1e-9)
+	})
+
+	t.Run("dimension mismatch", func(t *testing.T) {
+		_, err := NewRouter(newVariantRoutes(nil, [][]float64{{1.0, 0.0}}), newVariantEncoder(), memory.NewStore())
+		var mismatch ErrDimensionMismatch
+		require.ErrorAs(t, err, &mismatch)
+		assert.Equal(t, "pay bill", mismatch.Utterance)
+	})
+
+	t.Run("removed", func(t *testing.T) {
+		store := memory.NewStore()
+		router, err := NewRouter(newVariantRoutes([]string{"settle my outstanding invoice"}, nil), newVariantEncoder(), store)
+		require.NoError(t, err)
+		_, err = router.UpdateRoute(ctx, "billing", []domain.Utterance{{Utterance: "help me"}})
+		require.NoError(t, err)
+		_, err = store.Get(ctx, VariantKey("pay bill", 0))
+		assert.Error(t, err)
+	})
+
+	t.Run("updated", func(t *testing.T) {
+		store := memory.NewStore()
+		router, err := NewRouter(newVariantRoutes(nil, nil), newVariantEncoder(), store)
+		require.NoError(t, err)
+		n, err := router.UpdateRoute(ctx, "billing", []domain.Utterance{{
+			Utterance: "pay bill",
+			Variants:  []string{"settle my outstanding invoice", "help me"},
+		}})
+		require.NoError(t, err)
+		assert.Equal(t, 1, n)
+		route, _, err := router.Match(ctx, query)
+		require.NoError(t, err)
+		assert.Equal(t, "billing", route)
+
+		_, err = router.UpdateRoute(ctx, "billing", []domain.Utterance{{
+			Utterance: "pay bill",
+			Variants:  []string{"settle my outstanding invoice"},
+		}})
+		require.NoError(t, err)
+		_, err = store.Get(ctx, VariantKey("pay bill", 0))
+		require.NoError(t, err)
+		_, err = store.Get(ctx, VariantKey("pay bill", 1))
+		assert.Error(t, err)
+		route, _, err = router.Match(ctx, query)
+		require.NoError(t, err)
+		assert.Equal(t, "billing", route)
+	})
+
+	t.Run("set routes", func(t *testing.T) {
+		store := memory.NewStore()
+		router, err := NewRouter(newVariantRoutes(nil, nil), newVariantEncoder(), store)
+		require.NoError(t, err)
+		routes := newVariantRoutes([]string{"settle my outstanding invoice", "help me"}, nil)
+		n, err := router.SetRoutes(ctx, routes)
+		require.NoError(t, err)
+		assert.Equal(t, 1, n)
+		route, _, err := router.Match(ctx, query)
+		require.NoError(t, err)
+		assert.Equal(t, "billing", route)
+
+		_, err = router.SetRoutes(ctx, newVariantRoutes([]string{"settle my outstanding invoice"}, nil))
+		require.NoError(t, err)
+		_, err = store.Get(ctx, VariantKey("pay bill", 1))
+		assert.Error(t, err)
+	})
+
+	t.Run("spatial index", func(t *testing.T) {
+		for name, opt := range map[string]Option{
+			"kd-tree": WithSpatialIndex(true),
+			"hnsw":    WithHNSW(4, 16, 16),
+		} {
+			router, err := NewRouter(newVariantRoutes([]string{"settle my outstanding invoice"}, nil), newVariantEncoder(), memory.NewStore(), opt)
+			require.NoError(t, err)
+			require.NotNil(t, router.spatial, name)
+			route, score, err := router.Match(ctx, query)
+			require.NoError(t, err)
+			assert.Equal(t, "billing", route, name)
+			_, exact, err := router.MatchExact(ctx, query)
+			require.NoError(t, err)
+			assert.InDelta(t, exact, score, 1e-9, name)
+		}
+	})
+}
@@ -0,0 +1,16 @@
+package semanticrouter
+
+import (
+	"context"
+
+	"github.com/conneroisu/go-semantic-router/domain"
+)
+
+// VectorStore is a Store that can also search for the utterances whose
+// embeddings are the most similar to a query embedding.
+//
+// Search returns at most k utterances ordered by descending score.
+type VectorStore interface {
+	Store
+	Search(ctx context.Context, query []float64, k int) ([]domain.ScoredUtterance, error)
+}
@@ -0,0 +1,205 @@
+package semanticrouter
+
+import (
+	"context"
+	"sort"
+
+	"github.com/conneroisu/go-semantic-router/domain"
+)
+
+// defaultTopUtterances is the default number of utterances of the matched
+// route returned by MatchVerbose, see WithTopUtterances.
+const defaultTopUtterances = 3
+
+// VerboseMatch is the detailed result of matching an utterance.
+type VerboseMatch struct {
+	Route         string                   `json:"route"          yaml:"route"          toml:"route"`          // Route is the name of the best matching route.
+	Score         float64                  `json:"score"          yaml:"score"          toml:"score"`          // Score is the score of the best matching route.
+	TopUtterances []domain.ScoredUtterance `json:"top_utterances" yaml:"top_utterances" toml:"top_utterances"` // TopUtterances are the best scoring utterances of the route, best first.
+}
+
+// MatchVerbose returns the route that matches the given utterance along with
+// the best scoring utterances of that route.
+//
+// The number of utterances returned is set with WithTopUtterances. Comparing
+// their scores tells whether the route won decisively or thanks to a single
+// outlier.
+func (r *Router) MatchVerbose(
+	ctx context.Context,
+	utterance string,
+) (VerboseMatch, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	ranked, err := r.scoreUtterance(ctx, utterance, nil)
+	if err != nil {
+		return VerboseMatch{}, err
+	}
+	route, score, err := r.bestRoute(ranked.scores)
+	if err != nil {
+		return VerboseMatch{}, err
+	}
+	top, err := r.topUtterances(ranked.qs, ranked.index, route)
+	if err != nil {
+		return VerboseMatch{}, err
+	}
+	return VerboseMatch{
+		Route:         route,
+		Score:         score,
+		TopUtterances: top,
+	}, nil
+}
+
+// topUtterances returns the best scoring utterances of the given route
+// against the given queries, best first.
+//
+// The scores of the queries are combined according to the configured
+// expansion mode.
+func (r *Router) topUtterances(
+	qs []query,
+	index []indexEntry,
+	route string,
+) ([]domain.ScoredUtterance, error) {
+	var top []domain.ScoredUtterance
+	for _, entry := range index {
+		if entry.route != route {
+			continue
+		}
+		var score float64
+		var n int
+		for _, q := range qs {
+			if q.kind == denseEmbeddings && entry.vec.Len() != q.vec.Len() {
+				continue
+			}
+			s, ok, err := r.computeScore(q, entry, r.similarities(route))
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				continue
+			}
+			s += entry.boost
+			switch {
+			case n == 0:
+				score = s
+			case r.expansionMode == ExpansionMean:
+				score += s
+			default:
+				score = max(score, s)
+			}
+			n++
+		}
+		if n == 0 {
+			continue
+		}
+		if r.expansionMode == ExpansionMean {
+			score /= float64(n)
+		}
+		top = append(top, domain.ScoredUtterance{
+			Utterance: entry.utterance,
+			Score:     score,
+		})
+	}
+	sort.SliceStable(top, func(i, j int) bool {
+		return r.better(top[i].Score, top[j].Score)
+	})
+	m := r.topUtteranceCount
+	if m <= 0 {
+		m = defaultTopUtterances
+	}
+	return top[:min(m, len(top))], nil
+}
+
+// routeBreakdown returns the unweighted score of each similarity function
+// between the best scoring pair of query and utterance of the given route.
+func (r *Router) routeBreakdown(
+	qs []query,
+	index []indexEntry,
+	route string,
+) (map[string]float64, error) {
+	q, entry, found, err := r.bestPair(qs, index, route)
+	if err != nil || !found {
+		return nil, err
+	}
+	return r.scoreBreakdown(q, entry, r.similarities(route))
+}
+
+// bestPair returns the pair of query and utterance of the given route with
+// the highest score, and false if no utterance of the route is scored.
+func (r *Router) bestPair(
+	qs []query,
+	index []indexEntry,
+	route string,
+) (bestQuery query, bestEntry indexEntry, found bool, err error) {
+	var bestScore float64
+	fns := r.similarities(route)
+	for _, entry := range index {
+		if entry.route != route {
+			continue
+		}
+		for _, q := range qs {
+			if q.kind == denseEmbeddings && entry.vec.Len() != q.vec.Len() {
+				continue
+			}
+			score, ok, err := r.computeScore(q, entry, fns)
+			if err != nil {
+				return query{}, indexEntry{}, false, err
+			}
+			if ok && (!found || r.better(score+entry.boost, bestScore)) {
+				bestQuery, bestEntry, bestScore = q, entry, score+entry.boost
+				found = true
+			}
+		}
+	}
+	return bestQuery, bestEntry, found, nil
+}
+
+// scoreBreakdown returns the unweighted score of each of the given
+// similarity functions between the query and the index entry, the terms of
+// the weighted sum computed by computeScore.
+//
+// Scores the NaN policy skips are left out. Utterances with alternative
+// embeddings are broken down against their best scoring embedding.
+func (r *Router) scoreBreakdown(
+	q query,
+	entry indexEntry,
+	fns []biFuncCoefficient,
+) (map[string]float64, error) {
+	if len(entry.variants) > 0 {
+		var err error
+		entry, _, _, err = r.bestVariant(q, entry, fns)
+		if err != nil {
+			return nil, err
+		}
+	}
+	breakdown := make(map[string]float64)
+	add := func(name string, score float64) error {
+		s, ok, err := r.checkScore(name, entry, score)
+		if err != nil {
+			return err
+		}
+		if ok {
+			breakdown[name] = s
+		}
+		return nil
+	}
+	var err error
+	switch {
+	case q.kind == sparseEmbeddings:
+		err = add("sparse_dot_product", SparseDotProduct(q.sparse, entry.sparse))
+	case q.kind == multiVectorEmbeddings:
+		err = add("max_sim", MaxSim(q.multi, entry.multi))
+	case len(fns) == 0:
+		err = add(SimilarityCosine, CosineFromNorms(q.vec, entry.vec, q.norm, entry.norm))
+	default:
+		for _, bf := range fns {
+			err = add(bf.name, bf.score(q, entry))
+			if err != nil {
+				break
+			}
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return breakdown, nil
+}
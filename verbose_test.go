@@ -0,0 +1,49 @@
+package semanticrouter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/conneroisu/go-semantic-router/domain"
+	"github.com/conneroisu/go-semantic-router/stores/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMatchVerbose tests that the utterances of the matched route are
+// returned ranked by score.
+func TestMatchVerbose(t *testing.T) {
+	ctx := context.Background()
+	encoder := newTestEncoder()
+	encoder.embeddings["sunny and warm"] = []float64{0.5, 0.5, 0.5}
+	routes := newTestRoutes()
+	routes[0].Utterances = append(
+		routes[0].Utterances,
+		domain.Utterance{Utterance: "sunny and warm"},
+	)
+	router, err := NewRouter(routes, encoder, memory.NewStore())
+	require.NoError(t, err)
+
+	match, err := router.MatchVerbose(ctx, "is it raining outside?")
+	require.NoError(t, err)
+	assert.Equal(t, "chitchat", match.Route)
+	require.Len(t, match.TopUtterances, 3)
+	assert.Equal(t, []string{
+		"how's the weather today?",
+		"lovely weather today",
+		"sunny and warm",
+	}, []string{
+		match.TopUtterances[0].Utterance,
+		match.TopUtterances[1].Utterance,
+		match.TopUtterances[2].Utterance,
+	})
+	assert.Equal(t, match.Score, match.TopUtterances[0].Score)
+	assert.Greater(t, match.TopUtterances[1].Score, match.TopUtterances[2].Score)
+
+	router, err = NewRouter(routes, encoder, memory.NewStore(), WithTopUtterances(1))
+	require.NoError(t, err)
+	match, err = router.MatchVerbose(ctx, "is it raining outside?")
+	require.NoError(t, err)
+	require.Len(t, match.TopUtterances, 1)
+	assert.Equal(t, "how's the weather today?", match.TopUtterances[0].Utterance)
+}
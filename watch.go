@@ -0,0 +1,121 @@
+package semanticrouter
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// watchDebounce is how long WatchRoutes waits after a change before
+// reloading the routes, so that the several events of a single save are
+// handled as one.
+const watchDebounce = 100 * time.Millisecond
+
+// WatchRoutes watches the given routes file, or directory of route files as
+// read by LoadRoutesFromDir, and replaces the routes of the router with the
+// ones it defines whenever it changes, see SetRoutes.
+//
+// A routes file holds a route or a list of routes in YAML, or in JSON if its
+// extension is .json. Only new utterances are encoded on reload; the
+// embeddings of unchanged ones are reused from the store. After each reload,
+// onReload, if non-nil, is called with the router and the error of the
+// reload, if any, in which case the routes are left unchanged. Errors of the
+// watcher itself are reported to onReload too.
+//
+// The file is not loaded when watching starts. WatchRoutes returns once the
+// watcher is set up; it stops watching once the given context is done.
+func (r *Router) WatchRoutes(
+	ctx context.Context,
+	path string,
+	onReload func(*Router, error),
+) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("error watching routes: %w", err)
+	}
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("error watching routes: %w", err)
+	}
+	// The directory of a file is watched, as editors often save files by
+	// replacing them.
+	dir := path
+	if !info.IsDir() {
+		dir = filepath.Dir(path)
+	}
+	err = watcher.Add(dir)
+	if err != nil {
+		_ = watcher.Close()
+		return fmt.Errorf("error watching routes: %w", err)
+	}
+	go r.watchRoutes(ctx, watcher, path, info.IsDir(), onReload)
+	return nil
+}
+
+// watchRoutes reloads the routes of the given path whenever the watcher
+// reports a change to them, until the given context is done.
+func (r *Router) watchRoutes(
+	ctx context.Context,
+	watcher *fsnotify.Watcher,
+	path string,
+	isDir bool,
+	onReload func(*Router, error),
+) {
+	defer watcher.Close()
+	report := func(err error) {
+		if onReload != nil {
+			onReload(r, err)
+		}
+	}
+	debounce := time.NewTimer(watchDebounce)
+	debounce.Stop()
+	defer debounce.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename|fsnotify.Remove) != 0 &&
+				watchesFile(path, isDir, event.Name) {
+				debounce.Reset(watchDebounce)
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			report(fmt.Errorf("error watching routes: %w", err))
+		case <-debounce.C:
+			routes, err := loadRoutes(path, isDir)
+			if err == nil {
+				_, err = r.SetRoutes(ctx, routes)
+			}
+			report(err)
+		}
+	}
+}
+
+// watchesFile reports whether a change to the given file changes the routes
+// of the watched path.
+func watchesFile(path string, isDir bool, name string) bool {
+	if !isDir {
+		return filepath.Clean(name) == filepath.Clean(path)
+	}
+	ext := strings.ToLower(filepath.Ext(name))
+	return filepath.Dir(name) == filepath.Clean(path) && (ext == ".yaml" || ext == ".yml" || ext == ".json")
+}
+
+// loadRoutes reads the routes of the given routes file or directory.
+func loadRoutes(path string, isDir bool) ([]Route, error) {
+	if isDir {
+		return LoadRoutesFromDir(path)
+	}
+	return loadRoutesFile(path, strings.ToLower(filepath.Ext(path)) == ".json")
+}
@@ -0,0 +1,66 @@
+package semanticrouter
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/conneroisu/go-semantic-router/stores/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestWatchRoutes tests that the routes of a router are reloaded when its
+// routes file changes.
+func TestWatchRoutes(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	path := filepath.Join(t.TempDir(), "routes.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`- name: chitchat
+  utterances:
+    - utterance: how's the weather today?
+`), 0o600))
+	encoder := &countingEncoder{Encoder: newTestEncoder()}
+	router, err := NewRouter(newTestRoutes(), encoder, memory.NewStore())
+	require.NoError(t, err)
+	built := encoder.calls.Load()
+
+	reloads := make(chan error, 10)
+	require.NoError(t, router.WatchRoutes(ctx, path, func(reloaded *Router, err error) {
+		assert.Same(t, router, reloaded)
+		reloads <- err
+	}))
+	require.NoError(t, os.WriteFile(path, []byte(`- name: chitchat
+  utterances:
+    - utterance: how's the weather today?
+- name: forecast
+  utterances:
+    - utterance: is it raining outside?
+`), 0o600))
+	select {
+	case err := <-reloads:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("routes were not reloaded")
+	}
+	route, _, err := router.Match(ctx, "is it raining outside?")
+	require.NoError(t, err)
+	assert.Equal(t, "forecast", route)
+	require.Len(t, router.Routes, 2)
+	// Only the new utterance and the query were encoded.
+	assert.Equal(t, built+2, encoder.calls.Load())
+
+	// An invalid file is reported and leaves the routes unchanged.
+	require.NoError(t, os.WriteFile(path, []byte("name: ["), 0o600))
+	select {
+	case err := <-reloads:
+		assert.Error(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("routes were not reloaded")
+	}
+	require.Len(t, router.Routes, 2)
+
+	assert.Error(t, router.WatchRoutes(ctx, filepath.Join(t.TempDir(), "missing.yaml"), nil))
+}
@@ -0,0 +1,161 @@
+package semanticrouter
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/stat"
+)
+
+// whiteningTolerance is the fraction of the largest variance below which a
+// direction of the embeddings is considered empty and dropped by whitening.
+const whiteningTolerance = 1e-9
+
+// WhiteningTransform is a centering and ZCA whitening transform of
+// embeddings, fitted once on a corpus with FitWhitening.
+//
+// Embeddings of some models are anisotropic: they share a large common
+// component and vary along few directions, so that the cosine similarity of
+// any two of them is high. Subtracting the mean of the corpus and rescaling
+// every direction of its covariance to unit variance spreads them out, which
+// helps cosine similarity discriminate between routes.
+type WhiteningTransform struct {
+	Mean   []float64  // Mean is the mean embedding of the corpus.
+	Matrix *mat.Dense // Matrix is the ZCA whitening matrix of the corpus.
+}
+
+// FitWhitening fits a whitening transform on the given embeddings.
+//
+// Directions along which the embeddings do not vary, as when there are fewer
+// embeddings than dimensions, are dropped rather than amplified. It returns
+// nil if there are no embeddings, if they do not all have the same dimension,
+// if they do not vary along any direction, as when there is a single one or
+// they are all equal, or if their covariance cannot be decomposed.
+func FitWhitening(embeddings [][]float64) *WhiteningTransform {
+	if len(embeddings) == 0 || len(embeddings[0]) == 0 {
+		return nil
+	}
+	dim := len(embeddings[0])
+	data := mat.NewDense(len(embeddings), dim, nil)
+	for i, em := range embeddings {
+		if len(em) != dim {
+			return nil
+		}
+		data.SetRow(i, em)
+	}
+	mean := make([]float64, dim)
+	for j := range mean {
+		mean[j] = stat.Mean(mat.Col(nil, j, data), nil)
+	}
+	var cov mat.SymDense
+	stat.CovarianceMatrix(&cov, data, nil)
+	var eig mat.EigenSym
+	if !eig.Factorize(&cov, true) {
+		return nil
+	}
+	values := eig.Values(nil)
+	var vectors mat.Dense
+	eig.VectorsTo(&vectors)
+	largest := values[len(values)-1]
+	if !(largest > 0) {
+		return nil
+	}
+	scales := make([]float64, dim)
+	for i, v := range values {
+		if v > largest*whiteningTolerance && v > 0 {
+			scales[i] = 1 / math.Sqrt(v)
+		}
+	}
+	// The ZCA matrix is U diag(1/sqrt(λ)) Uᵀ, rotating the whitened
+	// embeddings back to the original axes.
+	var scaled, matrix mat.Dense
+	scaled.Apply(func(_, j int, v float64) float64 {
+		return v * scales[j]
+	}, &vectors)
+	matrix.Mul(&scaled, vectors.T())
+	return &WhiteningTransform{Mean: mean, Matrix: &matrix}
+}
+
+// FitRouteWhitening fits a whitening transform on the embeddings of all the
+// utterances of the given routes, encoded with the given encoder.
+//
+// The encoder should be the one later wrapped with NewWhiteningEncoder, so
+// that the transform matches the embeddings it whitens.
+func FitRouteWhitening(
+	ctx context.Context,
+	encoder Encoder,
+	routes []Route,
+) (*WhiteningTransform, error) {
+	var embeddings [][]float64
+	for _, route := range routes {
+		for _, utter := range route.Utterances {
+			em, err := encoder.Encode(ctx, utter.Utterance)
+			if err != nil {
+				return nil, ErrEncoding{Message: "error encoding utterance", Err: err}
+			}
+			embeddings = append(embeddings, em)
+		}
+	}
+	t := FitWhitening(embeddings)
+	if t == nil {
+		return nil, fmt.Errorf("error fitting whitening: no varying embeddings of a single dimension")
+	}
+	return t, nil
+}
+
+// Apply returns the whitened copy of the given embedding.
+func (t *WhiteningTransform) Apply(embedding []float64) ([]float64, error) {
+	if len(embedding) != len(t.Mean) {
+		return nil, fmt.Errorf(
+			"embedding has dimension %d, expected %d",
+			len(embedding),
+			len(t.Mean),
+		)
+	}
+	centered := make([]float64, len(embedding))
+	for i, v := range embedding {
+		centered[i] = v - t.Mean[i]
+	}
+	var whitened mat.VecDense
+	whitened.MulVec(t.Matrix, mat.NewVecDense(len(centered), centered))
+	return whitened.RawVector().Data, nil
+}
+
+// WhiteningEncoder is an encoder whitening the embeddings of an underlying
+// encoder with a fitted transform.
+//
+// Used as the encoder of a router, it whitens the embeddings of both the
+// utterances of the routes and the queries, so that they are compared in
+// the same space.
+type WhiteningEncoder struct {
+	Encoder   Encoder             // Encoder is the underlying encoder.
+	Transform *WhiteningTransform // Transform is the whitening transform.
+}
+
+// NewWhiteningEncoder creates a new WhiteningEncoder whitening the
+// embeddings of the given encoder with the given transform.
+func NewWhiteningEncoder(
+	encoder Encoder,
+	transform *WhiteningTransform,
+) *WhiteningEncoder {
+	return &WhiteningEncoder{Encoder: encoder, Transform: transform}
+}
+
+// Encode encodes the utterance with the underlying encoder and whitens the
+// returned embedding.
+func (e *WhiteningEncoder) Encode(
+	ctx context.Context,
+	utterance string,
+) ([]float64, error) {
+	embedding, err := e.Encoder.Encode(ctx, utterance)
+	if err != nil {
+		return nil, err
+	}
+	whitened, err := e.Transform.Apply(embedding)
+	if err != nil {
+		return nil, fmt.Errorf("error whitening embedding of utterance %q: %w", utterance, err)
+	}
+	return whitened, nil
+}
@@ -0,0 +1,135 @@
+package semanticrouter
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/conneroisu/go-semantic-router/domain"
+	"github.com/conneroisu/go-semantic-router/stores/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/stat"
+)
+
+// newAnisotropicClasses returns two classes of embeddings sharing a large
+// common component and differing only slightly along their first two axes.
+func newAnisotropicClasses(n int) (a, b [][]float64) {
+	rnd := rand.New(rand.NewSource(1))
+	sample := func(axis int) []float64 {
+		em := []float64{10, 10, 10, 10}
+		for i := range em {
+			em[i] += 0.1 * rnd.NormFloat64()
+		}
+		em[axis] += 1
+		return em
+	}
+	for i := 0; i < n; i++ {
+		a = append(a, sample(0))
+		b = append(b, sample(1))
+	}
+	return a, b
+}
+
+// cosineSeparation returns the mean cosine similarity of embeddings of the
+// same class minus the mean cosine similarity of embeddings of different
+// classes.
+func cosineSeparation(a, b [][]float64) float64 {
+	cosine := func(x, y []float64) float64 {
+		vx, vy := mat.NewVecDense(len(x), x), mat.NewVecDense(len(y), y)
+		return mat.Dot(vx, vy) / (mat.Norm(vx, 2) * mat.Norm(vy, 2))
+	}
+	var intra, inter []float64
+	for i := range a {
+		for j := range a {
+			if i < j {
+				intra = append(intra, cosine(a[i], a[j]), cosine(b[i], b[j]))
+			}
+			inter = append(inter, cosine(a[i], b[j]))
+		}
+	}
+	return stat.Mean(intra, nil) - stat.Mean(inter, nil)
+}
+
+// TestFitWhitening tests that whitening decorrelates the corpus it is fitted
+// on and makes cosine similarity separate anisotropic classes better.
+func TestFitWhitening(t *testing.T) {
+	a, b := newAnisotropicClasses(20)
+	transform := FitWhitening(append(append([][]float64{}, a...), b...))
+	require.NotNil(t, transform)
+
+	whiten := func(ems [][]float64) (whitened [][]float64) {
+		for _, em := range ems {
+			w, err := transform.Apply(em)
+			require.NoError(t, err)
+			whitened = append(whitened, w)
+		}
+		return whitened
+	}
+	wa, wb := whiten(a), whiten(b)
+
+	// The whitened corpus has a zero mean and an identity covariance.
+	data := mat.NewDense(len(wa)+len(wb), 4, nil)
+	for i, em := range append(append([][]float64{}, wa...), wb...) {
+		data.SetRow(i, em)
+	}
+	var cov mat.SymDense
+	stat.CovarianceMatrix(&cov, data, nil)
+	for i := 0; i < 4; i++ {
+		assert.InDelta(t, 0, stat.Mean(mat.Col(nil, i, data), nil), 1e-9)
+		for j := 0; j < 4; j++ {
+			want := 0.0
+			if i == j {
+				want = 1
+			}
+			assert.InDelta(t, want, cov.At(i, j), 1e-9)
+		}
+	}
+
+	raw := cosineSeparation(a, b)
+	whitened := cosineSeparation(wa, wb)
+	assert.Less(t, raw, 0.01)
+	assert.Greater(t, whitened, 0.5)
+
+	_, err := transform.Apply([]float64{1, 2})
+	assert.Error(t, err)
+	assert.Nil(t, FitWhitening(nil))
+	assert.Nil(t, FitWhitening([][]float64{{1, 2}, {1}}))
+	assert.Nil(t, FitWhitening([][]float64{{1, 2}}))
+	assert.Nil(t, FitWhitening([][]float64{{1, 2}, {1, 2}}))
+}
+
+// TestWhiteningEncoder tests routing with a whitening transform fitted on
+// the routes.
+func TestWhiteningEncoder(t *testing.T) {
+	ctx := context.Background()
+	a, b := newAnisotropicClasses(10)
+	encoder := &mockEncoder{embeddings: map[string][]float64{
+		"query a": {11, 10, 10, 10},
+		"query b": {10, 11, 10, 10},
+	}}
+	routes := []Route{{Name: "a"}, {Name: "b"}}
+	for i := range a {
+		for pos, em := range [][]float64{a[i], b[i]} {
+			utterance := fmt.Sprintf("utterance %d of %s", i, routes[pos].Name)
+			encoder.embeddings[utterance] = em
+			routes[pos].Utterances = append(routes[pos].Utterances, domain.Utterance{Utterance: utterance})
+		}
+	}
+	transform, err := FitRouteWhitening(ctx, encoder, routes)
+	require.NoError(t, err)
+	router, err := NewRouter(routes, NewWhiteningEncoder(encoder, transform), memory.NewStore())
+	require.NoError(t, err)
+
+	route, _, err := router.Match(ctx, "query a")
+	require.NoError(t, err)
+	assert.Equal(t, "a", route)
+	route, _, err = router.Match(ctx, "query b")
+	require.NoError(t, err)
+	assert.Equal(t, "b", route)
+
+	_, err = FitRouteWhitening(ctx, encoder, []Route{{Name: "unknown", Utterances: []domain.Utterance{{Utterance: "unknown"}}}})
+	assert.ErrorAs(t, err, &ErrEncoding{})
+}